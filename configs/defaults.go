@@ -20,6 +20,13 @@ var (
 	TemplateError         = fmt.Errorf("failed to process template")
 	ConversionError       = fmt.Errorf("failed to convert PDF to images")
 	CleanError            = fmt.Errorf("failed to clean up auxiliary files")
+	LintError             = fmt.Errorf("template has lint errors")
+	FrontMatterError      = fmt.Errorf("invalid template front matter")
+	RequiredVariableError = fmt.Errorf("template requires variables the config does not define")
+	SchemaError           = fmt.Errorf("invalid variable schema")
+	VariableSourceError   = fmt.Errorf("failed to read variables")
+	ProfileError          = fmt.Errorf("failed to apply config profile")
+	InterpolationError    = fmt.Errorf("failed to interpolate config values")
 
 	// Command-specific errors
 	UnknownSubcommandError = fmt.Errorf("unknown subcommand")
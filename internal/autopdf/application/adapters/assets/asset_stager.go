@@ -0,0 +1,325 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package assets
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+)
+
+var (
+	commentPattern      = regexp.MustCompile(`(^|[^\\])%.*`)
+	graphicsPattern     = regexp.MustCompile(`\\includegraphics\*?\s*(?:\[[^\]]*\])?\s*\{([^}]+)\}`)
+	inputPattern        = regexp.MustCompile(`\\(input|include|subfile)\s*\{([^}]+)\}`)
+	importPattern       = regexp.MustCompile(`\\(?:sub)?(?:import|inputfrom|includefrom)\*?\s*\{([^}]*)\}\s*\{([^}]+)\}`)
+	includePDFPattern   = regexp.MustCompile(`\\includepdf\s*(?:\[[^\]]*\])?\s*\{([^}]+)\}`)
+	listingPattern      = regexp.MustCompile(`\\lstinputlisting\s*(?:\[[^\]]*\])?\s*\{([^}]+)\}`)
+	classPattern        = regexp.MustCompile(`\\documentclass\s*(?:\[[^\]]*\])?\s*\{([^}]+)\}`)
+	packagePattern      = regexp.MustCompile(`\\(?:usepackage|RequirePackage)\s*(?:\[[^\]]*\])?\s*\{([^}]+)\}`)
+	bibliographyPattern = regexp.MustCompile(`\\bibliography\s*\{([^}]+)\}`)
+	bibResourcePattern  = regexp.MustCompile(`\\addbibresource\s*(?:\[[^\]]*\])?\s*\{([^}]+)\}`)
+	bibStylePattern     = regexp.MustCompile(`\\bibliographystyle\s*\{([^}]+)\}`)
+	graphicsPathPattern = regexp.MustCompile(`\\graphicspath\s*\{((?:\{[^}]*\}\s*)+)\}`)
+	fontPathPattern     = regexp.MustCompile(`Path\s*=\s*([^,\]\}]+)`)
+
+	// Typst reads files through functions taking a string path, and modules through include and import
+	typstFilePattern   = regexp.MustCompile(`\b(?:image|read|json|csv|yaml|toml|xml|cbor|bibliography)\(\s*"([^"]+)"`)
+	typstModulePattern = regexp.MustCompile(`#?\b(?:include|import)\s+"([^"]+)"`)
+)
+
+// graphicsExtensions is the order graphicx tries when \includegraphics omits the extension
+var graphicsExtensions = []string{".pdf", ".png", ".jpg", ".jpeg", ".mps", ".eps", ".svg"}
+
+// AssetStagerAdapter implements AssetStager by scanning the LaTeX or Typst source for local files,
+// following the inputs, classes and packages it finds there
+// References that do not exist are left for the engine to report; those outside the source
+// directory are reported as unstaged
+type AssetStagerAdapter struct {
+	fileSystem ports.FileSystem
+	engine     string   // Engine compiling the content; decides how its comments are written
+	mode       string   // ports.AssetModeSymlink or ports.AssetModeCopy
+	extraGlobs []string // Additional files to stage, relative to the source directory
+}
+
+// NewAssetStagerAdapter creates an asset stager for content compiled by engine; an empty mode links the assets
+func NewAssetStagerAdapter(fileSystem ports.FileSystem, engine, mode string, extraGlobs []string) (*AssetStagerAdapter, error) {
+	if mode == "" {
+		mode = ports.AssetModeSymlink
+	}
+	if mode != ports.AssetModeSymlink && mode != ports.AssetModeCopy {
+		return nil, fmt.Errorf("unsupported asset mode %q (use %s or %s)", mode, ports.AssetModeSymlink, ports.AssetModeCopy)
+	}
+	for _, pattern := range extraGlobs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid asset glob %q: %w", pattern, err)
+		}
+	}
+	return &AssetStagerAdapter{
+		fileSystem: fileSystem,
+		engine:     engine,
+		mode:       mode,
+		extraGlobs: extraGlobs,
+	}, nil
+}
+
+// Stage links or copies every local dependency of content from sourceDir into targetDir
+// When both are the same directory nothing is staged, yet the dependencies are still returned,
+// since they key the compile cache
+func (s *AssetStagerAdapter) Stage(ctx context.Context, content, sourceDir, targetDir string) (ports.StagedAssets, error) {
+	if sourceDir == "" || targetDir == "" {
+		return ports.StagedAssets{}, nil
+	}
+	sourceDir, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return ports.StagedAssets{}, fmt.Errorf("failed to resolve asset directory: %w", err)
+	}
+	targetDir, err = filepath.Abs(targetDir)
+	if err != nil {
+		return ports.StagedAssets{}, fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	candidates := s.references(ctx, content, sourceDir)
+	for _, pattern := range s.extraGlobs {
+		matches, _ := filepath.Glob(filepath.Join(sourceDir, pattern))
+		for _, match := range matches {
+			if rel, err := filepath.Rel(sourceDir, match); err == nil {
+				candidates = append(candidates, rel)
+			}
+		}
+	}
+
+	assets := ports.StagedAssets{Files: []string{}}
+	seen := make(map[string]bool)
+	for _, rel := range candidates {
+		rel = filepath.Clean(rel)
+		if seen[rel] || rel == "." {
+			continue
+		}
+		seen[rel] = true
+		if !isLocal(rel) {
+			assets.Unstaged = append(assets.Unstaged, rel)
+			continue
+		}
+		if sourceDir == targetDir {
+			assets.Files = append(assets.Files, rel)
+			continue
+		}
+
+		ok, err := s.stage(ctx, filepath.Join(sourceDir, rel), filepath.Join(targetDir, rel))
+		if err != nil {
+			return assets, fmt.Errorf("failed to stage %s: %w", rel, err)
+		}
+		if ok {
+			assets.Files = append(assets.Files, rel)
+		}
+	}
+
+	sort.Strings(assets.Files)
+	sort.Strings(assets.Unstaged)
+	return assets, nil
+}
+
+// references lists the files and directories the LaTeX source refers to, relative to sourceDir
+// unless absolute, following \input and \include files and local classes and packages;
+// only paths that exist are returned
+func (s *AssetStagerAdapter) references(ctx context.Context, content, sourceDir string) []string {
+	refs, inputs := s.scan(ctx, stripComments(content, s.engine == ports.EngineTypst), sourceDir)
+
+	// Paths inside included files are resolved from the main file's directory too
+	scanned := make(map[string]bool)
+	for len(inputs) > 0 {
+		rel := inputs[0]
+		inputs = inputs[1:]
+		if scanned[rel] {
+			continue
+		}
+		scanned[rel] = true
+
+		data, err := s.fileSystem.ReadFile(ctx, resolve(sourceDir, rel))
+		if err != nil {
+			continue
+		}
+		typst := strings.EqualFold(filepath.Ext(rel), ports.SourceExtension(ports.EngineTypst))
+		nestedRefs, nestedInputs := s.scan(ctx, stripComments(string(data), typst), sourceDir)
+		refs = append(refs, nestedRefs...)
+		inputs = append(inputs, nestedInputs...)
+	}
+	return refs
+}
+
+// scan finds the references of a single LaTeX or Typst source; inputs are the referenced
+// .tex, .typ, .cls and .sty files, which are scanned in turn
+func (s *AssetStagerAdapter) scan(ctx context.Context, content, sourceDir string) (refs, inputs []string) {
+	exists := func(rel string) bool {
+		_, err := s.fileSystem.Stat(ctx, resolve(sourceDir, rel))
+		return err == nil
+	}
+	// addFile adds rel, or rel with the first matching default extension
+	addFile := func(rel string, extensions ...string) {
+		rel = strings.TrimSpace(rel)
+		if rel == "" {
+			return
+		}
+		if filepath.Ext(rel) != "" && exists(rel) {
+			refs = append(refs, rel)
+			return
+		}
+		for _, ext := range extensions {
+			if exists(rel + ext) {
+				refs = append(refs, rel+ext)
+				return
+			}
+		}
+		if exists(rel) {
+			refs = append(refs, rel)
+		}
+	}
+
+	// Directories searched for graphics, also staged as a whole
+	graphicsDirs := []string{""}
+	for _, m := range graphicsPathPattern.FindAllStringSubmatch(content, -1) {
+		for _, dir := range strings.Split(strings.Trim(m[1], "{} \t\n"), "}") {
+			dir = strings.TrimSpace(strings.TrimLeft(dir, "{ \t\n"))
+			if dir != "" && exists(dir) {
+				refs = append(refs, filepath.Clean(dir))
+				graphicsDirs = append(graphicsDirs, dir)
+			}
+		}
+	}
+
+	for _, m := range graphicsPattern.FindAllStringSubmatch(content, -1) {
+		for _, dir := range graphicsDirs {
+			addFile(filepath.Join(dir, m[1]), graphicsExtensions...)
+		}
+	}
+	// addInput adds rel like addFile, and scans it when it is a source with the extension
+	addInput := func(rel, ext string) {
+		count := len(refs)
+		addFile(rel, ext)
+		if len(refs) > count && filepath.Ext(refs[len(refs)-1]) == ext {
+			inputs = append(inputs, refs[len(refs)-1])
+		}
+	}
+	for _, m := range inputPattern.FindAllStringSubmatch(content, -1) {
+		addInput(m[2], ".tex")
+	}
+	for _, m := range importPattern.FindAllStringSubmatch(content, -1) {
+		addInput(filepath.Join(strings.TrimSpace(m[1]), strings.TrimSpace(m[2])), ".tex")
+	}
+	for _, m := range includePDFPattern.FindAllStringSubmatch(content, -1) {
+		addFile(m[1], ".pdf")
+	}
+	for _, m := range listingPattern.FindAllStringSubmatch(content, -1) {
+		addFile(m[1])
+	}
+	for _, m := range classPattern.FindAllStringSubmatch(content, -1) {
+		addInput(m[1], ".cls")
+	}
+	for _, m := range packagePattern.FindAllStringSubmatch(content, -1) {
+		for _, name := range strings.Split(m[1], ",") {
+			addInput(name, ".sty")
+		}
+	}
+	for _, m := range bibliographyPattern.FindAllStringSubmatch(content, -1) {
+		for _, name := range strings.Split(m[1], ",") {
+			addFile(name, ".bib")
+		}
+	}
+	for _, m := range bibResourcePattern.FindAllStringSubmatch(content, -1) {
+		addFile(m[1])
+	}
+	for _, m := range bibStylePattern.FindAllStringSubmatch(content, -1) {
+		addFile(m[1], ".bst")
+	}
+	for _, m := range fontPathPattern.FindAllStringSubmatch(content, -1) {
+		addFile(m[1])
+	}
+	// A leading slash makes a Typst path relative to the project root, which the source directory becomes
+	for _, m := range typstFilePattern.FindAllStringSubmatch(content, -1) {
+		addFile(strings.TrimPrefix(m[1], "/"))
+	}
+	for _, m := range typstModulePattern.FindAllStringSubmatch(content, -1) {
+		addInput(strings.TrimPrefix(m[1], "/"), ".typ")
+	}
+
+	return refs, inputs
+}
+
+// stage links or copies src to dst; returns false when src does not exist or dst is already present
+func (s *AssetStagerAdapter) stage(ctx context.Context, src, dst string) (bool, error) {
+	info, err := s.fileSystem.Stat(ctx, src)
+	if err != nil {
+		return false, nil
+	}
+	if _, err := s.fileSystem.Stat(ctx, dst); err == nil {
+		return false, nil
+	}
+	if err := s.fileSystem.MkdirAll(ctx, filepath.Dir(dst), 0755); err != nil {
+		return false, err
+	}
+
+	if s.mode == ports.AssetModeSymlink {
+		return true, s.fileSystem.Symlink(ctx, src, dst)
+	}
+	if !info.IsDir() {
+		return true, s.copyFile(ctx, src, dst, info.Mode().Perm())
+	}
+	return true, filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			return s.fileSystem.MkdirAll(ctx, target, 0755)
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return s.copyFile(ctx, path, target, fileInfo.Mode().Perm())
+	})
+}
+
+// copyFile copies a single file through the FileSystem port
+func (s *AssetStagerAdapter) copyFile(ctx context.Context, src, dst string, perm fs.FileMode) error {
+	data, err := s.fileSystem.ReadFile(ctx, src)
+	if err != nil {
+		return err
+	}
+	return s.fileSystem.WriteFile(ctx, dst, data, perm)
+}
+
+// stripComments removes LaTeX comments so commented-out references are not staged
+// Typst sources are left as they are: % is text there, as in 50%, and // also occurs in URLs
+func stripComments(content string, typst bool) string {
+	if typst {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = commentPattern.ReplaceAllString(line, "$1")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolve returns the path of a reference, relative to sourceDir unless absolute
+func resolve(sourceDir, ref string) string {
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(sourceDir, ref)
+}
+
+// isLocal reports whether rel stays inside the source directory;
+// absolute paths are read by the engine directly and cannot be staged
+func isLocal(rel string) bool {
+	return rel != "." && !filepath.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package assets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTree creates the files (relative path -> content) under dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func newStager(t *testing.T, mode string, extra ...string) *AssetStagerAdapter {
+	t.Helper()
+	stager, err := NewAssetStagerAdapter(infraadapters.NewOSFileSystem(), ports.EnginePDFLaTeX, mode, extra)
+	require.NoError(t, err)
+	return stager
+}
+
+const invoiceTemplate = `\documentclass[a4paper]{letterhead}
+\usepackage{graphicx,brand}
+\usepackage{fontspec}
+\setmainfont{Inter}[Path=fonts/, Extension=.otf]
+\graphicspath{{img/}}
+% \includegraphics{draft-watermark}
+\begin{document}
+\includegraphics[width=3cm]{logo}
+\includegraphics{photos/team.jpg}
+\input{sections/terms}
+\bibliographystyle{brand}
+\bibliography{refs,extra}
+\end{document}
+`
+
+func TestAssetStagerAdapter_Stage(t *testing.T) {
+	source := t.TempDir()
+	target := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"letterhead.cls":          "class",
+		"brand.sty":               "package",
+		"brand.bst":               "style",
+		"fonts/Inter-Regular.otf": "font",
+		"img/stamp.png":           "stamp",
+		"logo.png":                "logo",
+		"photos/team.jpg":         "team",
+		"sections/terms.tex":      `\includegraphics{signature}`,
+		"signature.pdf":           "signature",
+		"refs.bib":                "@book{}",
+		"draft-watermark.png":     "unused",
+		"unrelated.txt":           "unused",
+	})
+
+	staged, err := newStager(t, "").Stage(context.Background(), invoiceTemplate, source, target)
+	require.NoError(t, err)
+
+	assert.Empty(t, staged.Unstaged)
+	assert.ElementsMatch(t, []string{
+		"letterhead.cls",
+		"brand.sty",
+		"brand.bst",
+		"fonts",
+		"img",
+		"logo.png",
+		filepath.Join("photos", "team.jpg"),
+		filepath.Join("sections", "terms.tex"),
+		"signature.pdf",
+		"refs.bib",
+	}, staged.Files)
+
+	link, err := os.Readlink(filepath.Join(target, "logo.png"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(source, "logo.png"), link)
+
+	// Commented-out and unreferenced files stay behind
+	for _, rel := range []string{"draft-watermark.png", "unrelated.txt"} {
+		_, err := os.Lstat(filepath.Join(target, rel))
+		assert.True(t, os.IsNotExist(err), rel)
+	}
+}
+
+func TestAssetStagerAdapter_StageTypst(t *testing.T) {
+	source := t.TempDir()
+	target := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"logo.svg":           "logo",
+		"data/items.csv":     "a,b",
+		"refs.bib":           "@book{}",
+		"brand.typ":          `#let stamp = image("stamp.png")`,
+		"stamp.png":          "stamp",
+		"seal.png":           "seal",
+		"chapters/terms.typ": "terms",
+		"unrelated.txt":      "unused",
+	})
+
+	content := `#import "brand.typ": stamp
+#import "@preview/tablex:0.0.8": tablex
+#image("logo.svg", width: 3cm)
+#box(width: 50%)[#image("seal.png")]
+#let items = csv("/data/items.csv")
+#include "chapters/terms.typ"
+#bibliography("refs.bib")
+`
+	stager, err := NewAssetStagerAdapter(infraadapters.NewOSFileSystem(), ports.EngineTypst, "", nil)
+	require.NoError(t, err)
+	staged, err := stager.Stage(context.Background(), content, source, target)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"brand.typ",
+		"seal.png",
+		"stamp.png",
+		"logo.svg",
+		filepath.Join("data", "items.csv"),
+		filepath.Join("chapters", "terms.typ"),
+		"refs.bib",
+	}, staged.Files)
+}
+
+func TestAssetStagerAdapter_CopyMode(t *testing.T) {
+	source := t.TempDir()
+	target := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"logo.png":                "logo",
+		"fonts/Inter-Regular.otf": "font",
+	})
+
+	content := `\setmainfont{Inter}[Path=./fonts/]` + "\n" + `\includegraphics{logo}`
+	_, err := newStager(t, ports.AssetModeCopy).Stage(context.Background(), content, source, target)
+	require.NoError(t, err)
+
+	for _, rel := range []string{"logo.png", filepath.Join("fonts", "Inter-Regular.otf")} {
+		info, err := os.Lstat(filepath.Join(target, rel))
+		require.NoError(t, err, rel)
+		assert.True(t, info.Mode().IsRegular(), "%s should be a copy", rel)
+	}
+}
+
+func TestAssetStagerAdapter_ExtraGlobs(t *testing.T) {
+	source := t.TempDir()
+	target := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"data/rates.csv": "a,b",
+		"data/notes.md":  "notes",
+	})
+
+	staged, err := newStager(t, "", "data/*.csv").Stage(context.Background(), `\documentclass{article}`, source, target)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("data", "rates.csv")}, staged.Files)
+}
+
+func TestAssetStagerAdapter_SameDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"logo.png": "logo"})
+
+	staged, err := newStager(t, "").Stage(context.Background(), `\includegraphics{logo}`, dir, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logo.png"}, staged.Files, "dependencies are listed even though nothing is staged")
+
+	staged, err = newStager(t, "").Stage(context.Background(), `\documentclass{article}`, dir, dir)
+	require.NoError(t, err)
+	assert.NotNil(t, staged.Files, "a document without dependencies has known inputs")
+}
+
+func TestAssetStagerAdapter_ReportsPathsOutsideSource(t *testing.T) {
+	parent := t.TempDir()
+	source := filepath.Join(parent, "template")
+	target := t.TempDir()
+	writeTree(t, parent, map[string]string{
+		"shared/logo.png":    "logo",
+		"shared/seal.png":    "seal",
+		"template/local.png": "local",
+	})
+	seal := filepath.Join(parent, "shared", "seal.png")
+
+	content := `\includegraphics{../shared/logo.png}` + "\n" + `\includegraphics{local}` + "\n" + `\includegraphics{` + seal + `}`
+	staged, err := newStager(t, "").Stage(context.Background(), content, source, target)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local.png"}, staged.Files)
+	assert.ElementsMatch(t, []string{filepath.Join("..", "shared", "logo.png"), seal}, staged.Unstaged)
+	assert.True(t, staged.LeavesSourceDir())
+}
+
+func TestAssetStagerAdapter_FollowsClassesAndPackages(t *testing.T) {
+	source := t.TempDir()
+	target := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"letterhead.cls":     "\\LoadClass{article}\n\\RequirePackage{brand}\n\\AtBeginDocument{\\includegraphics{crest}}\n",
+		"brand.sty":          "\\input{colors}\n% \\includegraphics{unused}\n",
+		"colors.tex":         "\\definecolor{brand}{HTML}{003366}",
+		"crest.png":          "crest",
+		"unused.png":         "unused",
+		"forms/terms.pdf":    "terms",
+		"code/main.go":       "package main",
+		"chapters/intro.tex": "\\includegraphics{chart}",
+		"chart.pdf":          "chart",
+		"appendix/extra.tex": "extra",
+	})
+
+	content := `\documentclass{letterhead}
+\usepackage{pdfpages,listings}
+\begin{document}
+\includepdf[pages=-]{forms/terms}
+\lstinputlisting[language=Go]{code/main.go}
+\import{chapters/}{intro}
+\subimport*{appendix/}{extra.tex}
+\end{document}
+`
+	staged, err := newStager(t, "").Stage(context.Background(), content, source, target)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"letterhead.cls",
+		"brand.sty",
+		"colors.tex",
+		"crest.png",
+		filepath.Join("forms", "terms.pdf"),
+		filepath.Join("code", "main.go"),
+		filepath.Join("chapters", "intro.tex"),
+		"chart.pdf",
+		filepath.Join("appendix", "extra.tex"),
+	}, staged.Files)
+}
+
+func TestNewAssetStagerAdapter_InvalidSettings(t *testing.T) {
+	_, err := NewAssetStagerAdapter(infraadapters.NewOSFileSystem(), ports.EnginePDFLaTeX, "hardlink", nil)
+	assert.Error(t, err)
+
+	_, err = NewAssetStagerAdapter(infraadapters.NewOSFileSystem(), ports.EnginePDFLaTeX, "", []string{"[unclosed"})
+	assert.Error(t, err)
+}
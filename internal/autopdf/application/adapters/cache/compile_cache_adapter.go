@@ -0,0 +1,422 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+)
+
+// keyVersion changes whenever the key derivation does, orphaning older entries
+const keyVersion = "autopdf-compile-cache/v1"
+
+// DefaultCacheDir returns the directory holding cached PDFs when none is configured
+func DefaultCacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "autopdf", "compile")
+	}
+	return filepath.Join(os.TempDir(), "autopdf", "compile")
+}
+
+// EvictionPolicy bounds the cache; a zero field leaves that dimension unbounded
+// Entries are evicted least recently used first, hits refresh an entry
+type EvictionPolicy struct {
+	MaxBytes int64
+	MaxAge   time.Duration
+}
+
+// EngineSettings are the settings of the tectonic and typst adapters that change the produced PDF
+// They are configured on the adapters rather than passed in CompileOptions, so the cache is told them
+type EngineSettings struct {
+	Tectonic config.Tectonic
+	Typst    config.Typst
+}
+
+// CompileCacheAdapter is a LaTeXCompiler decorator reusing the PDF of an identical compilation
+// The key hashes the processed LaTeX, the options that change the output and the
+// content of opts.Inputs; files the engine finds elsewhere, such as installed packages, are not part of it
+type CompileCacheAdapter struct {
+	compiler ports.LaTeXCompiler
+	dir      string
+	policy   EvictionPolicy
+	engine   EngineSettings
+}
+
+// cacheEntry is the metadata stored next to each cached PDF
+type cacheEntry struct {
+	Passes      int                `json:"passes"`
+	Diagnostics []ports.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// NewCompileCacheAdapter wraps compiler with a cache in dir; an empty dir uses DefaultCacheDir
+func NewCompileCacheAdapter(compiler ports.LaTeXCompiler, dir string, policy EvictionPolicy) (*CompileCacheAdapter, error) {
+	if policy.MaxBytes < 0 || policy.MaxAge < 0 {
+		return nil, fmt.Errorf("cache limits must not be negative")
+	}
+	if dir == "" {
+		dir = DefaultCacheDir()
+	}
+	return &CompileCacheAdapter{
+		compiler: compiler,
+		dir:      dir,
+		policy:   policy,
+	}, nil
+}
+
+// WithEngineSettings keys the cache on the engine adapters' settings as well
+func (c *CompileCacheAdapter) WithEngineSettings(settings EngineSettings) *CompileCacheAdapter {
+	c.engine = settings
+	return c
+}
+
+// Dir returns the directory holding the cached PDFs
+func (c *CompileCacheAdapter) Dir() string {
+	return c.dir
+}
+
+// Compile returns the cached PDF for identical inputs, compiling and storing it otherwise
+// Debug builds always compile, since they are run to inspect the engine's files, and so do
+// builds whose inputs are unknown (nil Inputs), whose PDF may depend on any file of the working directory
+func (c *CompileCacheAdapter) Compile(ctx context.Context, content string, opts ports.CompileOptions) (ports.CompileResult, error) {
+	if opts.NoCache || opts.Debug || opts.Inputs == nil {
+		result, err := c.compiler.Compile(ctx, content, opts)
+		result.Cache = ports.CacheBypassed
+		return result, err
+	}
+
+	key, err := Key(content, opts, c.engine)
+	if err != nil {
+		// Inputs that cannot be read cannot be vouched for
+		result, err := c.compiler.Compile(ctx, content, opts)
+		result.Cache = ports.CacheBypassed
+		return result, err
+	}
+
+	if result, ok := c.load(key, opts.OutputPath); ok {
+		return result, nil
+	}
+
+	result, err := c.compiler.Compile(ctx, content, opts)
+	result.Cache = ports.CacheMiss
+	result.CacheKey = key
+	if err != nil {
+		return result, err
+	}
+
+	// A cache that cannot be written only costs the next build a compilation
+	if c.store(key, result) == nil {
+		_ = c.evict()
+	}
+	return result, nil
+}
+
+// load copies the cached PDF to outputPath; reports false on a miss or an unreadable entry
+func (c *CompileCacheAdapter) load(key, outputPath string) (ports.CompileResult, bool) {
+	pdfPath, metaPath := c.paths(key)
+
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return ports.CompileResult{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return ports.CompileResult{}, false
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return ports.CompileResult{}, false
+	}
+	if err := copyFile(pdfPath, outputPath); err != nil {
+		return ports.CompileResult{}, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(pdfPath, now, now)
+
+	return ports.CompileResult{
+		PDFPath:     outputPath,
+		Diagnostics: entry.Diagnostics,
+		Passes:      entry.Passes,
+		Cache:       ports.CacheHit,
+		CacheKey:    key,
+	}, true
+}
+
+// store saves the compiled PDF and its metadata under key
+// Both files are written to temporary names and renamed, so concurrent builds never read a partial entry
+func (c *CompileCacheAdapter) store(key string, result ports.CompileResult) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	pdfPath, metaPath := c.paths(key)
+
+	meta, err := json.Marshal(cacheEntry{Passes: result.Passes, Diagnostics: result.Diagnostics})
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(pdfPath, func(w io.Writer) error {
+		src, err := os.Open(result.PDFPath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	}); err != nil {
+		return err
+	}
+	// The metadata is written last: an entry without it is a miss
+	return writeAtomic(metaPath, func(w io.Writer) error {
+		_, err := w.Write(meta)
+		return err
+	})
+}
+
+// cachedPDF is an entry found on disk
+type cachedPDF struct {
+	key     string
+	size    int64
+	usedAt  time.Time
+	expired bool
+}
+
+// evict removes expired entries, then the least recently used until the cache fits MaxBytes
+func (c *CompileCacheAdapter) evict() error {
+	if c.policy == (EvictionPolicy{}) {
+		return nil
+	}
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var pdfs []cachedPDF
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".pdf" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		pdfs = append(pdfs, cachedPDF{
+			key:     strings.TrimSuffix(name, ".pdf"),
+			size:    info.Size(),
+			usedAt:  info.ModTime(),
+			expired: c.policy.MaxAge > 0 && now.Sub(info.ModTime()) > c.policy.MaxAge,
+		})
+	}
+
+	// Most recently used first, so everything past the size budget is removed
+	sort.Slice(pdfs, func(i, j int) bool {
+		return pdfs[i].usedAt.After(pdfs[j].usedAt)
+	})
+
+	var total int64
+	for _, pdf := range pdfs {
+		if !pdf.expired {
+			total += pdf.size
+			if c.policy.MaxBytes == 0 || total <= c.policy.MaxBytes {
+				continue
+			}
+		}
+		pdfPath, metaPath := c.paths(pdf.key)
+		_ = os.Remove(metaPath)
+		if err := os.Remove(pdfPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to evict cached PDF %s: %w", pdf.key, err)
+		}
+	}
+	return nil
+}
+
+// paths returns where the PDF and the metadata of an entry are stored
+func (c *CompileCacheAdapter) paths(key string) (pdfPath, metaPath string) {
+	base := filepath.Join(c.dir, key)
+	return base + ".pdf", base + ".json"
+}
+
+// keyOptions are the compile options that change the produced PDF
+type keyOptions struct {
+	Engine        string
+	Passes        int
+	AutoPasses    bool
+	UseLatexmk    bool
+	Toolchain     ports.Toolchain
+	JobName       string
+	ShellEscape   string
+	ParanoidFiles bool
+	Bundle        string   `json:",omitempty"`
+	OnlyCached    bool     `json:",omitempty"`
+	FontPaths     []string `json:",omitempty"`
+	PackagePath   string   `json:",omitempty"`
+}
+
+// Key hashes everything the PDF of a compilation depends on that AutoPDF controls:
+// the processed LaTeX, the options that change the output, the settings of the engine
+// compiling it and each input file's content
+func Key(content string, opts ports.CompileOptions, engine EngineSettings) (string, error) {
+	h := sha256.New()
+	key := keyOptions{
+		Engine:        opts.Engine,
+		Passes:        opts.Passes,
+		AutoPasses:    opts.AutoPasses,
+		UseLatexmk:    opts.UseLatexmk,
+		Toolchain:     opts.Toolchain,
+		JobName:       opts.JobName,
+		ShellEscape:   opts.Security.ShellEscape,
+		ParanoidFiles: opts.Security.ParanoidFiles,
+	}
+	switch opts.Engine {
+	case ports.EngineTectonic:
+		key.Bundle = engine.Tectonic.Bundle
+		key.OnlyCached = engine.Tectonic.OnlyCached
+	case ports.EngineTypst:
+		key.FontPaths = engine.Typst.FontPaths
+		key.PackagePath = engine.Typst.PackagePath
+	}
+	options, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "%s\n%s\n%d\n%s\n", keyVersion, options, len(content), content)
+
+	inputs := append([]string(nil), opts.Inputs...)
+	sort.Strings(inputs)
+	for _, rel := range inputs {
+		if err := hashInput(h, opts.WorkingDir, rel); err != nil {
+			return "", fmt.Errorf("failed to hash input %s: %w", rel, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashInput adds the path and content of a file, or of every file below a directory, to h
+// Symbolic links are followed: staged assets usually link to the template's directory
+func hashInput(h hash.Hash, workingDir, rel string) error {
+	root := filepath.Join(workingDir, rel)
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return hashFile(h, rel, root)
+	}
+
+	// WalkDir does not descend into a linked directory, so walk its resolved path
+	resolved, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(resolved, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		sub, err := filepath.Rel(resolved, path)
+		if err != nil {
+			return err
+		}
+		return hashFile(h, filepath.Join(rel, sub), path)
+	})
+}
+
+// hashFile adds one file's relative path, size and content to h
+func hashFile(h hash.Hash, rel, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fileHash := sha256.New()
+	size, err := io.Copy(fileHash, f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "%s\n%d\n%x\n", filepath.ToSlash(rel), size, fileHash.Sum(nil))
+	return nil
+}
+
+// writeAtomic writes a file through a temporary sibling and renames it into place
+func writeAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// copyFile copies src to dst, replacing dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return writeAtomic(dst, func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
+
+// Defaults for an enabled cache without explicit limits
+const (
+	defaultMaxBytes = 512 << 20
+	defaultMaxAge   = 7 * 24 * time.Hour
+)
+
+// NewCompileCacheFromConfig wraps compiler with the cache a config's cache section
+// describes, keyed on its tectonic and typst settings; compiler is returned unchanged when the cache is disabled
+func NewCompileCacheFromConfig(compiler ports.LaTeXCompiler, cfg *config.Config) (ports.LaTeXCompiler, error) {
+	if !cfg.Cache.Enabled {
+		return compiler, nil
+	}
+	maxAge, err := cfg.Cache.MaxAgeDuration()
+	if err != nil {
+		return nil, err
+	}
+	policy := EvictionPolicy{
+		MaxBytes: int64(cfg.Cache.MaxSizeMB) << 20,
+		MaxAge:   maxAge,
+	}
+	if policy.MaxBytes == 0 {
+		policy.MaxBytes = defaultMaxBytes
+	}
+	if policy.MaxAge == 0 {
+		policy.MaxAge = defaultMaxAge
+	}
+	adapter, err := NewCompileCacheAdapter(compiler, cfg.Cache.Dir, policy)
+	if err != nil {
+		return nil, err
+	}
+	return adapter.WithEngineSettings(EngineSettings{Tectonic: cfg.Tectonic, Typst: cfg.Typst}), nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCompiler writes a PDF holding the content and counts its compilations
+type countingCompiler struct {
+	calls int
+	err   error
+}
+
+func (c *countingCompiler) Compile(ctx context.Context, content string, opts ports.CompileOptions) (ports.CompileResult, error) {
+	c.calls++
+	if c.err != nil {
+		return ports.CompileResult{}, c.err
+	}
+	if err := os.WriteFile(opts.OutputPath, []byte("%PDF-1.5 "+content), 0644); err != nil {
+		return ports.CompileResult{}, err
+	}
+	return ports.CompileResult{
+		PDFPath:     opts.OutputPath,
+		Passes:      2,
+		Diagnostics: []ports.Diagnostic{{Kind: ports.DiagnosticWarning, Severity: ports.SeverityWarning, Message: "Overfull"}},
+	}, nil
+}
+
+func newCache(t *testing.T, policy EvictionPolicy) (*CompileCacheAdapter, *countingCompiler) {
+	t.Helper()
+	compiler := &countingCompiler{}
+	adapter, err := NewCompileCacheAdapter(compiler, t.TempDir(), policy)
+	require.NoError(t, err)
+	return adapter, compiler
+}
+
+func compileOptions(workingDir string) ports.CompileOptions {
+	return ports.NewCompileOptions("pdflatex", filepath.Join(workingDir, "doc.pdf"), workingDir).WithJobName("doc").WithInputs([]string{})
+}
+
+func TestCompileCacheAdapter_HitAndMiss(t *testing.T) {
+	adapter, compiler := newCache(t, EvictionPolicy{})
+
+	first, err := adapter.Compile(context.Background(), "statement", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheMiss, first.Cache)
+	assert.NotEmpty(t, first.CacheKey)
+
+	// Another build of the same document, in another workspace
+	opts := compileOptions(t.TempDir())
+	second, err := adapter.Compile(context.Background(), "statement", opts)
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheHit, second.Cache)
+	assert.Equal(t, first.CacheKey, second.CacheKey)
+	assert.Equal(t, opts.OutputPath, second.PDFPath)
+	assert.Equal(t, first.Passes, second.Passes)
+	assert.Equal(t, first.Diagnostics, second.Diagnostics)
+	assert.Equal(t, 1, compiler.calls)
+
+	data, err := os.ReadFile(second.PDFPath)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.5 statement", string(data))
+
+	// Different content, engine or passes compile again
+	_, err = adapter.Compile(context.Background(), "another statement", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+	_, err = adapter.Compile(context.Background(), "statement", compileOptions(t.TempDir()).WithPasses(3))
+	require.NoError(t, err)
+	assert.Equal(t, 3, compiler.calls)
+}
+
+func TestCompileCacheAdapter_InputsArePartOfTheKey(t *testing.T) {
+	adapter, compiler := newCache(t, EvictionPolicy{})
+	assets := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(assets, "logo.png"), []byte("v1"), 0644))
+
+	build := func() ports.CompileResult {
+		workingDir := t.TempDir()
+		require.NoError(t, os.Symlink(filepath.Join(assets, "logo.png"), filepath.Join(workingDir, "logo.png")))
+		result, err := adapter.Compile(context.Background(), "letter", compileOptions(workingDir).WithInputs([]string{"logo.png"}))
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, ports.CacheMiss, build().Cache)
+	assert.Equal(t, ports.CacheHit, build().Cache)
+
+	require.NoError(t, os.WriteFile(filepath.Join(assets, "logo.png"), []byte("v2"), 0644))
+	assert.Equal(t, ports.CacheMiss, build().Cache, "a changed asset must recompile")
+	assert.Equal(t, 2, compiler.calls)
+}
+
+func TestKey_EngineSettings(t *testing.T) {
+	tectonic := compileOptions(t.TempDir())
+	tectonic.Engine = ports.EngineTectonic
+	typst := compileOptions(t.TempDir())
+	typst.Engine = ports.EngineTypst
+
+	key := func(opts ports.CompileOptions, settings EngineSettings) string {
+		k, err := Key("report", opts, settings)
+		require.NoError(t, err)
+		return k
+	}
+
+	base := key(tectonic, EngineSettings{})
+	assert.NotEqual(t, base, key(tectonic, EngineSettings{Tectonic: config.Tectonic{Bundle: "https://example.com/bundle.tar"}}))
+	assert.NotEqual(t, base, key(tectonic, EngineSettings{Tectonic: config.Tectonic{OnlyCached: true}}))
+	assert.Equal(t, base, key(tectonic, EngineSettings{Tectonic: config.Tectonic{CacheDir: "/var/cache/tectonic"}}), "where bundles are cached does not change the PDF")
+
+	base = key(typst, EngineSettings{})
+	assert.NotEqual(t, base, key(typst, EngineSettings{Typst: config.Typst{FontPaths: []string{"fonts"}}}))
+	assert.NotEqual(t, base, key(typst, EngineSettings{Typst: config.Typst{PackagePath: "packages"}}))
+	assert.Equal(t, base, key(typst, EngineSettings{Tectonic: config.Tectonic{Bundle: "bundle.tar"}}), "another engine's settings are not part of the key")
+}
+
+func TestCompileCacheAdapter_Bypass(t *testing.T) {
+	adapter, compiler := newCache(t, EvictionPolicy{})
+	_, err := adapter.Compile(context.Background(), "statement", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+
+	result, err := adapter.Compile(context.Background(), "statement", compileOptions(t.TempDir()).WithNoCache(true))
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheBypassed, result.Cache)
+
+	result, err = adapter.Compile(context.Background(), "statement", compileOptions(t.TempDir()).WithDebug(true))
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheBypassed, result.Cache)
+
+	// Without an asset stager the files the document reads are unknown
+	result, err = adapter.Compile(context.Background(), "statement", compileOptions(t.TempDir()).WithInputs(nil))
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheBypassed, result.Cache)
+	assert.Equal(t, 4, compiler.calls)
+}
+
+func TestCompileCacheAdapter_FailuresAreNotCached(t *testing.T) {
+	adapter, compiler := newCache(t, EvictionPolicy{})
+	compiler.err = errors.New("undefined control sequence")
+
+	_, err := adapter.Compile(context.Background(), "broken", compileOptions(t.TempDir()))
+	require.Error(t, err)
+
+	compiler.err = nil
+	result, err := adapter.Compile(context.Background(), "broken", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheMiss, result.Cache)
+	assert.Equal(t, 2, compiler.calls)
+}
+
+func TestCompileCacheAdapter_EvictsBySize(t *testing.T) {
+	// Each PDF is 19 bytes: room for two
+	adapter, compiler := newCache(t, EvictionPolicy{MaxBytes: 40})
+
+	for _, content := range []string{"statement1", "statement2", "statement3"} {
+		_, err := adapter.Compile(context.Background(), content, compileOptions(t.TempDir()))
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond) // Distinct modification times
+	}
+
+	pdfs, err := filepath.Glob(filepath.Join(adapter.Dir(), "*.pdf"))
+	require.NoError(t, err)
+	assert.Len(t, pdfs, 2)
+
+	// The least recently used entry went first
+	result, err := adapter.Compile(context.Background(), "statement1", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheMiss, result.Cache)
+	result, err = adapter.Compile(context.Background(), "statement3", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheHit, result.Cache)
+	assert.Equal(t, 4, compiler.calls)
+}
+
+func TestCompileCacheAdapter_EvictsByAge(t *testing.T) {
+	adapter, _ := newCache(t, EvictionPolicy{MaxAge: time.Hour})
+
+	stale, err := adapter.Compile(context.Background(), "old", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+	pdfPath, metaPath := adapter.paths(stale.CacheKey)
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(pdfPath, old, old))
+
+	_, err = adapter.Compile(context.Background(), "new", compileOptions(t.TempDir()))
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, pdfPath)
+	assert.NoFileExists(t, metaPath)
+}
+
+func TestNewCompileCacheFromConfig(t *testing.T) {
+	compiler := &countingCompiler{}
+
+	uncached, err := NewCompileCacheFromConfig(compiler, &config.Config{})
+	require.NoError(t, err)
+	assert.Same(t, compiler, uncached)
+
+	cached, err := NewCompileCacheFromConfig(compiler, &config.Config{
+		Cache: config.Cache{Enabled: true, Dir: t.TempDir(), MaxAge: "1h"},
+		Typst: config.Typst{PackagePath: "packages"},
+	})
+	require.NoError(t, err)
+	adapter, ok := cached.(*CompileCacheAdapter)
+	require.True(t, ok)
+	assert.Equal(t, EvictionPolicy{MaxBytes: defaultMaxBytes, MaxAge: time.Hour}, adapter.policy)
+	assert.Equal(t, "packages", adapter.engine.Typst.PackagePath)
+
+	_, err = NewCompileCacheFromConfig(compiler, &config.Config{Cache: config.Cache{Enabled: true, MaxAge: "a week"}})
+	assert.Error(t, err)
+}
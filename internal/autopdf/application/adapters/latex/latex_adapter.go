@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +17,8 @@ import (
 	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
+var unsafeSourceChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
 // LaTeXCompilerAdapter wraps the existing LaTeX compiler
 // Now DIP-compliant: depends on ports, not low-level libraries
 type LaTeXCompilerAdapter struct {
@@ -54,9 +58,9 @@ func NewLaTeXCompilerAdapterWithWorkingDir(
 }
 
 // Compile compiles LaTeX content to PDF using the new CompileOptions interface
-func (lca *LaTeXCompilerAdapter) Compile(ctx context.Context, content string, opts application.CompileOptions) (string, error) {
+func (lca *LaTeXCompilerAdapter) Compile(ctx context.Context, content string, opts application.CompileOptions) (application.CompileResult, error) {
 	if content == "" {
-		return "", errors.New("no LaTeX content provided")
+		return application.CompileResult{}, errors.New("no LaTeX content provided")
 	}
 
 	// Use working directory from options if provided, otherwise use adapter's default
@@ -67,21 +71,30 @@ func (lca *LaTeXCompilerAdapter) Compile(ctx context.Context, content string, op
 
 	// Ensure working directory exists
 	if err := lca.fileSystem.MkdirAll(ctx, workingDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create working directory: %w", err)
+		return application.CompileResult{}, fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	if err := validateTeXEngine(opts.Engine); err != nil {
+		return application.CompileResult{}, err
+	}
+	if err := opts.Toolchain.Validate(); err != nil {
+		return application.CompileResult{}, err
 	}
 
 	// Verify that the engine is installed
 	if _, err := lca.executor.Execute(ctx, application.NewCommand("which", []string{opts.Engine}, "")); err != nil {
-		return "", fmt.Errorf("LaTeX engine not found: %s", opts.Engine)
+		return application.CompileResult{}, fmt.Errorf("LaTeX engine not found: %s", opts.Engine)
 	}
 
 	// Generate concrete file name using job name
-	concreteFileName := fmt.Sprintf("%s.tex", opts.JobName)
+	// The engine reads it relative to the working directory, so it is the only path
+	// that has to survive TeX's own file name parsing
+	concreteFileName := sourceFileName(opts.JobName)
 	concreteFile := filepath.Join(workingDir, concreteFileName)
 
 	// Write the content to the concrete file
 	if err := lca.fileSystem.WriteFile(ctx, concreteFile, []byte(content), 0644); err != nil {
-		return "", err
+		return application.CompileResult{}, err
 	}
 
 	// Only clean up temp file if not in debug mode
@@ -102,36 +115,49 @@ func (lca *LaTeXCompilerAdapter) Compile(ctx context.Context, content string, op
 	// Create output directory if needed
 	outputDir := filepath.Dir(pdfPath)
 	if err := lca.fileSystem.MkdirAll(ctx, outputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
+		return application.CompileResult{}, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Get the base name for the LaTeX job
 	baseName := strings.TrimSuffix(filepath.Base(pdfPath), ".pdf")
 
-	// Run multiple passes if requested
-	for pass := 1; pass <= opts.Passes; pass++ {
-		// Create command to run LaTeX
-		var cmdStr string
-		if outputDir == "." {
-			cmdStr = fmt.Sprintf("%s -interaction=nonstopmode -jobname=%s %s", opts.Engine, baseName, concreteFile)
-		} else {
-			cmdStr = fmt.Sprintf("%s -interaction=nonstopmode -jobname=%s -output-directory=%s %s", opts.Engine, baseName, outputDir, concreteFile)
-		}
+	// The engine writes its log next to the PDF, or in the working directory
+	// when no output directory is passed
+	logPath := filepath.Join(outputDir, baseName+".log")
+	if outputDir == "." {
+		logPath = filepath.Join(workingDir, baseName+".log")
+	}
+
+	// In auto mode Passes is only the cap; the detector stops once the output converged
+	detector := newRerunDetector(lca.fileSystem, strings.TrimSuffix(logPath, ".log"))
+	if opts.AutoPasses {
+		detector.Snapshot(ctx)
+	}
+
+	// Bibliography, index and glossary tools run once, after the first pass
+	toolchain := newToolchainRunner(lca.fileSystem, lca.executor)
+	var toolDiagnostics []application.Diagnostic
 
-		cmd := application.NewCommand("sh", []string{"-c", cmdStr}, workingDir).
-			WithTimeout(5 * time.Minute)
+	// Run multiple passes if requested
+	maxPasses := opts.Passes
+	passesRun := 0
+	for pass := 1; pass <= maxPasses; pass++ {
+		passesRun = pass
+		// Create command to run LaTeX: no shell, every value is its own argument
+		cmd := application.NewCommand(opts.Engine, engineArgs(baseName, outputDir, concreteFileName), workingDir).
+			WithTimeout(5 * time.Minute).
+			WithEnv(searchPathEnv(workingDir, opts.SearchDirs)).
+			WithSecurity(opts.Security)
+		cmdStr := formatArgv(cmd)
 
 		// Run the LaTeX command
 		result, err := lca.executor.Execute(ctx, cmd)
 		if err != nil {
 			// Check if PDF was created despite the error
 			if _, statErr := lca.fileSystem.Stat(ctx, pdfPath); statErr != nil {
-				// Include LaTeX's actual error output in the error message
-				errorDetails := fmt.Sprintf(
-					"LaTeX compilation failed (pass %d/%d):\nCommand: %s\nWorking Dir: %s\nStderr:\n%s\nStdout:\n%s",
-					pass, opts.Passes, cmdStr, workingDir, result.Stderr, result.Stdout,
-				)
-				return "", fmt.Errorf("%s: %w", errorDetails, err)
+				diagnostics := readLogDiagnostics(ctx, lca.fileSystem, logPath)
+				return application.CompileResult{Diagnostics: diagnostics},
+					compilationError(diagnostics, pass, maxPasses, cmdStr, workingDir, result, err)
 			}
 			// PDF was created, so continue
 		}
@@ -139,19 +165,122 @@ func (lca *LaTeXCompilerAdapter) Compile(ctx context.Context, content string, op
 		// Check if output PDF exists and has content
 		fileInfo, statErr := lca.fileSystem.Stat(ctx, pdfPath)
 		if statErr != nil {
+			diagnostics := readLogDiagnostics(ctx, lca.fileSystem, logPath)
 			if lca.fileSystem.IsNotExist(statErr) {
-				return "", fmt.Errorf("PDF output file was not created (pass %d/%d)", pass, opts.Passes)
+				return application.CompileResult{Diagnostics: diagnostics}, &application.CompilationError{
+					Diagnostics: diagnostics,
+					Err:         fmt.Errorf("PDF output file was not created (pass %d/%d)", pass, maxPasses),
+				}
 			}
-			return "", fmt.Errorf("error checking output file (pass %d/%d): %w", pass, opts.Passes, statErr)
+			return application.CompileResult{Diagnostics: diagnostics}, fmt.Errorf("error checking output file (pass %d/%d): %w", pass, maxPasses, statErr)
 		}
 
 		// Check if file is empty
 		if fileInfo.Size() == 0 {
-			return "", fmt.Errorf("PDF output file was created but is empty (pass %d/%d)", pass, opts.Passes)
+			diagnostics := readLogDiagnostics(ctx, lca.fileSystem, logPath)
+			return application.CompileResult{Diagnostics: diagnostics}, &application.CompilationError{
+				Diagnostics: diagnostics,
+				Err:         fmt.Errorf("PDF output file was created but is empty (pass %d/%d)", pass, maxPasses),
+			}
+		}
+
+		toolsRan := false
+		if pass == 1 {
+			toolsRan, toolDiagnostics = toolchain.Run(ctx, opts.Toolchain, opts.Security, filepath.Dir(logPath), append([]string{workingDir}, opts.SearchDirs...), baseName)
+			// The engine has to read the tools' output and then resolve the new references
+			if toolsRan && maxPasses < toolchainPasses {
+				maxPasses = toolchainPasses
+			}
+		}
+
+		if opts.AutoPasses {
+			if rerun, _ := detector.NeedsRerun(ctx, logPath); !rerun && !toolsRan {
+				break
+			}
+		}
+	}
+
+	// Only the final pass matters: earlier passes legitimately report unresolved references
+	return application.CompileResult{
+		PDFPath:     pdfPath,
+		Diagnostics: append(readLogDiagnostics(ctx, lca.fileSystem, logPath), toolDiagnostics...),
+		Passes:      passesRun,
+	}, nil
+}
+
+// validateTeXEngine accepts the TeX Live engines run directly or through latexmk
+// Tectonic is a supported engine, but only TectonicCompilerAdapter runs it
+func validateTeXEngine(engine string) error {
+	if err := application.ValidateEngine(engine); err != nil {
+		return err
+	}
+	switch engine {
+	case application.EngineTectonic:
+		return fmt.Errorf("%w %q for this compiler: tectonic is run by TectonicCompilerAdapter", application.ErrUnsupportedEngine, engine)
+	case application.EngineTypst:
+		return fmt.Errorf("%w %q for this compiler: typst is run by TypstCompilerAdapter", application.ErrUnsupportedEngine, engine)
+	}
+	return nil
+}
+
+// engineArgs builds the engine's argv; paths are passed verbatim and never parsed by a shell
+// The output directory is omitted when the PDF goes to the working directory
+func engineArgs(jobName, outputDir, sourceFile string) []string {
+	args := []string{
+		"-interaction=nonstopmode",
+		"-file-line-error",
+		"-jobname=" + jobName,
+	}
+	if outputDir != "." {
+		args = append(args, "-output-directory="+outputDir)
+	}
+	return append(args, sourceFile)
+}
+
+// sourceFileName names the file holding the processed content
+// TeX splits its input file name at spaces and treats a leading dash as an option,
+// so anything beyond letters, digits, dots, dashes and underscores is replaced
+func sourceFileName(jobName string) string {
+	name := unsafeSourceChars.ReplaceAllString(jobName, "_")
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") {
+		name = "_" + name
+	}
+	return name + ".tex"
+}
+
+// formatArgv renders a command for error messages, quoting arguments that need it
+func formatArgv(cmd application.Command) string {
+	parts := make([]string, 0, len(cmd.Args)+1)
+	for _, arg := range append([]string{cmd.Executable}, cmd.Args...) {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`;&|<>(){}*?") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// compilationError wraps a failed engine run with its parsed diagnostics
+// Raw engine output is only included when the log yielded no error diagnostics
+func compilationError(diagnostics []application.Diagnostic, pass, passes int, cmdStr, workingDir string, result application.CommandResult, err error) error {
+	for _, d := range diagnostics {
+		if d.IsError() {
+			return &application.CompilationError{
+				Diagnostics: diagnostics,
+				Err:         fmt.Errorf("LaTeX compilation failed (pass %d/%d): %w", pass, passes, err),
+			}
 		}
 	}
 
-	return pdfPath, nil
+	// Include LaTeX's actual error output in the error message
+	errorDetails := fmt.Sprintf(
+		"LaTeX compilation failed (pass %d/%d):\nCommand: %s\nWorking Dir: %s\nStderr:\n%s\nStdout:\n%s",
+		pass, passes, cmdStr, workingDir, result.Stderr, result.Stdout,
+	)
+	return &application.CompilationError{
+		Diagnostics: diagnostics,
+		Err:         fmt.Errorf("%s: %w", errorDetails, err),
+	}
 }
 
 // CompileLegacy provides backward compatibility with the old interface
@@ -159,5 +288,6 @@ func (lca *LaTeXCompilerAdapter) CompileLegacy(ctx context.Context, content stri
 	opts := application.NewCompileOptions(engine, outputPath, lca.workingDir).
 		WithDebug(debugEnabled)
 
-	return lca.Compile(ctx, content, opts)
+	result, err := lca.Compile(ctx, content, opts)
+	return result.PDFPath, err
 }
@@ -0,0 +1,166 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hostileNames are output file names that break or exploit shell-built commands
+var hostileNames = []string{
+	"my invoice",
+	`it's "quoted"`,
+	"$(touch pwned)",
+	"`touch pwned`",
+	"a; touch pwned",
+	"a && touch pwned #",
+	"-output-directory=evil",
+	"line\nbreak",
+}
+
+// recordingEngine captures engine commands and writes the PDF the engine would produce
+type recordingEngine struct {
+	commands []ports.Command
+}
+
+func (e *recordingEngine) Execute(ctx context.Context, cmd ports.Command) (ports.CommandResult, error) {
+	if cmd.Executable == "which" {
+		return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+	}
+	e.commands = append(e.commands, cmd)
+
+	var jobName, outputDir string
+	for _, arg := range cmd.Args {
+		switch {
+		case strings.HasPrefix(arg, "-jobname="):
+			jobName = strings.TrimPrefix(arg, "-jobname=")
+		case strings.HasPrefix(arg, "-output-directory="):
+			outputDir = strings.TrimPrefix(arg, "-output-directory=")
+		}
+	}
+	_ = os.WriteFile(filepath.Join(outputDir, jobName+".pdf"), []byte("%PDF-1.5"), 0644)
+	return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+}
+
+func TestLaTeXCompilerAdapter_ArgvWithHostilePaths(t *testing.T) {
+	for _, name := range hostileNames {
+		t.Run(name, func(t *testing.T) {
+			workingDir := t.TempDir()
+			outputDir := filepath.Join(t.TempDir(), name)
+			engine := &recordingEngine{}
+			adapter := NewLaTeXCompilerAdapterWithWorkingDir(nil, infraadapters.NewOSFileSystem(), engine, workingDir)
+
+			opts := ports.NewCompileOptions("pdflatex", filepath.Join(outputDir, name+".pdf"), workingDir).
+				WithJobName(name).
+				WithDebug(true)
+
+			result, err := adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(outputDir, name+".pdf"), result.PDFPath)
+
+			require.Len(t, engine.commands, 1)
+			cmd := engine.commands[0]
+			assert.Equal(t, "pdflatex", cmd.Executable)
+			assert.Equal(t, workingDir, cmd.Dir)
+			assert.Contains(t, cmd.Args, "-jobname="+name)
+			assert.Contains(t, cmd.Args, "-output-directory="+outputDir)
+
+			// The source file is a plain relative name inside the working directory
+			source := cmd.Args[len(cmd.Args)-1]
+			assert.Regexp(t, `^[A-Za-z0-9_][A-Za-z0-9._-]*\.tex$`, source)
+			assert.FileExists(t, filepath.Join(workingDir, source))
+		})
+	}
+}
+
+func TestSourceFileName(t *testing.T) {
+	tests := map[string]string{
+		"report":                 "report.tex",
+		"my invoice":             "my_invoice.tex",
+		"$(touch pwned)":         "_touch_pwned_.tex",
+		"-output-directory=evil": "_-output-directory_evil.tex",
+		".hidden":                "_.hidden.tex",
+		"":                       "_.tex",
+	}
+	for jobName, expected := range tests {
+		assert.Equal(t, expected, sourceFileName(jobName), jobName)
+	}
+}
+
+// fakeEngineScript stands in for pdflatex: it logs its argv one argument per line
+// and writes the PDF named by -jobname into -output-directory
+const fakeEngineScript = `#!/bin/sh
+for arg in "$@"; do
+	printf '%s\n' "$arg" >> "$ARGV_LOG"
+	case "$arg" in
+		-jobname=*) job="${arg#-jobname=}" ;;
+		-output-directory=*) out="${arg#-output-directory=}" ;;
+	esac
+done
+printf '%%PDF-1.5' > "${out:-.}/$job.pdf"
+`
+
+func TestLaTeXCompilerAdapter_NoShellInterpretation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engine is a POSIX shell script")
+	}
+
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "pdflatex"), []byte(fakeEngineScript), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for _, name := range hostileNames {
+		t.Run(name, func(t *testing.T) {
+			workingDir := t.TempDir()
+			outputDir := filepath.Join(t.TempDir(), name)
+			argvLog := filepath.Join(t.TempDir(), "argv.log")
+			t.Setenv("ARGV_LOG", argvLog)
+
+			adapter := NewLaTeXCompilerAdapterWithWorkingDir(nil, infraadapters.NewOSFileSystem(), infraadapters.NewOSCommandExecutor(), workingDir)
+			opts := ports.NewCompileOptions("pdflatex", filepath.Join(outputDir, name+".pdf"), workingDir).
+				WithJobName(name)
+
+			result, err := adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+			require.NoError(t, err)
+			assert.FileExists(t, result.PDFPath)
+
+			logged, err := os.ReadFile(argvLog)
+			require.NoError(t, err)
+			assert.Contains(t, string(logged), "-output-directory="+outputDir+"\n")
+
+			// Nothing was executed from the name
+			for _, dir := range []string{".", workingDir, outputDir, binDir} {
+				_, err := os.Stat(filepath.Join(dir, "pwned"))
+				assert.True(t, os.IsNotExist(err), "command injected in %s", dir)
+			}
+		})
+	}
+}
+
+func TestLaTeXCompilerAdapter_PassesSecurityPolicy(t *testing.T) {
+	workingDir := t.TempDir()
+	engine := &recordingEngine{}
+	adapter := NewLaTeXCompilerAdapterWithWorkingDir(nil, infraadapters.NewOSFileSystem(), engine, workingDir)
+
+	policy := ports.NewSandboxPolicy()
+	opts := ports.NewCompileOptions("pdflatex", filepath.Join(workingDir, "doc.pdf"), workingDir).
+		WithJobName("doc").
+		WithSecurity(policy)
+
+	_, err := adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+	require.NoError(t, err)
+	require.Len(t, engine.commands, 1)
+	assert.Equal(t, policy, engine.commands[0].Security)
+}
@@ -13,6 +13,13 @@ import (
 	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 )
 
+// latexmkEngineFlags select the engine latexmk runs
+var latexmkEngineFlags = map[string]string{
+	ports.EnginePDFLaTeX: "-pdflatex",
+	ports.EngineXeLaTeX:  "-xelatex",
+	ports.EngineLuaLaTeX: "-lualatex",
+}
+
 // LatexmkCompilerAdapter implements LaTeXCompiler using latexmk
 // This adapter provides multi-pass compilation with automatic dependency tracking
 type LatexmkCompilerAdapter struct {
@@ -36,7 +43,11 @@ func NewLatexmkCompilerAdapterWithLogger(commandExecutor ports.CommandExecutor,
 }
 
 // Compile compiles LaTeX content using latexmk
-func (a *LatexmkCompilerAdapter) Compile(ctx context.Context, content string, opts ports.CompileOptions) (string, error) {
+func (a *LatexmkCompilerAdapter) Compile(ctx context.Context, content string, opts ports.CompileOptions) (ports.CompileResult, error) {
+	if err := validateTeXEngine(opts.Engine); err != nil {
+		return ports.CompileResult{}, err
+	}
+
 	// Write content to temporary .tex file
 	texPath := filepath.Join(opts.WorkingDir, fmt.Sprintf("%s.tex", opts.JobName))
 	err := a.fileSystem.WriteFile(ctx, texPath, []byte(content), 0644)
@@ -44,11 +55,12 @@ func (a *LatexmkCompilerAdapter) Compile(ctx context.Context, content string, op
 		a.logError(ctx, "Failed to write LaTeX content to file",
 			ports.NewLogField("tex_path", texPath),
 			ports.NewLogField("error", err.Error()))
-		return "", fmt.Errorf("failed to write LaTeX content: %w", err)
+		return ports.CompileResult{}, fmt.Errorf("failed to write LaTeX content: %w", err)
 	}
 
 	// Build latexmk command
 	cmd := a.buildLatexmkCommand(opts, texPath)
+	logPath := filepath.Join(a.outputDir(opts), fmt.Sprintf("%s.log", opts.JobName))
 
 	// Log command being executed for transparency
 	cmdString := a.formatCommand(cmd)
@@ -83,7 +95,11 @@ func (a *LatexmkCompilerAdapter) Compile(ctx context.Context, content string, op
 		}
 
 		a.logError(ctx, "latexmk execution failed", logFields...)
-		return "", fmt.Errorf("latexmk execution failed: %w", err)
+		diagnostics := readLogDiagnostics(ctx, a.fileSystem, logPath)
+		return ports.CompileResult{Diagnostics: diagnostics}, &ports.CompilationError{
+			Diagnostics: diagnostics,
+			Err:         fmt.Errorf("latexmk execution failed: %w", err),
+		}
 	}
 
 	if !result.Success {
@@ -95,7 +111,11 @@ func (a *LatexmkCompilerAdapter) Compile(ctx context.Context, content string, op
 			ports.NewLogField("stderr", result.Stderr),
 			ports.NewLogField("exit_code", result.ExitCode),
 			ports.NewLogField("duration", result.Duration.String()))
-		return "", fmt.Errorf("latexmk compilation failed: %s", result.Stderr)
+		diagnostics := readLogDiagnostics(ctx, a.fileSystem, logPath)
+		return ports.CompileResult{Diagnostics: diagnostics}, &ports.CompilationError{
+			Diagnostics: diagnostics,
+			Err:         fmt.Errorf("latexmk compilation failed: %s", result.Stderr),
+		}
 	}
 
 	// Log successful compilation
@@ -117,12 +137,10 @@ func (a *LatexmkCompilerAdapter) Compile(ctx context.Context, content string, op
 
 	// Determine output PDF path - use OutputPath's directory (where PDF was written)
 	// This matches where -outdir was set during compilation
-	outputDir := filepath.Dir(opts.OutputPath)
-	// Fallback to WorkingDir if OutputPath is empty or just a filename
-	if outputDir == "." || outputDir == "" {
-		outputDir = opts.WorkingDir
-	}
-	pdfPath := filepath.Join(outputDir, fmt.Sprintf("%s.pdf", opts.JobName))
+	pdfPath := filepath.Join(a.outputDir(opts), fmt.Sprintf("%s.pdf", opts.JobName))
+
+	// Parse the log before cleanup removes it
+	diagnostics := readLogDiagnostics(ctx, a.fileSystem, logPath)
 
 	// Cleanup aux files if requested and not in debug mode
 	if opts.Cleanup && !opts.Debug {
@@ -135,46 +153,48 @@ func (a *LatexmkCompilerAdapter) Compile(ctx context.Context, content string, op
 		}
 	}
 
-	return pdfPath, nil
+	return ports.CompileResult{PDFPath: pdfPath, Diagnostics: diagnostics}, nil
 }
 
-// buildLatexmkCommand constructs the latexmk command with appropriate options
-func (a *LatexmkCompilerAdapter) buildLatexmkCommand(opts ports.CompileOptions, texPath string) ports.Command {
-	// Use OutputPath's directory for -outdir (where PDF should be written)
-	// This allows compilation to happen in WorkingDir while outputs go to OutputPath's directory
+// outputDir returns the directory latexmk writes the PDF and log to
+// Uses OutputPath's directory, falling back to WorkingDir if OutputPath is empty or just a filename
+func (a *LatexmkCompilerAdapter) outputDir(opts ports.CompileOptions) string {
 	outputDir := filepath.Dir(opts.OutputPath)
-	// Fallback to WorkingDir if OutputPath is empty or just a filename
 	if outputDir == "." || outputDir == "" {
 		outputDir = opts.WorkingDir
 	}
+	return outputDir
+}
 
+// buildLatexmkCommand constructs the latexmk command with appropriate options
+func (a *LatexmkCompilerAdapter) buildLatexmkCommand(opts ports.CompileOptions, texPath string) ports.Command {
+	// Use OutputPath's directory for -outdir (where PDF should be written)
+	// This allows compilation to happen in WorkingDir while outputs go to OutputPath's directory
 	args := []string{
 		"-silent", // Suppress output except errors (for clean logs)
 		"-f",      // Force compilation even if output is up to date (ensures multi-pass)
 		"-interaction=nonstopmode",
 		"-latexoption=-interaction=nonstopmode",
+		"-file-line-error", // Prefix errors with file:line for diagnostics parsing
 		"-jobname=" + opts.JobName,
-		"-outdir=" + outputDir,
+		"-outdir=" + a.outputDir(opts),
 	}
 
-	// Map engine to latexmk flag
-	switch opts.Engine {
-	case "pdflatex":
-		args = append(args, "-pdflatex")
-	case "xelatex":
-		args = append(args, "-xelatex")
-	case "lualatex":
-		args = append(args, "-lualatex")
-	default:
-		// Default to xelatex if unknown engine
-		args = append(args, "-xelatex")
+	// latexmk detects bibliography needs itself; only an explicit opt-out changes that
+	if opts.Toolchain.Bibliography == ports.ToolNone {
+		args = append(args, "-bibtex-")
 	}
 
+	// Compile has already rejected engines without a flag
+	args = append(args, latexmkEngineFlags[opts.Engine])
+
 	// Add the .tex file
 	args = append(args, texPath)
 
 	return ports.NewCommand("latexmk", args, opts.WorkingDir).
-		WithTimeout(5 * time.Minute)
+		WithTimeout(5 * time.Minute).
+		WithEnv(searchPathEnv(opts.WorkingDir, opts.SearchDirs)).
+		WithSecurity(opts.Security)
 }
 
 // cleanupAuxFiles runs latexmk -c to clean auxiliary files
@@ -182,7 +202,7 @@ func (a *LatexmkCompilerAdapter) cleanupAuxFiles(ctx context.Context, opts ports
 	cleanupCmd := ports.NewCommand("latexmk", []string{
 		"-c",
 		"-outdir=" + opts.WorkingDir,
-	}, opts.WorkingDir).WithTimeout(30 * time.Second)
+	}, opts.WorkingDir).WithTimeout(30 * time.Second).WithSecurity(opts.Security)
 
 	cleanupCmdString := a.formatCommand(cleanupCmd)
 	a.logDebug(ctx, "Cleaning auxiliary files",
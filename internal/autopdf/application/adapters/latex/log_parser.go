@@ -0,0 +1,277 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+)
+
+// maxPrintLine is TeX's default line width; longer log lines are hard-wrapped at it
+const maxPrintLine = 79
+
+// maxErrorContextLines bounds how far an error block is scanned for its "l.N" marker
+const maxErrorContextLines = 12
+
+var (
+	fileLineErrorPattern = regexp.MustCompile(`^([^:]+\.\w+):(\d+): (.*)$`)
+	errorLinePattern     = regexp.MustCompile(`^l\.(\d+)`)
+	inputLinePattern     = regexp.MustCompile(`on input line (\d+)`)
+	boxLinePattern       = regexp.MustCompile(`at lines? (\d+)`)
+	warningPattern       = regexp.MustCompile(`^(LaTeX|Package|Class)(?: (\S+))? Warning: (.*)$`)
+	referencePattern     = regexp.MustCompile("Reference `[^']*' on page")
+	citationPattern      = regexp.MustCompile("Citation `[^']*' on page")
+	missingFilePattern   = regexp.MustCompile("File `[^']*' not found|I can't find file `[^']*'")
+	noFilePattern        = regexp.MustCompile(`^No file (\S+)\.$`)
+	filePathPattern      = regexp.MustCompile(`^(?:\.{1,2}/|/|[A-Za-z]:[\\/])|\.[A-Za-z]\w*$`)
+)
+
+// LogParser turns a TeX engine log into typed diagnostics
+// Log format knowledge stays in the adapter layer; callers only see ports.Diagnostic
+type LogParser struct{}
+
+// NewLogParser creates a new log parser
+func NewLogParser() *LogParser {
+	return &LogParser{}
+}
+
+// Parse extracts errors, warnings, box problems, undefined references/citations
+// and missing files from the content of a .log file
+func (p *LogParser) Parse(log string) []ports.Diagnostic {
+	lines := unwrapLogLines(log)
+	files := &fileStack{}
+	var diagnostics []ports.Diagnostic
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.HasPrefix(line, "!  ==>"):
+			// "Fatal error occurred" trailer repeats the error already reported
+
+		case strings.HasPrefix(line, "! "):
+			d, consumed := parseErrorBlock(lines, i, strings.TrimPrefix(line, "! "))
+			d.File = files.current()
+			diagnostics = append(diagnostics, d)
+			i += consumed
+
+		case fileLineErrorPattern.MatchString(line):
+			m := fileLineErrorPattern.FindStringSubmatch(line)
+			d, consumed := parseErrorBlock(lines, i, m[3])
+			d.File = cleanLogPath(m[1])
+			d.Line, _ = strconv.Atoi(m[2])
+			diagnostics = append(diagnostics, d)
+			i += consumed
+
+		case strings.HasPrefix(line, "Overfull \\") || strings.HasPrefix(line, "Underfull \\"):
+			d, consumed := parseBoxBlock(lines, i)
+			d.File = files.current()
+			diagnostics = append(diagnostics, d)
+			i += consumed
+
+		case warningPattern.MatchString(line):
+			d, consumed := parseWarningBlock(lines, i)
+			d.File = files.current()
+			diagnostics = append(diagnostics, d)
+			i += consumed
+
+		case noFilePattern.MatchString(line):
+			diagnostics = append(diagnostics, ports.Diagnostic{
+				Kind:     ports.DiagnosticMissingFile,
+				Severity: ports.SeverityWarning,
+				File:     files.current(),
+				Message:  line,
+			})
+
+		default:
+			files.scan(line)
+		}
+	}
+
+	return diagnostics
+}
+
+// parseErrorBlock reads an error message and its context up to the "l.N" marker
+// Returns the diagnostic (without File) and the number of extra lines consumed
+func parseErrorBlock(lines []string, start int, message string) (ports.Diagnostic, int) {
+	d := ports.Diagnostic{
+		Kind:     ports.DiagnosticError,
+		Severity: ports.SeverityError,
+		Message:  strings.TrimSpace(message),
+	}
+	if missingFilePattern.MatchString(message) {
+		d.Kind = ports.DiagnosticMissingFile
+	}
+
+	var context []string
+	consumed := 0
+	for j := start + 1; j < len(lines) && j <= start+maxErrorContextLines; j++ {
+		next := lines[j]
+		if strings.HasPrefix(next, "! ") || fileLineErrorPattern.MatchString(next) {
+			break
+		}
+		consumed++
+		if m := errorLinePattern.FindStringSubmatch(next); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			context = append(context, next)
+			// TeX prints the rest of the offending source line on the following line
+			if j+1 < len(lines) && strings.TrimSpace(lines[j+1]) != "" {
+				context = append(context, lines[j+1])
+				consumed++
+			}
+			break
+		}
+		if strings.TrimSpace(next) != "" {
+			context = append(context, next)
+		}
+	}
+
+	d.Context = strings.Join(context, "\n")
+	return d, consumed
+}
+
+// parseBoxBlock reads an overfull/underfull box warning and the box content that follows
+func parseBoxBlock(lines []string, start int) (ports.Diagnostic, int) {
+	line := lines[start]
+	d := ports.Diagnostic{
+		Kind:     ports.DiagnosticUnderfullBox,
+		Severity: ports.SeverityWarning,
+		Message:  strings.TrimSpace(line),
+	}
+	if strings.HasPrefix(line, "Overfull") {
+		d.Kind = ports.DiagnosticOverfullBox
+	}
+	if m := boxLinePattern.FindStringSubmatch(line); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+	}
+
+	// Box content is printed until the next blank line and may contain unbalanced
+	// parentheses, so it is consumed here rather than fed to the file tracker
+	var context []string
+	consumed := 0
+	for j := start + 1; j < len(lines) && strings.TrimSpace(lines[j]) != ""; j++ {
+		context = append(context, lines[j])
+		consumed++
+	}
+
+	d.Context = strings.Join(context, "\n")
+	return d, consumed
+}
+
+// parseWarningBlock reads a LaTeX, package or class warning including its "(name)" continuation lines
+func parseWarningBlock(lines []string, start int) (ports.Diagnostic, int) {
+	m := warningPattern.FindStringSubmatch(lines[start])
+	name := m[2]
+	if name == "" {
+		name = m[1]
+	}
+
+	message := []string{strings.TrimSpace(m[3])}
+	consumed := 0
+	prefix := "(" + name + ")"
+	for j := start + 1; j < len(lines) && strings.HasPrefix(lines[j], prefix); j++ {
+		message = append(message, strings.TrimSpace(strings.TrimPrefix(lines[j], prefix)))
+		consumed++
+	}
+
+	d := ports.Diagnostic{
+		Kind:     ports.DiagnosticWarning,
+		Severity: ports.SeverityWarning,
+		Message:  strings.Join(message, " "),
+		Context:  strings.Join(lines[start:start+consumed+1], "\n"),
+	}
+	switch {
+	case citationPattern.MatchString(d.Message):
+		d.Kind = ports.DiagnosticUndefinedCitation
+	case referencePattern.MatchString(d.Message):
+		d.Kind = ports.DiagnosticUndefinedReference
+	case missingFilePattern.MatchString(d.Message):
+		d.Kind = ports.DiagnosticMissingFile
+	}
+	if lm := inputLinePattern.FindStringSubmatch(d.Message); lm != nil {
+		d.Line, _ = strconv.Atoi(lm[1])
+	}
+
+	return d, consumed
+}
+
+// unwrapLogLines splits the log into lines, re-joining lines TeX hard-wrapped at max_print_line
+func unwrapLogLines(log string) []string {
+	raw := strings.Split(strings.ReplaceAll(log, "\r\n", "\n"), "\n")
+	lines := make([]string, 0, len(raw))
+
+	var current strings.Builder
+	for _, line := range raw {
+		current.WriteString(line)
+		if len(line) == maxPrintLine || utf8.RuneCountInString(line) == maxPrintLine {
+			continue
+		}
+		lines = append(lines, current.String())
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		lines = append(lines, current.String())
+	}
+
+	return lines
+}
+
+// cleanLogPath normalizes a path as printed by the engine
+func cleanLogPath(path string) string {
+	return strings.TrimPrefix(path, "./")
+}
+
+// fileStack tracks which input file the engine is reading from the "(file ... )" nesting
+type fileStack struct {
+	entries []string
+}
+
+// scan updates the stack with every opening and closing parenthesis on the line
+func (fs *fileStack) scan(line string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '(':
+			end := i + 1
+			for end < len(line) && !strings.ContainsRune(" \t()", rune(line[end])) {
+				end++
+			}
+			token := line[i+1 : end]
+			if filePathPattern.MatchString(token) {
+				fs.entries = append(fs.entries, cleanLogPath(token))
+			} else {
+				// Non-file group: keep parentheses balanced
+				fs.entries = append(fs.entries, "")
+			}
+			i = end - 1
+		case ')':
+			if len(fs.entries) > 0 {
+				fs.entries = fs.entries[:len(fs.entries)-1]
+			}
+		}
+	}
+}
+
+// current returns the innermost file being read, or empty if unknown
+func (fs *fileStack) current() string {
+	for i := len(fs.entries) - 1; i >= 0; i-- {
+		if fs.entries[i] != "" {
+			return fs.entries[i]
+		}
+	}
+	return ""
+}
+
+// readLogDiagnostics parses the engine log at logPath; a missing log yields no diagnostics
+func readLogDiagnostics(ctx context.Context, fileSystem ports.FileSystem, logPath string) []ports.Diagnostic {
+	data, err := fileSystem.ReadFile(ctx, logPath)
+	if err != nil {
+		return nil
+	}
+	return NewLogParser().Parse(string(data))
+}
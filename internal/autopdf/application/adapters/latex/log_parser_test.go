@@ -0,0 +1,154 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"strings"
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleLog = `This is pdfTeX, Version 3.141592653-2.6-1.40.25 (TeX Live 2023) (preloaded format=pdflatex 2023.10.1)  16 OCT 2025 10:00
+entering extended mode
+**/tmp/autopdf/report.tex
+(/tmp/autopdf/report.tex
+LaTeX2e <2023-06-01> patch level 1
+(/usr/share/texlive/texmf-dist/tex/latex/base/article.cls
+Document Class: article 2023/05/17 v1.4n Standard LaTeX document class
+(/usr/share/texlive/texmf-dist/tex/latex/base/size10.clo
+File: size10.clo 2023/05/17 v1.4n Standard LaTeX file (size option)
+))
+No file report.aux.
+(./chapter.tex
+! Undefined control sequence.
+l.7 \foo
+        {bar}
+The control sequence at the end of the top line
+of your error message was never \def'ed.
+
+Overfull \hbox (12.34pt too wide) in paragraph at lines 10--12
+[]\OT1/cmr/m/n/10 A very long word (unbalanced
+ []
+
+)
+LaTeX Warning: Reference ` + "`fig:flow'" + ` on page 1 undefined on input line 21.
+
+LaTeX Warning: Citation ` + "`knuth84'" + ` on page 1 undefined on input line 23.
+
+Package hyperref Warning: Token not allowed in a PDF string (Unicode):
+(hyperref)                removing ` + "`math shift'" + ` on input line 30.
+
+Underfull \vbox (badness 10000) has occurred while \output is active []
+
+! LaTeX Error: File ` + "`missing.sty'" + ` not found.
+
+Type X to quit or <RETURN> to proceed,
+or enter new name. (Default extension: sty)
+
+Enter file name:
+! Emergency stop.
+<read *>
+
+l.40 \usepackage
+                {missing}^^M
+!  ==> Fatal error occurred, no output PDF file produced!
+`
+
+func TestLogParser_Parse(t *testing.T) {
+	diagnostics := NewLogParser().Parse(sampleLog)
+
+	kinds := make([]ports.DiagnosticKind, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		kinds = append(kinds, d.Kind)
+	}
+	require.Equal(t, []ports.DiagnosticKind{
+		ports.DiagnosticMissingFile,
+		ports.DiagnosticError,
+		ports.DiagnosticOverfullBox,
+		ports.DiagnosticUndefinedReference,
+		ports.DiagnosticUndefinedCitation,
+		ports.DiagnosticWarning,
+		ports.DiagnosticUnderfullBox,
+		ports.DiagnosticMissingFile,
+		ports.DiagnosticError,
+	}, kinds)
+
+	t.Run("no file is a warning", func(t *testing.T) {
+		assert.Equal(t, ports.SeverityWarning, diagnostics[0].Severity)
+		assert.Equal(t, "No file report.aux.", diagnostics[0].Message)
+	})
+
+	t.Run("error carries file, line and context", func(t *testing.T) {
+		d := diagnostics[1]
+		assert.True(t, d.IsError())
+		assert.Equal(t, "chapter.tex", d.File)
+		assert.Equal(t, 7, d.Line)
+		assert.Equal(t, "Undefined control sequence.", d.Message)
+		assert.Contains(t, d.Context, `l.7 \foo`)
+		assert.Contains(t, d.Context, "{bar}")
+		assert.Equal(t, "chapter.tex:7: Undefined control sequence.", d.String())
+	})
+
+	t.Run("box content does not confuse file tracking", func(t *testing.T) {
+		d := diagnostics[2]
+		assert.Equal(t, "chapter.tex", d.File)
+		assert.Equal(t, 10, d.Line)
+		assert.Contains(t, d.Context, "A very long word")
+	})
+
+	t.Run("warnings after the included file closes", func(t *testing.T) {
+		assert.Equal(t, "/tmp/autopdf/report.tex", diagnostics[3].File)
+		assert.Equal(t, 21, diagnostics[3].Line)
+		assert.Equal(t, 23, diagnostics[4].Line)
+	})
+
+	t.Run("package warning continuation lines are joined", func(t *testing.T) {
+		d := diagnostics[5]
+		assert.Equal(t, "Token not allowed in a PDF string (Unicode): removing `math shift' on input line 30.", d.Message)
+		assert.Equal(t, 30, d.Line)
+	})
+
+	t.Run("missing file error", func(t *testing.T) {
+		d := diagnostics[7]
+		assert.True(t, d.IsError())
+		assert.Contains(t, d.Message, "missing.sty")
+	})
+
+	t.Run("fatal error trailer is skipped", func(t *testing.T) {
+		d := diagnostics[8]
+		assert.Equal(t, "Emergency stop.", d.Message)
+		assert.Equal(t, 40, d.Line)
+	})
+}
+
+func TestLogParser_Parse_FileLineError(t *testing.T) {
+	log := "(./main.tex\n./main.tex:12: Undefined control sequence.\nl.12 \\bad\n          \n)\n"
+
+	diagnostics := NewLogParser().Parse(log)
+
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, ports.DiagnosticError, diagnostics[0].Kind)
+	assert.Equal(t, "main.tex", diagnostics[0].File)
+	assert.Equal(t, 12, diagnostics[0].Line)
+	assert.Equal(t, "Undefined control sequence.", diagnostics[0].Message)
+}
+
+func TestLogParser_Parse_UnwrapsLongLines(t *testing.T) {
+	message := "LaTeX Warning: Reference `a-very-long-label-name-that-forces-the-engine-to-wrap' on page 3 undefined on input line 99."
+	wrapped := message[:maxPrintLine] + "\n" + message[maxPrintLine:] + "\n"
+
+	diagnostics := NewLogParser().Parse(wrapped)
+
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, ports.DiagnosticUndefinedReference, diagnostics[0].Kind)
+	assert.Equal(t, 99, diagnostics[0].Line)
+	assert.True(t, strings.HasSuffix(diagnostics[0].Message, "input line 99."))
+}
+
+func TestLogParser_Parse_Empty(t *testing.T) {
+	assert.Empty(t, NewLogParser().Parse(""))
+}
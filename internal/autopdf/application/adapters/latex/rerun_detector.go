@@ -0,0 +1,85 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+)
+
+// rerunPattern matches engine and package messages asking for another pass
+var rerunPattern = regexp.MustCompile(`Rerun to get .* right|Label\(s\) may have changed|Please \(re\)run|has changed\. Rerun`)
+
+// auxExtensions are the files whose content feeds into the next pass
+var auxExtensions = []string{".aux", ".toc", ".lof", ".lot"}
+
+// rerunDetector decides whether another engine pass is needed in auto passes mode
+// It compares the log messages and the checksums of auxiliary files between passes
+type rerunDetector struct {
+	fileSystem ports.FileSystem
+	basePath   string            // Output directory joined with the job name, without extension
+	checksums  map[string]string // Extension -> checksum after the last snapshot ("" if absent)
+}
+
+// newRerunDetector creates a detector for the job at basePath
+func newRerunDetector(fileSystem ports.FileSystem, basePath string) *rerunDetector {
+	return &rerunDetector{
+		fileSystem: fileSystem,
+		basePath:   basePath,
+		checksums:  map[string]string{},
+	}
+}
+
+// Snapshot records the current auxiliary file checksums; call it before the first pass
+func (rd *rerunDetector) Snapshot(ctx context.Context) {
+	rd.checksums = rd.currentChecksums(ctx)
+}
+
+// NeedsRerun reports whether the pass that just finished left the document unconverged
+// and returns the reason. It also takes a new snapshot for the next comparison.
+func (rd *rerunDetector) NeedsRerun(ctx context.Context, logPath string) (bool, string) {
+	previous := rd.checksums
+	rd.checksums = rd.currentChecksums(ctx)
+
+	if data, err := rd.fileSystem.ReadFile(ctx, logPath); err == nil {
+		if match := rerunPattern.Find(data); match != nil {
+			return true, string(match)
+		}
+	}
+
+	for _, ext := range auxExtensions {
+		before, after := previous[ext], rd.checksums[ext]
+		if before == after {
+			continue
+		}
+		// A freshly written .aux is covered by LaTeX's own label check above;
+		// anything else appearing or changing is only picked up by the next pass
+		if ext == ".aux" && before == "" {
+			continue
+		}
+		if after != "" {
+			return true, ext + " file changed"
+		}
+	}
+
+	return false, ""
+}
+
+// currentChecksums hashes every auxiliary file that exists for the job
+func (rd *rerunDetector) currentChecksums(ctx context.Context) map[string]string {
+	checksums := make(map[string]string, len(auxExtensions))
+	for _, ext := range auxExtensions {
+		data, err := rd.fileSystem.ReadFile(ctx, rd.basePath+ext)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		checksums[ext] = hex.EncodeToString(sum[:])
+	}
+	return checksums
+}
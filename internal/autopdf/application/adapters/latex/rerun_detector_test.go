@@ -0,0 +1,168 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// passOutput is what a simulated engine pass leaves behind
+type passOutput struct {
+	log   string
+	aux   string
+	toc   string
+	extra map[string]string // Extension -> content of other files written by the pass
+}
+
+// scriptedEngine simulates engine passes by writing the PDF, log and aux files for each run
+// Commands other than the engine itself are recorded in tools
+type scriptedEngine struct {
+	basePath string
+	passes   []passOutput
+	runs     int
+	tools    []ports.Command
+	toolErr  error
+}
+
+func (e *scriptedEngine) Execute(ctx context.Context, cmd ports.Command) (ports.CommandResult, error) {
+	if cmd.Executable == "which" {
+		return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+	}
+	if !strings.HasSuffix(cmd.Executable, "latex") {
+		e.tools = append(e.tools, cmd)
+		if e.toolErr != nil {
+			return ports.NewCommandResult("", "I couldn't open database file refs.bib", 2, time.Millisecond), e.toolErr
+		}
+		return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+	}
+
+	out := e.passes[len(e.passes)-1]
+	if e.runs < len(e.passes) {
+		out = e.passes[e.runs]
+	}
+	e.runs++
+
+	_ = os.WriteFile(e.basePath+".pdf", []byte("%PDF-1.5"), 0644)
+	_ = os.WriteFile(e.basePath+".log", []byte(out.log), 0644)
+	_ = os.WriteFile(e.basePath+".aux", []byte(out.aux), 0644)
+	if out.toc != "" {
+		_ = os.WriteFile(e.basePath+".toc", []byte(out.toc), 0644)
+	}
+	for ext, content := range out.extra {
+		_ = os.WriteFile(e.basePath+ext, []byte(content), 0644)
+	}
+	return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+}
+
+func compileAuto(t *testing.T, maxPasses int, passes ...passOutput) (ports.CompileResult, *scriptedEngine) {
+	t.Helper()
+	dir := t.TempDir()
+	engine := &scriptedEngine{basePath: filepath.Join(dir, "report"), passes: passes}
+	adapter := NewLaTeXCompilerAdapterWithWorkingDir(nil, infraadapters.NewOSFileSystem(), engine, dir)
+
+	opts := ports.NewCompileOptions("pdflatex", filepath.Join(dir, "report.pdf"), dir).
+		WithJobName("report").
+		WithPasses(maxPasses).
+		WithAutoPasses(true)
+
+	result, err := adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+	require.NoError(t, err)
+	return result, engine
+}
+
+func TestLaTeXCompilerAdapter_AutoPasses(t *testing.T) {
+	t.Run("stops after one pass when nothing changes", func(t *testing.T) {
+		result, engine := compileAuto(t, 5, passOutput{log: "Output written on report.pdf", aux: `\relax`})
+
+		assert.Equal(t, 1, engine.runs)
+		assert.Equal(t, 1, result.Passes)
+	})
+
+	t.Run("reruns while the log asks for it", func(t *testing.T) {
+		result, engine := compileAuto(t, 5,
+			passOutput{log: "LaTeX Warning: Label(s) may have changed. Rerun to get cross-references right.", aux: `\newlabel{a}{{1}{1}}`},
+			passOutput{log: "Output written on report.pdf", aux: `\newlabel{a}{{1}{1}}`},
+		)
+
+		assert.Equal(t, 2, engine.runs)
+		assert.Equal(t, 2, result.Passes)
+	})
+
+	t.Run("reruns when the table of contents appears or changes", func(t *testing.T) {
+		_, engine := compileAuto(t, 5,
+			passOutput{aux: `\relax`, toc: `\contentsline {section}{Intro}{1}`},
+			passOutput{aux: `\relax`, toc: `\contentsline {section}{Intro}{2}`},
+			passOutput{aux: `\relax`, toc: `\contentsline {section}{Intro}{2}`},
+		)
+
+		assert.Equal(t, 3, engine.runs)
+	})
+
+	t.Run("a freshly written aux file alone does not force a rerun", func(t *testing.T) {
+		_, engine := compileAuto(t, 5,
+			passOutput{aux: `\gdef \@abspage@last{1}`},
+		)
+
+		assert.Equal(t, 1, engine.runs)
+	})
+
+	t.Run("never exceeds the cap", func(t *testing.T) {
+		result, engine := compileAuto(t, 3, passOutput{log: "Rerun to get cross-references right."})
+
+		assert.Equal(t, 3, engine.runs)
+		assert.Equal(t, 3, result.Passes)
+	})
+
+	t.Run("unset passes cap the reruns at the default", func(t *testing.T) {
+		result, engine := compileAuto(t, 0, passOutput{log: "Rerun to get cross-references right."})
+
+		assert.Equal(t, ports.DefaultAutoPasses, engine.runs)
+		assert.Equal(t, ports.DefaultAutoPasses, result.Passes)
+	})
+}
+
+func TestRerunDetector_AuxChecksumChange(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "report")
+	detector := newRerunDetector(infraadapters.NewOSFileSystem(), basePath)
+
+	// Stale aux from a previous build
+	require.NoError(t, os.WriteFile(basePath+".aux", []byte(`\gdef \@abspage@last{1}`), 0644))
+	detector.Snapshot(ctx)
+
+	require.NoError(t, os.WriteFile(basePath+".aux", []byte(`\gdef \@abspage@last{2}`), 0644))
+	rerun, reason := detector.NeedsRerun(ctx, basePath+".log")
+	assert.True(t, rerun)
+	assert.Equal(t, ".aux file changed", reason)
+
+	rerun, _ = detector.NeedsRerun(ctx, basePath+".log")
+	assert.False(t, rerun)
+}
+
+func TestLaTeXCompilerAdapter_FixedPasses(t *testing.T) {
+	dir := t.TempDir()
+	engine := &scriptedEngine{basePath: filepath.Join(dir, "report"), passes: []passOutput{{aux: `\relax`}}}
+	adapter := NewLaTeXCompilerAdapterWithWorkingDir(nil, infraadapters.NewOSFileSystem(), engine, dir)
+
+	opts := ports.NewCompileOptions("pdflatex", filepath.Join(dir, "report.pdf"), dir).
+		WithJobName("report").
+		WithPasses(3)
+
+	result, err := adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, engine.runs)
+	assert.Equal(t, 3, result.Passes)
+}
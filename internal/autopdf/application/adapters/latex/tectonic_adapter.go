@@ -0,0 +1,207 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	application "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+)
+
+// TectonicCompilerAdapter implements LaTeXCompiler with the tectonic engine
+// Tectonic reruns itself until cross-references converge, runs bibtex/biber on its own
+// and fetches packages from its support bundle into a cache, so no TeX installation is needed
+type TectonicCompilerAdapter struct {
+	fileSystem application.FileSystem
+	executor   application.CommandExecutor
+	settings   config.Tectonic
+}
+
+// NewTectonicCompilerAdapter creates a new tectonic compiler adapter
+func NewTectonicCompilerAdapter(
+	cfg *config.Config,
+	fileSystem application.FileSystem,
+	executor application.CommandExecutor,
+) *TectonicCompilerAdapter {
+	var settings config.Tectonic
+	if cfg != nil {
+		settings = cfg.Tectonic
+	}
+	return &TectonicCompilerAdapter{
+		fileSystem: fileSystem,
+		executor:   executor,
+		settings:   settings,
+	}
+}
+
+// Compile compiles LaTeX content to PDF with tectonic
+// Passes is only honoured when AutoPasses is off and it asks for more than one pass;
+// otherwise tectonic decides how often to rerun
+func (tca *TectonicCompilerAdapter) Compile(ctx context.Context, content string, opts application.CompileOptions) (application.CompileResult, error) {
+	if content == "" {
+		return application.CompileResult{}, errors.New("no LaTeX content provided")
+	}
+	if opts.Engine != application.EngineTectonic {
+		return application.CompileResult{}, fmt.Errorf("%w %q for this compiler: only %s is run by TectonicCompilerAdapter",
+			application.ErrUnsupportedEngine, opts.Engine, application.EngineTectonic)
+	}
+	if err := opts.Toolchain.Validate(); err != nil {
+		return application.CompileResult{}, err
+	}
+
+	pdfPath := opts.OutputPath
+	if pdfPath == "" {
+		pdfPath = filepath.Join(opts.WorkingDir, opts.JobName+".pdf")
+	}
+	// --outdir must exist and is resolved by tectonic, not relative to the working directory
+	outputDir, err := filepath.Abs(filepath.Dir(pdfPath))
+	if err != nil {
+		return application.CompileResult{}, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+	workingDir := opts.WorkingDir
+	if workingDir == "" {
+		workingDir = outputDir
+	}
+	for _, dir := range []string{workingDir, outputDir} {
+		if err := tca.fileSystem.MkdirAll(ctx, dir, 0755); err != nil {
+			return application.CompileResult{}, fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	// Verify that the engine is installed
+	if _, err := tca.executor.Execute(ctx, application.NewCommand("which", []string{application.EngineTectonic}, "")); err != nil {
+		return application.CompileResult{}, fmt.Errorf("LaTeX engine not found: %s", application.EngineTectonic)
+	}
+
+	// Tectonic has no -jobname: its outputs are named after the source file
+	sourceFile := sourceFileName(opts.JobName)
+	concreteFile := filepath.Join(workingDir, sourceFile)
+	if err := tca.fileSystem.WriteFile(ctx, concreteFile, []byte(content), 0644); err != nil {
+		return application.CompileResult{}, err
+	}
+	if !opts.Debug {
+		defer func() {
+			_ = tca.fileSystem.Remove(ctx, concreteFile)
+		}()
+	}
+
+	stem := strings.TrimSuffix(sourceFile, ".tex")
+	producedPDF := filepath.Join(outputDir, stem+".pdf")
+	logPath := filepath.Join(outputDir, stem+".log")
+
+	cmd := application.NewCommand(application.EngineTectonic, tca.args(opts, outputDir, sourceFile), workingDir).
+		WithTimeout(5 * time.Minute).
+		WithEnv(tca.env()).
+		WithSecurity(opts.Security)
+	cmdStr := formatArgv(cmd)
+
+	result, err := tca.executor.Execute(ctx, cmd)
+	if err == nil && !result.Success {
+		err = fmt.Errorf("tectonic exited with code %d", result.ExitCode)
+	}
+	if err != nil {
+		if _, statErr := tca.fileSystem.Stat(ctx, producedPDF); statErr != nil {
+			diagnostics := readLogDiagnostics(ctx, tca.fileSystem, logPath)
+			return application.CompileResult{Diagnostics: diagnostics},
+				compilationError(diagnostics, 1, 1, cmdStr, workingDir, result, err)
+		}
+		// PDF was created, so continue
+	}
+
+	if producedPDF != pdfPath {
+		if err := tca.move(ctx, producedPDF, pdfPath); err != nil {
+			return application.CompileResult{}, fmt.Errorf("failed to move PDF to %s: %w", pdfPath, err)
+		}
+	}
+
+	info, err := tca.fileSystem.Stat(ctx, pdfPath)
+	if err != nil {
+		return application.CompileResult{}, fmt.Errorf("PDF file was not created at %s: %w", pdfPath, err)
+	}
+	if info.Size() == 0 {
+		return application.CompileResult{}, fmt.Errorf("PDF file was created but is empty at %s", pdfPath)
+	}
+
+	return application.CompileResult{
+		PDFPath:     pdfPath,
+		Diagnostics: readLogDiagnostics(ctx, tca.fileSystem, logPath),
+		Passes:      tca.passes(opts),
+	}, nil
+}
+
+// args builds tectonic's argv
+// Intermediate files stay in memory unless debugging; the log is always kept for diagnostics
+func (tca *TectonicCompilerAdapter) args(opts application.CompileOptions, outputDir, sourceFile string) []string {
+	args := []string{
+		"--outdir", outputDir,
+		"--keep-logs",
+		"--chatter", "minimal",
+	}
+	if passes := tca.passes(opts); passes > 0 {
+		args = append(args, "--reruns", strconv.Itoa(passes-1))
+	}
+	if opts.Debug {
+		args = append(args, "--keep-intermediates")
+	}
+	if tca.settings.Bundle != "" {
+		args = append(args, "--bundle", tca.settings.Bundle)
+	}
+	if tca.settings.OnlyCached {
+		args = append(args, "--only-cached")
+	}
+	for _, dir := range opts.SearchDirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		args = append(args, "-Z", "search-path="+dir)
+	}
+	// Untrusted mode disables shell escape and every other feature unsafe for foreign input
+	if opts.Security.ShellEscape != "" {
+		args = append(args, "--untrusted")
+	}
+	return append(args, sourceFile)
+}
+
+// passes returns the fixed number of engine passes, or 0 when tectonic decides
+func (tca *TectonicCompilerAdapter) passes(opts application.CompileOptions) int {
+	if opts.AutoPasses || opts.Passes <= 1 {
+		return 0
+	}
+	return opts.Passes
+}
+
+// env points tectonic at the bundle cache
+// The directory is always explicit: a sandbox moves HOME, which would otherwise
+// make every build download the bundle again
+func (tca *TectonicCompilerAdapter) env() []string {
+	cacheDir := tca.settings.CacheDir
+	if cacheDir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return nil
+		}
+		cacheDir = filepath.Join(userCache, "Tectonic")
+	}
+	return append(os.Environ(), "TECTONIC_CACHE_DIR="+cacheDir)
+}
+
+// move renames a file through the FileSystem port
+func (tca *TectonicCompilerAdapter) move(ctx context.Context, src, dst string) error {
+	data, err := tca.fileSystem.ReadFile(ctx, src)
+	if err != nil {
+		return err
+	}
+	if err := tca.fileSystem.WriteFile(ctx, dst, data, 0644); err != nil {
+		return err
+	}
+	return tca.fileSystem.Remove(ctx, src)
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTectonic records tectonic runs and writes <source stem>.pdf and .log into --outdir
+type fakeTectonic struct {
+	commands []ports.Command
+	log      string
+	exitCode int
+}
+
+func (f *fakeTectonic) Execute(ctx context.Context, cmd ports.Command) (ports.CommandResult, error) {
+	if cmd.Executable == "which" {
+		return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+	}
+	f.commands = append(f.commands, cmd)
+
+	var outdir string
+	for i, arg := range cmd.Args {
+		if arg == "--outdir" {
+			outdir = cmd.Args[i+1]
+		}
+	}
+	stem := strings.TrimSuffix(cmd.Args[len(cmd.Args)-1], ".tex")
+	_ = os.WriteFile(filepath.Join(outdir, stem+".log"), []byte(f.log), 0644)
+	if f.exitCode != 0 {
+		return ports.NewCommandResult("", "error: halted on potentially-recoverable error", f.exitCode, time.Millisecond),
+			errors.New("exit status 1")
+	}
+	_ = os.WriteFile(filepath.Join(outdir, stem+".pdf"), []byte("%PDF-1.5"), 0644)
+	return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+}
+
+func TestTectonicCompilerAdapter_Compile(t *testing.T) {
+	workingDir := t.TempDir()
+	outputDir := t.TempDir()
+	engine := &fakeTectonic{}
+	cfg := &config.Config{Tectonic: config.Tectonic{CacheDir: "/var/cache/tectonic", Bundle: "/srv/bundle.tar", OnlyCached: true}}
+	adapter := NewTectonicCompilerAdapter(cfg, infraadapters.NewOSFileSystem(), engine)
+
+	output := filepath.Join(outputDir, "March statement.pdf")
+	opts := ports.NewCompileOptions(ports.EngineTectonic, output, workingDir).WithJobName("March statement")
+
+	result, err := adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+	require.NoError(t, err)
+	assert.Equal(t, output, result.PDFPath)
+	assert.FileExists(t, output)
+	assert.NoFileExists(t, filepath.Join(outputDir, "March_statement.pdf"), "the PDF is renamed to the requested output")
+	assert.Zero(t, result.Passes, "tectonic decides how often to rerun")
+
+	require.Len(t, engine.commands, 1)
+	cmd := engine.commands[0]
+	assert.Equal(t, "tectonic", cmd.Executable)
+	assert.Equal(t, workingDir, cmd.Dir)
+	assert.Equal(t, []string{
+		"--outdir", outputDir,
+		"--keep-logs",
+		"--chatter", "minimal",
+		"--bundle", "/srv/bundle.tar",
+		"--only-cached",
+		"March_statement.tex",
+	}, cmd.Args)
+	assert.Contains(t, cmd.Env, "TECTONIC_CACHE_DIR=/var/cache/tectonic")
+
+	// The source file is removed outside debug mode
+	assert.NoFileExists(t, filepath.Join(workingDir, "March_statement.tex"))
+}
+
+func TestTectonicCompilerAdapter_Options(t *testing.T) {
+	dir := t.TempDir()
+	engine := &fakeTectonic{}
+	adapter := NewTectonicCompilerAdapter(nil, infraadapters.NewOSFileSystem(), engine)
+
+	opts := ports.NewCompileOptions(ports.EngineTectonic, filepath.Join(dir, "doc.pdf"), dir).
+		WithJobName("doc").
+		WithPasses(3).
+		WithDebug(true).
+		WithSecurity(ports.NewSandboxPolicy())
+
+	result, err := adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Passes)
+
+	args := engine.commands[0].Args
+	assert.Contains(t, strings.Join(args, " "), "--reruns 2")
+	assert.Contains(t, args, "--keep-intermediates")
+	assert.Contains(t, args, "--untrusted")
+	assert.Equal(t, ports.NewSandboxPolicy(), engine.commands[0].Security)
+	assert.FileExists(t, filepath.Join(dir, "doc.tex"), "debug builds keep the source")
+
+	// Auto passes leave reruns to tectonic
+	_, err = adapter.Compile(context.Background(), `\documentclass{article}`, opts.WithAutoPasses(true))
+	require.NoError(t, err)
+	assert.NotContains(t, engine.commands[1].Args, "--reruns")
+}
+
+func TestTectonicCompilerAdapter_Failure(t *testing.T) {
+	dir := t.TempDir()
+	engine := &fakeTectonic{
+		exitCode: 1,
+		log:      "./doc.tex:3: Undefined control sequence.\nl.3 \\foo\n",
+	}
+	adapter := NewTectonicCompilerAdapter(nil, infraadapters.NewOSFileSystem(), engine)
+
+	opts := ports.NewCompileOptions(ports.EngineTectonic, filepath.Join(dir, "doc.pdf"), dir).WithJobName("doc")
+	result, err := adapter.Compile(context.Background(), `\documentclass{article}\foo`, opts)
+
+	require.Error(t, err)
+	var compileErr *ports.CompilationError
+	require.ErrorAs(t, err, &compileErr)
+	require.NotEmpty(t, result.Diagnostics)
+	assert.Equal(t, 3, result.Diagnostics[0].Line)
+}
+
+func TestCompilers_RejectUnknownEngines(t *testing.T) {
+	dir := t.TempDir()
+	fileSystem := infraadapters.NewOSFileSystem()
+	engine := &recordingEngine{}
+	compilers := map[string]ports.LaTeXCompiler{
+		"latex":    NewLaTeXCompilerAdapterWithWorkingDir(nil, fileSystem, engine, dir),
+		"latexmk":  NewLatexmkCompilerAdapter(engine, fileSystem),
+		"tectonic": NewTectonicCompilerAdapter(nil, fileSystem, engine),
+	}
+
+	for name, compiler := range compilers {
+		for _, unknown := range []string{"", "latex", "context", "xetex"} {
+			opts := ports.NewCompileOptions(unknown, filepath.Join(dir, "doc.pdf"), dir)
+			_, err := compiler.Compile(context.Background(), `\documentclass{article}`, opts)
+			assert.ErrorIs(t, err, ports.ErrUnsupportedEngine, "%s accepted %q", name, unknown)
+		}
+	}
+
+	// Each adapter only runs its own engines
+	others := map[string][]string{
+		"latex":    {ports.EngineTectonic, ports.EngineTypst},
+		"latexmk":  {ports.EngineTectonic, ports.EngineTypst},
+		"tectonic": {ports.EngineXeLaTeX, ports.EngineTypst},
+	}
+	for name, engineNames := range others {
+		for _, engineName := range engineNames {
+			opts := ports.NewCompileOptions(engineName, filepath.Join(dir, "doc.pdf"), dir)
+			_, err := compilers[name].Compile(context.Background(), `\documentclass{article}`, opts)
+			assert.ErrorIs(t, err, ports.ErrUnsupportedEngine, "%s accepted %q", name, engineName)
+		}
+	}
+	assert.Empty(t, engine.commands, "no engine ran")
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+)
+
+// toolchainPasses is the minimum number of engine passes once a tool ran:
+// one to read the tool's output and one to resolve the references it introduced
+const toolchainPasses = 3
+
+// toolchainRunner runs bibtex/biber, makeindex and makeglossaries between engine passes
+// Needs are detected from the .aux, .bcf, .idx and .glo files written by the first pass
+type toolchainRunner struct {
+	fileSystem ports.FileSystem
+	executor   ports.CommandExecutor
+}
+
+// newToolchainRunner creates a new toolchain runner
+func newToolchainRunner(fileSystem ports.FileSystem, executor ports.CommandExecutor) *toolchainRunner {
+	return &toolchainRunner{
+		fileSystem: fileSystem,
+		executor:   executor,
+	}
+}
+
+// Run executes every tool the document needs for the job in outputDir
+// sourceDirs are where the engine resolves \bibliography and .bst files from
+// Tools run under the same security policy as the engine
+// Returns whether any tool ran, and warnings for tools that failed
+func (tr *toolchainRunner) Run(ctx context.Context, toolchain ports.Toolchain, security ports.SecurityPolicy, outputDir string, sourceDirs []string, jobName string) (bool, []ports.Diagnostic) {
+	basePath := filepath.Join(outputDir, jobName)
+
+	var commands []ports.Command
+	if tool := tr.bibliographyTool(ctx, toolchain.Bibliography, basePath); tool != "" {
+		commands = append(commands, ports.NewCommand(tool, []string{jobName}, outputDir))
+	}
+	if toolchain.Index != ports.ToolNone && tr.hasContent(ctx, basePath+".idx") {
+		commands = append(commands, ports.NewCommand(ports.ToolMakeindex, []string{jobName + ".idx"}, outputDir))
+	}
+	if toolchain.Glossary != ports.ToolNone && tr.hasContent(ctx, basePath+".glo") {
+		commands = append(commands, ports.NewCommand(ports.ToolMakeglossaries, []string{jobName}, outputDir))
+	}
+
+	var diagnostics []ports.Diagnostic
+	for _, cmd := range commands {
+		cmd = cmd.WithTimeout(2 * time.Minute).WithEnv(searchPathEnv(outputDir, sourceDirs)).WithSecurity(security)
+		result, err := tr.executor.Execute(ctx, cmd)
+		if err != nil {
+			diagnostics = append(diagnostics, toolFailure(cmd, result, err))
+		}
+	}
+
+	return len(commands) > 0, diagnostics
+}
+
+// bibliographyTool picks bibtex or biber from the selection and the files of the first pass
+func (tr *toolchainRunner) bibliographyTool(ctx context.Context, selection, basePath string) string {
+	usesBiblatex := tr.hasContent(ctx, basePath+".bcf")
+	usesBibtex := tr.auxContains(ctx, basePath+".aux", `\bibdata`)
+
+	switch selection {
+	case ports.ToolNone:
+		return ""
+	case ports.ToolBiber:
+		if usesBiblatex {
+			return ports.ToolBiber
+		}
+	case ports.ToolBibtex:
+		if usesBibtex {
+			return ports.ToolBibtex
+		}
+	default:
+		if usesBiblatex {
+			return ports.ToolBiber
+		}
+		if usesBibtex {
+			return ports.ToolBibtex
+		}
+	}
+	return ""
+}
+
+// hasContent reports whether the file exists and is not empty
+func (tr *toolchainRunner) hasContent(ctx context.Context, path string) bool {
+	info, err := tr.fileSystem.Stat(ctx, path)
+	return err == nil && info.Size() > 0
+}
+
+// auxContains reports whether the file contains the given marker
+func (tr *toolchainRunner) auxContains(ctx context.Context, path, marker string) bool {
+	data, err := tr.fileSystem.ReadFile(ctx, path)
+	return err == nil && strings.Contains(string(data), marker)
+}
+
+// searchPathEnv lets the engine and the tools running in dir find sources, .bib and .bst files
+// in the other directories too, such as the template's; the trailing separator keeps the default paths
+func searchPathEnv(dir string, dirs []string) []string {
+	var paths []string
+	for _, d := range dirs {
+		if d == "" || d == dir {
+			continue
+		}
+		if abs, err := filepath.Abs(d); err == nil {
+			d = abs
+		}
+		paths = append(paths, d)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	sep := string(os.PathListSeparator)
+	list := strings.Join(paths, sep) + sep
+	env := os.Environ()
+	for _, name := range []string{"TEXINPUTS", "BIBINPUTS", "BSTINPUTS"} {
+		env = append(env, name+"="+list+os.Getenv(name))
+	}
+	return env
+}
+
+// toolFailure reports a failed tool run as a warning: the PDF is still produced,
+// with unresolved citations or an empty index
+func toolFailure(cmd ports.Command, result ports.CommandResult, err error) ports.Diagnostic {
+	output := strings.TrimSpace(result.Stderr)
+	if output == "" {
+		output = strings.TrimSpace(result.Stdout)
+	}
+	return ports.Diagnostic{
+		Kind:     ports.DiagnosticWarning,
+		Severity: ports.SeverityWarning,
+		Message:  fmt.Sprintf("%s %s failed: %v", cmd.Executable, strings.Join(cmd.Args, " "), err),
+		Context:  output,
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compileWithToolchain(t *testing.T, engine *scriptedEngine, dir string, toolchain ports.Toolchain, passes int) (ports.CompileResult, error) {
+	t.Helper()
+	adapter := NewLaTeXCompilerAdapterWithWorkingDir(nil, infraadapters.NewOSFileSystem(), engine, dir)
+
+	opts := ports.NewCompileOptions("pdflatex", filepath.Join(dir, "report.pdf"), dir).
+		WithJobName("report").
+		WithPasses(passes).
+		WithToolchain(toolchain)
+
+	return adapter.Compile(context.Background(), `\documentclass{article}`, opts)
+}
+
+func toolNames(cmds []ports.Command) []string {
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.Executable)
+	}
+	return names
+}
+
+func TestLaTeXCompilerAdapter_Toolchain(t *testing.T) {
+	t.Run("runs bibtex when the aux declares a bibliography", func(t *testing.T) {
+		dir := t.TempDir()
+		engine := &scriptedEngine{basePath: filepath.Join(dir, "report"), passes: []passOutput{
+			{aux: "\\citation{knuth}\n\\bibdata{refs}"},
+		}}
+
+		result, err := compileWithToolchain(t, engine, dir, ports.NewToolchain("", "", ""), 1)
+
+		require.NoError(t, err)
+		require.Len(t, engine.tools, 1)
+		assert.Equal(t, "bibtex", engine.tools[0].Executable)
+		assert.Equal(t, []string{"report"}, engine.tools[0].Args)
+		assert.Equal(t, dir, engine.tools[0].Dir)
+		assert.Equal(t, toolchainPasses, result.Passes, "bibliography needs the extra passes")
+	})
+
+	t.Run("prefers biber when biblatex wrote a bcf", func(t *testing.T) {
+		dir := t.TempDir()
+		engine := &scriptedEngine{basePath: filepath.Join(dir, "report"), passes: []passOutput{
+			{aux: `\relax`, extra: map[string]string{".bcf": "<bcf:controlfile/>"}},
+		}}
+
+		_, err := compileWithToolchain(t, engine, dir, ports.NewToolchain("", "", ""), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"biber"}, toolNames(engine.tools))
+	})
+
+	t.Run("runs index and glossary tools", func(t *testing.T) {
+		dir := t.TempDir()
+		engine := &scriptedEngine{basePath: filepath.Join(dir, "report"), passes: []passOutput{
+			{aux: `\relax`, extra: map[string]string{".idx": `\indexentry{term}{1}`, ".glo": `\glossaryentry{x}{1}`}},
+		}}
+
+		_, err := compileWithToolchain(t, engine, dir, ports.NewToolchain("", "", ""), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"makeindex", "makeglossaries"}, toolNames(engine.tools))
+		assert.Equal(t, []string{"report.idx"}, engine.tools[0].Args)
+	})
+
+	t.Run("none disables a tool", func(t *testing.T) {
+		dir := t.TempDir()
+		engine := &scriptedEngine{basePath: filepath.Join(dir, "report"), passes: []passOutput{
+			{aux: `\bibdata{refs}`, extra: map[string]string{".idx": `\indexentry{term}{1}`}},
+		}}
+
+		result, err := compileWithToolchain(t, engine, dir, ports.NewToolchain(ports.ToolNone, ports.ToolNone, ""), 1)
+
+		require.NoError(t, err)
+		assert.Empty(t, engine.tools)
+		assert.Equal(t, 1, result.Passes)
+	})
+
+	t.Run("tool failure is reported as a warning", func(t *testing.T) {
+		dir := t.TempDir()
+		engine := &scriptedEngine{
+			basePath: filepath.Join(dir, "report"),
+			passes:   []passOutput{{aux: `\bibdata{refs}`}},
+			toolErr:  errors.New("exit status 2"),
+		}
+
+		result, err := compileWithToolchain(t, engine, dir, ports.NewToolchain(ports.ToolBibtex, "", ""), 1)
+
+		require.NoError(t, err)
+		require.Len(t, result.Diagnostics, 1)
+		assert.Equal(t, ports.SeverityWarning, result.Diagnostics[0].Severity)
+		assert.Contains(t, result.Diagnostics[0].Message, "bibtex report failed")
+		assert.Contains(t, result.Diagnostics[0].Context, "refs.bib")
+	})
+
+	t.Run("unknown tool is rejected", func(t *testing.T) {
+		dir := t.TempDir()
+		engine := &scriptedEngine{basePath: filepath.Join(dir, "report"), passes: []passOutput{{}}}
+
+		_, err := compileWithToolchain(t, engine, dir, ports.NewToolchain("bibtex8", "", ""), 1)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported bibliography tool "bibtex8"`)
+		assert.Zero(t, engine.runs)
+	})
+}
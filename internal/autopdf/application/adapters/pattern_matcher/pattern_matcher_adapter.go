@@ -21,7 +21,7 @@ func NewPatternMatcherAdapter() *PatternMatcherAdapter {
 	return &PatternMatcherAdapter{
 		exclusionPatterns: configs.DefaultExclusionPatterns,
 		inclusionPatterns: []string{
-			"*.tex", "*.yaml", "*.yml",
+			"*.tex", "*.typ", "*.yaml", "*.yml",
 		},
 	}
 }
@@ -0,0 +1,119 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Templates written when every variable reached them as a string keep working:
+//   - eq and ne compare values of different types by their printed form, so
+//     delim[[if eq .count "3"]] still matches the number 3 and "true" the bool true
+//   - lt, le, gt and ge compare ints with floats, which text/template refuses
+//   - .vars refers to the variables themselves, unless a variable is named vars
+
+// legacyRoot is the alias older templates reach the variables through
+const legacyRoot = "vars"
+
+// withCompatAliases adds the legacy alias to the template data
+func withCompatAliases(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	if _, ok := data[legacyRoot]; ok {
+		return data
+	}
+	aliased := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		aliased[k] = v
+	}
+	aliased[legacyRoot] = data
+	return aliased
+}
+
+// compatFuncs returns the comparison functions replacing text/template's builtins
+func compatFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"eq": func(a interface{}, others ...interface{}) bool {
+			for _, b := range others {
+				if equal(a, b) {
+					return true
+				}
+			}
+			return false
+		},
+		"ne": func(a, b interface{}) bool { return !equal(a, b) },
+		"lt": ordered(func(c int) bool { return c < 0 }),
+		"le": ordered(func(c int) bool { return c <= 0 }),
+		"gt": ordered(func(c int) bool { return c > 0 }),
+		"ge": ordered(func(c int) bool { return c >= 0 }),
+	}
+}
+
+// ordered builds a comparison function from the test it applies to compare's result
+func ordered(test func(int) bool) func(a, b interface{}) (bool, error) {
+	return func(a, b interface{}) (bool, error) {
+		c, err := compare(a, b)
+		if err != nil {
+			return false, err
+		}
+		return test(c), nil
+	}
+}
+
+// equal compares numbers by value, values of one comparable type with ==
+// and anything else by its printed form
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	if a != nil && b != nil && reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() {
+		return a == b
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// compare orders two numbers or two strings
+func compare(a, b interface{}) (int, error) {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	x, okA := a.(string)
+	y, okB := b.(string)
+	if !okA || !okB {
+		return 0, fmt.Errorf("incompatible types for comparison: %T and %T", a, b)
+	}
+	switch {
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// number returns the value of any integer or float as a float64
+func number(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
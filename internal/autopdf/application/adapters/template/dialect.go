@@ -0,0 +1,154 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BuddhiLW/AutoPDF/configs"
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/BuddhiLW/AutoPDF/pkg/latex"
+)
+
+// Dialect describes the markup language a template is written in:
+// the delimiters that cannot clash with it and how to escape text for it
+type Dialect struct {
+	Name       string
+	Extension  string // Of template sources and partials
+	LeftDelim  string
+	RightDelim string
+	Escape     func(string) string // Makes a value print literally in the markup
+	AutoEscape bool                // Printed values are escaped for their context, see escape.go and escape_typst.go
+}
+
+// LaTeXDialect is used for every TeX engine
+// delim[[ ]] never occurs in LaTeX, whose own syntax is full of braces and brackets
+var LaTeXDialect = Dialect{
+	Name:       "latex",
+	Extension:  ports.SourceExtension(ports.EnginePDFLaTeX),
+	LeftDelim:  configs.TemplateStartDelim,
+	RightDelim: configs.TemplateEndDelim,
+	Escape:     EscapeLaTeX,
+	AutoEscape: true,
+}
+
+// TypstDialect is used for the typst engine
+// Typst markup uses brackets for content blocks, so ]] is common in it; braces only open code blocks
+var TypstDialect = Dialect{
+	Name:       "typst",
+	Extension:  ports.SourceExtension(ports.EngineTypst),
+	LeftDelim:  "{{",
+	RightDelim: "}}",
+	Escape:     EscapeTypst,
+	AutoEscape: true,
+}
+
+// escapeContext returns the dialect's escaping context at the top of a template
+func (d Dialect) escapeContext() escapeContext {
+	if d.Name == TypstDialect.Name {
+		return typstContext{}
+	}
+	return latexContext{}
+}
+
+// DialectFor returns the dialect of the sources an engine compiles
+func DialectFor(engine string) Dialect {
+	if engine == ports.EngineTypst {
+		return TypstDialect
+	}
+	return LaTeXDialect
+}
+
+// DialectForTemplate returns the dialect of a template file from its extension,
+// for callers that do not know the engine the template is compiled with
+func DialectForTemplate(templatePath string) Dialect {
+	if strings.EqualFold(filepath.Ext(templatePath), TypstDialect.Extension) {
+		return TypstDialect
+	}
+	return LaTeXDialect
+}
+
+// WithDelimiters returns the dialect with other action delimiters, when both are given
+func (d Dialect) WithDelimiters(left, right string) Dialect {
+	if left != "" && right != "" {
+		d.LeftDelim, d.RightDelim = left, right
+	}
+	return d
+}
+
+// magicComment sets a template's delimiters from its first lines, in either dialect's comment syntax:
+//
+//	% autopdf: delimiters << >>
+//	// autopdf: delimiters << >>
+var magicComment = regexp.MustCompile(`^[ \t]*(?:%|//)[ \t]*autopdf:[ \t]*delimiters[ \t]+(\S+)[ \t]+(\S+)[ \t]*$`)
+
+// ForContent applies the delimiters a template's front matter or magic comment sets, which
+// take precedence over configured ones, the magic comment over the front matter. The comment
+// has to be in the leading comment block. Both are blanked in the returned content, as the
+// new delimiters would parse them as actions; a LaTeX template's front matter is never rendered
+func (d Dialect) ForContent(content string) (Dialect, string) {
+	if d.Name == LaTeXDialect.Name {
+		// Malformed front matter is reported when the template's config is resolved
+		if frontMatter, stripped, err := config.ParseFrontMatter(content); err == nil && frontMatter != nil {
+			d = d.WithDelimiters(frontMatter.Delimiters.Left, frontMatter.Delimiters.Right)
+			content = stripped
+		}
+	}
+
+	offset := 0
+	for offset < len(content) {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content) - offset
+		}
+		line := strings.TrimRight(content[offset:offset+end], "\r")
+		if m := magicComment.FindStringSubmatch(line); m != nil {
+			return d.WithDelimiters(m[1], m[2]), content[:offset] + content[offset+len(line):]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "%") && !strings.HasPrefix(trimmed, "//") {
+			break
+		}
+		offset += end + 1
+	}
+	return d, content
+}
+
+// EscapeLaTeX escapes the characters LaTeX treats as commands
+func EscapeLaTeX(s string) string {
+	return latex.Escape(s)
+}
+
+var typstReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`*`, `\*`,
+	`_`, `\_`,
+	"`", "\\`",
+	`<`, `\<`,
+	`>`, `\>`,
+	`@`, `\@`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`~`, `\~`,
+	`/`, `\/`,
+)
+
+// EscapeTypst escapes the characters Typst markup treats as syntax
+// Headings and list markers only start a line, so they are escaped there alone
+func EscapeTypst(s string) string {
+	lines := strings.Split(typstReplacer.Replace(s), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && strings.ContainsRune("=-+", rune(trimmed[0])) {
+			indent := line[:len(line)-len(trimmed)]
+			lines[i] = indent + `\` + trimmed
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialectFor(t *testing.T) {
+	assert.Equal(t, "typst", DialectFor("typst").Name)
+	for _, engine := range []string{"pdflatex", "xelatex", "lualatex", "tectonic", ""} {
+		assert.Equal(t, "latex", DialectFor(engine).Name, engine)
+	}
+}
+
+func TestDialectForTemplate(t *testing.T) {
+	assert.Equal(t, "typst", DialectForTemplate("/srv/templates/invoice.typ").Name)
+	assert.Equal(t, "typst", DialectForTemplate("INVOICE.TYP").Name)
+	assert.Equal(t, "latex", DialectForTemplate("letter.tex").Name)
+	assert.Equal(t, "latex", DialectForTemplate("template").Name)
+}
+
+func TestEscapeLaTeX(t *testing.T) {
+	assert.Equal(t, `50\% off \& \$5 for \#1 \{a\_b\} \textasciitilde{} \textasciicircum{} \textbackslash{}`,
+		EscapeLaTeX(`50% off & $5 for #1 {a_b} ~ ^ \`))
+}
+
+func TestEscapeTypst(t *testing.T) {
+	assert.Equal(t, `Price: \$5 \#1 \*bold\* \_x\_ \@ref \<tag\> \[x\] a\/\/b`,
+		EscapeTypst(`Price: $5 #1 *bold* _x_ @ref <tag> [x] a//b`))
+	assert.Equal(t, "\\= Not a heading\n  \\- not a list\nx - y", EscapeTypst("= Not a heading\n  - not a list\nx - y"))
+}
+
+func TestTemplateProcessorAdapter_TypstDialect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoice.typ")
+	require.NoError(t, os.WriteFile(path, []byte(`#set page(paper: "a4")
+= Invoice {{ .number }}
+#box[#text[{{ escape .client }}]]
+`), 0644))
+
+	adapter := NewTemplateProcessorAdapter(&config.Config{Engine: "typst"})
+	out, err := adapter.Process(context.Background(), path, ports.TemplateData{"number": 42, "client": "ACME #1"})
+	require.NoError(t, err)
+	assert.Equal(t, `#set page(paper: "a4")
+= Invoice 42
+#box[#text[ACME \#1]]
+`, out)
+
+	// LaTeX engines keep their delimiters, which Typst content would otherwise be parsed with
+	adapter = NewTemplateProcessorAdapter(&config.Config{Engine: "xelatex"})
+	out, err = adapter.Process(context.Background(), path, ports.TemplateData{"number": 42})
+	require.NoError(t, err)
+	assert.Contains(t, out, "{{ .number }}")
+}
+
+func TestDialect_WithDelimiters(t *testing.T) {
+	d := LaTeXDialect.WithDelimiters("<<", ">>")
+	assert.Equal(t, "<<", d.LeftDelim)
+	assert.Equal(t, ">>", d.RightDelim)
+	assert.Equal(t, "delim[[", LaTeXDialect.LeftDelim, "the package dialect is not changed")
+
+	// Unset delimiters keep the dialect's
+	d = LaTeXDialect.WithDelimiters("<<", "")
+	assert.Equal(t, "delim[[", d.LeftDelim)
+	assert.Equal(t, "]]", d.RightDelim)
+}
+
+func TestDialect_ForContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialect     Dialect
+		content     string
+		wantLeft    string
+		wantContent string
+	}{
+		{"latex comment", LaTeXDialect, "% autopdf: delimiters << >>\n<<.x>>", "<<", "\n<<.x>>"},
+		{"typst comment", TypstDialect, "// autopdf: delimiters [% %]\n[%.x%]", "[%", "\n[%.x%]"},
+		{"after other comments", LaTeXDialect, "% Letter\n\n%autopdf:delimiters << >>\r\n<<.x>>", "<<", "% Letter\n\n\r\n<<.x>>"},
+		{"after markup", LaTeXDialect, "\\documentclass{article}\n% autopdf: delimiters << >>\n", "delim[[", "\\documentclass{article}\n% autopdf: delimiters << >>\n"},
+		{"none", TypstDialect, "= Title {{ .x }}", "{{", "= Title {{ .x }}"},
+		{"front matter", LaTeXDialect, "% ---\n% delimiters: {left: \"<<\", right: \">>\"}\n% ---\n<<.x>>", "<<", "\n\n\n<<.x>>"},
+		{"magic comment after front matter", LaTeXDialect, "% ---\n% delimiters: {left: \"<<\", right: \">>\"}\n% ---\n% autopdf: delimiters (( ))\n((.x))", "((", "\n\n\n\n((.x))"},
+		{"front matter without delimiters", LaTeXDialect, "% ---\n% passes: 2\n% ---\ndelim[[.x]]", "delim[[", "\n\n\ndelim[[.x]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, content := tt.dialect.ForContent(tt.content)
+			assert.Equal(t, tt.wantLeft, d.LeftDelim)
+			assert.Equal(t, tt.wantContent, content)
+		})
+	}
+}
+
+func TestExecute_MagicCommentDelimiters(t *testing.T) {
+	partials := []Partial{
+		{Name: "sign", Path: "sign.tex", Content: "% autopdf: delimiters (( ))\nYours, ((.from))"},
+		{Name: "date", Path: "date.tex", Content: "delim[[.date]]"},
+	}
+	content := "% autopdf: delimiters << >>\nDear <<.to>>, <<template \"date\" .>> <<template \"sign\" .>> delim[[.to]]"
+
+	out, err := Execute("letter", content, map[string]interface{}{"to": "Ann", "from": "Bob", "date": "today"}, LaTeXDialect, partials...)
+	require.NoError(t, err)
+	assert.Equal(t, "\nDear Ann, today \nYours, Bob delim[[.to]]", out)
+}
+
+func TestTemplateProcessorAdapter_ConfiguredDelimiters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "letter.tex")
+	require.NoError(t, os.WriteFile(path, []byte(`Dear <<.name>>, delim[[.name]]`), 0644))
+
+	adapter := NewTemplateProcessorAdapter(&config.Config{Delimiters: config.Delimiters{Left: "<<", Right: ">>"}})
+	out, err := adapter.Process(context.Background(), path, ports.TemplateData{"name": "Ann"})
+	require.NoError(t, err)
+	assert.Equal(t, `Dear Ann, delim[[.name]]`, out)
+
+	adapter = NewTemplateProcessorAdapter(&config.Config{Delimiters: config.Delimiters{Left: "<<"}})
+	_, err = adapter.Process(context.Background(), path, ports.TemplateData{"name": "Ann"})
+	assert.Error(t, err)
+}
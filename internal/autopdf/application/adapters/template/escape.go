@@ -0,0 +1,341 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/BuddhiLW/AutoPDF/pkg/latex"
+)
+
+// LaTeX is trusted markup, printed without escaping; in Typst templates it holds Typst markup
+// Like html/template's HTML type, it must only hold markup from a trusted source:
+// templates produce it with raw (or its alias latex) to opt a value out of escaping
+type LaTeX string
+
+// escapeContext is the state of a dialect's scanner at a point of the template
+type escapeContext interface {
+	// next scans text and returns the context at its end
+	next(text string) escapeContext
+	// escaper names the function escaping values printed in this context and returns it
+	escaper() (string, interface{})
+}
+
+// Escaping for LaTeX templates is modeled on html/template: after parsing, every
+// action that prints a value gets an escaper appended to its pipeline, chosen from
+// the LaTeX context the action appears in:
+//   - text: EscapeLaTeX
+//   - \url{} and the first argument of \href{}: escapeURL
+//   - file arguments (\includegraphics{}, \input{}, ...): the path is checked, not escaped
+//   - verbatim environments and \verb: printed as is, unless it would end the verbatim
+//
+// Values of type LaTeX pass through every escaper unchanged.
+
+// Escaper function names appended to pipelines
+const (
+	escaperText = "_latex_text"
+	escaperURL  = "_latex_url"
+	escaperPath = "_latex_path"
+	// escaperVerbatim and escaperVerb are suffixed with the environment and delimiter they guard
+	escaperVerbatim = "_latex_verbatim_"
+	escaperVerb     = "_latex_verb_"
+)
+
+// latexState is the kind of LaTeX text an action is printed into
+type latexState uint8
+
+const (
+	stateText latexState = iota
+	stateComment
+	stateURL
+	statePath
+	stateVerbatim
+	stateVerb
+)
+
+// urlCommands take a URL as their first argument
+var urlCommands = map[string]bool{"url": true, "href": true}
+
+// pathCommands take a file name as their first mandatory argument
+var pathCommands = map[string]bool{
+	"includegraphics": true,
+	"includepdf":      true,
+	"input":           true,
+	"include":         true,
+	"lstinputlisting": true,
+	"addbibresource":  true,
+	"bibliography":    true,
+}
+
+// verbatimEnvs are the environments whose body LaTeX does not interpret
+var verbatimEnvs = []string{"verbatim", "verbatim*", "Verbatim", "lstlisting", "minted", "comment"}
+
+// verbCommands take inline verbatim text between a delimiter of the author's choice
+var verbCommands = map[string]bool{"verb": true, "lstinline": true}
+
+var beginPattern = regexp.MustCompile(`^\s*\{([A-Za-z*]+)\}`)
+
+// latexContext is the state of the scanner at a point of the template
+type latexContext struct {
+	state   latexState
+	depth   int        // brace depth inside a URL or path argument
+	env     int        // index of the verbatim environment into verbatimEnvs
+	delim   byte       // delimiter closing \verb
+	pending latexState // kind of argument a command seen last is waiting for
+	verb    bool       // a \verb command is waiting for its delimiter
+}
+
+// advance scans text and returns the context at its end
+func (c latexContext) advance(text string) latexContext {
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch c.state {
+		case stateComment:
+			if ch == '\n' {
+				c.state = stateText
+			}
+		case stateVerbatim:
+			end := `\end{` + verbatimEnvs[c.env] + `}`
+			idx := strings.Index(text[i:], end)
+			if idx < 0 {
+				return c
+			}
+			i += idx + len(end) - 1
+			c.state = stateText
+		case stateVerb:
+			if ch == c.delim {
+				c.state = stateText
+			}
+		case stateURL, statePath:
+			switch ch {
+			case '\\':
+				i++
+			case '{':
+				c.depth++
+			case '}':
+				c.depth--
+				if c.depth == 0 {
+					c.state = stateText
+				}
+			}
+		default:
+			i = c.scanText(text, i)
+		}
+	}
+	return c
+}
+
+// scanText handles the text-state character at text[i] and returns the index of the last character consumed
+func (c *latexContext) scanText(text string, i int) int {
+	ch := text[i]
+	switch {
+	case c.verb:
+		if ch == '*' || ch == ' ' {
+			return i
+		}
+		c.verb = false
+		c.state, c.delim = stateVerb, ch
+		if ch == '{' {
+			c.delim = '}'
+		}
+		return i
+	case c.pending != stateText:
+		switch ch {
+		case ' ', '\t', '\n':
+			return i
+		case '[':
+			if end := strings.IndexByte(text[i:], ']'); end >= 0 {
+				return i + end
+			}
+			return len(text)
+		case '{':
+			c.state, c.depth, c.pending = c.pending, 1, stateText
+			return i
+		}
+		c.pending = stateText
+	}
+
+	switch ch {
+	case '%':
+		c.state = stateComment
+	case '\\':
+		start := i + 1
+		end := start
+		for end < len(text) && isLetter(text[end]) {
+			end++
+		}
+		if end == start {
+			// A control symbol such as \% or \\
+			return start
+		}
+		name := text[start:end]
+		switch {
+		case urlCommands[name]:
+			c.pending = stateURL
+		case pathCommands[name]:
+			c.pending = statePath
+		case verbCommands[name]:
+			c.verb = true
+		case name == "begin":
+			if m := beginPattern.FindStringSubmatch(text[end:]); m != nil {
+				for idx, env := range verbatimEnvs {
+					if env == m[1] {
+						c.state, c.env = stateVerbatim, idx
+						return end + len(m[0]) - 1
+					}
+				}
+			}
+		}
+		return end - 1
+	}
+	return i
+}
+
+func isLetter(ch byte) bool {
+	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+func (c latexContext) next(text string) escapeContext {
+	return c.advance(text)
+}
+
+// escaper names the function escaping values printed in this context and returns it
+func (c latexContext) escaper() (string, interface{}) {
+	switch c.state {
+	case stateURL:
+		return escaperURL, escapeURL
+	case statePath:
+		return escaperPath, checkPath
+	case stateVerbatim:
+		return fmt.Sprintf("%s%d", escaperVerbatim, c.env), contextEscaper(c)
+	case stateVerb:
+		return fmt.Sprintf("%s%d", escaperVerb, c.delim), contextEscaper(c)
+	}
+	return escaperText, escapeText
+}
+
+// autoEscape appends the escaper of each printing action's context to its pipeline
+// and registers the escapers with the template; start is the dialect's context at the top of a template
+func autoEscape(tmpl *template.Template, start escapeContext) {
+	funcs := template.FuncMap{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		escapeNode(t.Tree.Root, start, funcs)
+	}
+	tmpl.Funcs(funcs)
+}
+
+// escapeNode walks node in document order, returning the context after it
+// Branches all start from the context before them; the one of the first branch carries on
+func escapeNode(node parse.Node, c escapeContext, funcs template.FuncMap) escapeContext {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			c = escapeNode(child, c, funcs)
+		}
+	case *parse.TextNode:
+		c = c.next(string(n.Text))
+	case *parse.ActionNode:
+		// Actions declaring variables print nothing
+		if len(n.Pipe.Decl) == 0 {
+			name, fn := c.escaper()
+			if _, ok := funcs[name]; !ok {
+				funcs[name] = fn
+			}
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pipe.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(name).SetPos(n.Pipe.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		c = escapeBranch(&n.BranchNode, c, funcs)
+	case *parse.RangeNode:
+		c = escapeBranch(&n.BranchNode, c, funcs)
+	case *parse.WithNode:
+		c = escapeBranch(&n.BranchNode, c, funcs)
+	}
+	return c
+}
+
+func escapeBranch(n *parse.BranchNode, c escapeContext, funcs template.FuncMap) escapeContext {
+	after := escapeNode(n.List, c, funcs)
+	if n.ElseList != nil {
+		escapeNode(n.ElseList, c, funcs)
+	}
+	return after
+}
+
+// contextEscaper returns the escaper of the verbatim contexts, which depend on what closes them
+func contextEscaper(c latexContext) func(...interface{}) (string, error) {
+	var terminator string
+	switch c.state {
+	case stateVerbatim:
+		terminator = `\end{` + verbatimEnvs[c.env] + `}`
+	case stateVerb:
+		terminator = string(c.delim)
+	}
+	return func(args ...interface{}) (string, error) {
+		s, trusted := stringify(args)
+		if trusted {
+			return s, nil
+		}
+		if strings.Contains(s, terminator) || (c.state == stateVerb && strings.Contains(s, "\n")) {
+			return "", fmt.Errorf("value %q would end the verbatim text it is printed in", s)
+		}
+		return s, nil
+	}
+}
+
+// stringify prints escaper arguments as text/template would, reporting whether they are trusted markup
+// Missing values print as nothing rather than "<no value>"
+func stringify(args []interface{}) (string, bool) {
+	if len(args) == 1 {
+		switch v := args[0].(type) {
+		case LaTeX:
+			return string(v), true
+		case string:
+			return v, false
+		case nil:
+			return "", false
+		}
+	}
+	return fmt.Sprint(args...), false
+}
+
+func escapeText(args ...interface{}) string {
+	s, trusted := stringify(args)
+	if trusted {
+		return s
+	}
+	return EscapeLaTeX(s)
+}
+
+// escapeURL escapes a URL printed in \url or \href, see latex.EscapeURL
+func escapeURL(args ...interface{}) string {
+	s, trusted := stringify(args)
+	if trusted {
+		return s
+	}
+	return latex.EscapeURL(s)
+}
+
+// checkPath prints a file name unchanged: LaTeX reads it literally, so escaping
+// an underscore would change the file. Characters that would end the argument are refused
+func checkPath(args ...interface{}) (string, error) {
+	s, trusted := stringify(args)
+	if trusted {
+		return s, nil
+	}
+	if strings.ContainsAny(s, "\\{}%\n") {
+		return "", fmt.Errorf("file name %q contains characters LaTeX would interpret", s)
+	}
+	return s, nil
+}
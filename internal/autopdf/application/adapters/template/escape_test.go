@@ -0,0 +1,126 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_AutoEscape(t *testing.T) {
+	data := map[string]interface{}{
+		"company":  "Smith & Sons_Ltd",
+		"discount": "50% {off}",
+		"website":  `https://example.com/a_b?q=1&r=2#top`,
+		"logo":     "assets/my_logo.png",
+		"code":     `if a && b { return $x }`,
+		"bold":     `\textbf{Bold}`,
+		"count":    3,
+		"items":    []interface{}{"R&D"},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"text", `\section{delim[[.company]]}`, `\section{Smith \& Sons\_Ltd}`},
+		{"pipeline", `delim[[.discount | upper]]`, `50\% \{OFF\}`},
+		{"numbers", `delim[[.count]] pages`, `3 pages`},
+		{"missing value", `[delim[[.missing]]]`, `[]`},
+		{"url", `\url{delim[[.website]]}`, `\url{https://example.com/a_b?q=1&r=2\#top}`},
+		{"href", `\href{delim[[.website]]}{delim[[.company]]}`, `\href{https://example.com/a_b?q=1&r=2\#top}{Smith \& Sons\_Ltd}`},
+		{"path", `\includegraphics[width=3cm]{delim[[.logo]]}`, `\includegraphics[width=3cm]{assets/my_logo.png}`},
+		{"after path", `\input{delim[[.logo]]} delim[[.company]]`, `\input{assets/my_logo.png} Smith \& Sons\_Ltd`},
+		{"verbatim", "\\begin{verbatim}delim[[.code]]\\end{verbatim}delim[[.code]]",
+			"\\begin{verbatim}if a && b { return $x }\\end{verbatim}if a \\&\\& b \\{ return \\$x \\}"},
+		{"verb", `\verb|delim[[.company]]|`, `\verb|Smith & Sons_Ltd|`},
+		{"escaped symbols", `\% \url delim[[.company]]`, `\% \url Smith \& Sons\_Ltd`},
+		{"comment", "% \\url{\ndelim[[.company]]", "% \\url{\nSmith \\& Sons\\_Ltd"},
+		{"raw", `delim[[raw .bold]] delim[[latex .bold]]`, `\textbf{Bold} \textbf{Bold}`},
+		{"escape is not escaped twice", `delim[[escape .company]]`, `Smith \& Sons\_Ltd`},
+		{"range", `delim[[range .items]]\item delim[[.]]delim[[end]]`, `\item R\&D`},
+		{"variable declarations", `delim[[$c := .company]]delim[[$c]]`, `Smith \& Sons\_Ltd`},
+		{"defined templates", `delim[[define "name"]]delim[[.company]]delim[[end]]\url{x}delim[[template "name" .]]`, `\url{x}Smith \& Sons\_Ltd`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Execute("test", tt.template, data, LaTeXDialect)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestExecute_AutoEscapeRefusesBreakingValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		value    string
+	}{
+		{"path", `\includegraphics{delim[[.v]]}`, `x}\input{/etc/passwd`},
+		{"verbatim", `\begin{verbatim}delim[[.v]]\end{verbatim}`, `a\end{verbatim}\input{x}`},
+		{"verb", `\verb|delim[[.v]]|`, `a|b`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Execute("test", tt.template, map[string]interface{}{"v": tt.value}, LaTeXDialect)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExecute_TypstAutoEscape(t *testing.T) {
+	data := map[string]interface{}{
+		"client":  "ACME #1 <b> *x*",
+		"quote":   "say \"hi\"\\n",
+		"heading": "= not a heading",
+		"count":   3,
+		"rate":    2.0,
+		"paid":    true,
+		"code":    "#panic()",
+		"bold":    "*Bold*",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"markup", `Client: {{ .client }}`, `Client: ACME \#1 \<b\> \*x\*`},
+		{"line start", "{{ .heading }}", `\= not a heading`},
+		{"content block", `#text(fill: red)[{{ .client }}]`, `#text(fill: red)[ACME \#1 \<b\> \*x\*]`},
+		{"string", `#set document(title: "{{ .quote }}")`, `#set document(title: "say \"hi\"\\n")`},
+		{"string in content block", `#box[#text("{{ .code }}")]`, `#box[#text("#panic()")]`},
+		{"code", `#let total = {{ .count }} * {{ .rate }}`, `#let total = 3 * 2.0`},
+		{"code values", `#let paid = {{ .paid }}; #let note = {{ .missing }}; #let c = {{ .code }}`, `#let paid = true; #let note = none; #let c = "#panic()"`},
+		{"after statement", "#set page(paper: \"a4\")\n{{ .code }}", "#set page(paper: \"a4\")\n\\#panic()"},
+		{"after expression", `#emph[x] {{ .code }}`, `#emph[x] \#panic()`},
+		{"raw", "`{{ .code }}` {{ .code }}", "`#panic()` \\#panic()"},
+		{"comment", "// {{ .bold }}\n{{ .bold }}", "// \\*Bold\\*\n\\*Bold\\*"},
+		{"raw opt-out", `{{ raw .bold }}`, `*Bold*`},
+		{"escape is not escaped twice", `{{ escape .client }}`, `ACME \#1 \<b\> \*x\*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Execute("test", tt.template, data, TypstDialect)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestExecute_TypstRefusesValuesEndingRawText(t *testing.T) {
+	_, err := Execute("test", "```{{ .v }}```", map[string]interface{}{"v": "a```#panic()"}, TypstDialect)
+	assert.Error(t, err)
+
+	out, err := Execute("test", "```{{ .v }}```", map[string]interface{}{"v": "a`b"}, TypstDialect)
+	require.NoError(t, err)
+	assert.Equal(t, "```a`b```", out)
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Escaping for Typst templates works as for LaTeX ones (see escape.go), with the contexts of Typst:
+//   - markup, including content blocks and math: EscapeTypst
+//   - string literals: escapeTypstString, the value stays inside the quotes
+//   - code outside string literals: the value as a literal, numbers and booleans
+//     as they are, missing values as none and anything else as a string
+//   - raw text between backticks: printed as is, unless it would end the raw text
+//
+// Values of type LaTeX pass through every escaper unchanged.
+
+// Escaper function names appended to pipelines
+const (
+	escaperTypstMarkup = "_typst_markup"
+	escaperTypstString = "_typst_string"
+	escaperTypstCode   = "_typst_code"
+	// escaperTypstRaw is suffixed with the number of backticks closing the raw text
+	escaperTypstRaw = "_typst_raw_"
+)
+
+// typstFrame is a markup or code region the scanner is inside of
+type typstFrame struct {
+	code      bool
+	closer    byte // closes the region; 0 for the document and for embedded expressions
+	statement bool // an embedded set, let, show... statement, which ends with its line
+}
+
+// typstStatements are the keywords whose embedded statement runs to the end of the line
+var typstStatements = map[string]bool{
+	"let": true, "set": true, "show": true, "import": true, "include": true,
+	"if": true, "for": true, "while": true, "return": true, "context": true,
+}
+
+// typstContext is the state of the scanner at a point of a Typst template
+type typstContext struct {
+	frames       []typstFrame // enclosing regions, innermost last; none is the document's markup
+	inString     bool
+	raw          int // backticks closing the raw text being read, 0 outside raw text
+	lineComment  bool
+	blockComment bool
+}
+
+// top returns the innermost region
+func (c typstContext) top() typstFrame {
+	if len(c.frames) == 0 {
+		return typstFrame{}
+	}
+	return c.frames[len(c.frames)-1]
+}
+
+func (c typstContext) next(text string) escapeContext {
+	return c.advance(text)
+}
+
+// advance scans text and returns the context at its end
+func (c typstContext) advance(text string) typstContext {
+	// Branches share the context they start from, so the stack is never appended to in place
+	c.frames = append([]typstFrame(nil), c.frames...)
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case c.lineComment:
+			if ch == '\n' {
+				c.lineComment = false
+				i--
+			}
+		case c.blockComment:
+			if strings.HasPrefix(text[i:], "*/") {
+				c.blockComment = false
+				i++
+			}
+		case c.raw > 0:
+			if closing := strings.Repeat("`", c.raw); strings.HasPrefix(text[i:], closing) {
+				i += c.raw - 1
+				c.raw = 0
+			}
+		case c.inString:
+			switch ch {
+			case '\\':
+				i++
+			case '"':
+				c.inString = false
+			}
+		case strings.HasPrefix(text[i:], "//"):
+			c.lineComment = true
+			i++
+		case strings.HasPrefix(text[i:], "/*"):
+			c.blockComment = true
+			i++
+		case c.top().code:
+			i = c.scanCode(text, i)
+		default:
+			i = c.scanMarkup(text, i)
+		}
+	}
+	return c
+}
+
+// scanMarkup handles the markup character at text[i] and returns the index of the last character consumed
+func (c *typstContext) scanMarkup(text string, i int) int {
+	switch ch := text[i]; ch {
+	case '\\':
+		return i + 1
+	case '`':
+		ticks := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+		if ticks != 2 {
+			// Two backticks are empty raw text
+			c.raw = ticks
+		}
+		return i + ticks - 1
+	case '#':
+		if i+1 < len(text) && isTypstCodeStart(text[i+1]) {
+			end := i + 1
+			for end < len(text) && isTypstIdentifier(text[end]) {
+				end++
+			}
+			c.frames = append(c.frames, typstFrame{code: true, statement: typstStatements[text[i+1:end]]})
+		}
+	case ']':
+		if top := c.top(); top.closer == ']' {
+			c.frames = c.frames[:len(c.frames)-1]
+		}
+	}
+	return i
+}
+
+// scanCode handles the code character at text[i] and returns the index of the last character consumed
+func (c *typstContext) scanCode(text string, i int) int {
+	top := c.top()
+	switch ch := text[i]; ch {
+	case '"':
+		c.inString = true
+	case '(':
+		c.frames = append(c.frames, typstFrame{code: true, closer: ')'})
+	case '{':
+		c.frames = append(c.frames, typstFrame{code: true, closer: '}'})
+	case '[':
+		c.frames = append(c.frames, typstFrame{closer: ']'})
+	case ')', '}':
+		if top.closer == ch {
+			c.frames = c.frames[:len(c.frames)-1]
+		}
+	case '\n':
+		if top.closer == 0 {
+			c.frames = c.frames[:len(c.frames)-1]
+		}
+	default:
+		// An embedded expression such as #name.field ends at the first character not part of it
+		if top.closer == 0 && !top.statement && !isTypstIdentifier(ch) && ch != '.' {
+			c.frames = c.frames[:len(c.frames)-1]
+			return i - 1
+		}
+	}
+	return i
+}
+
+func isTypstCodeStart(ch byte) bool {
+	return isLetter(ch) || ch == '_' || ch == '(' || ch == '{'
+}
+
+func isTypstIdentifier(ch byte) bool {
+	return isLetter(ch) || ('0' <= ch && ch <= '9') || ch == '_' || ch == '-'
+}
+
+// escaper names the function escaping values printed in this context and returns it
+func (c typstContext) escaper() (string, interface{}) {
+	switch {
+	case c.raw > 0:
+		return fmt.Sprintf("%s%d", escaperTypstRaw, c.raw), typstRawEscaper(c.raw)
+	case c.inString:
+		return escaperTypstString, escapeTypstString
+	case c.lineComment, c.blockComment:
+		return escaperTypstMarkup, escapeTypstMarkup
+	case c.top().code:
+		return escaperTypstCode, escapeTypstCode
+	}
+	return escaperTypstMarkup, escapeTypstMarkup
+}
+
+func escapeTypstMarkup(args ...interface{}) string {
+	s, trusted := stringify(args)
+	if trusted {
+		return s
+	}
+	return EscapeTypst(s)
+}
+
+var typstStringReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// escapeTypstString escapes a value printed inside a string literal
+func escapeTypstString(args ...interface{}) string {
+	s, trusted := stringify(args)
+	if trusted {
+		return s
+	}
+	return typstStringReplacer.Replace(s)
+}
+
+// escapeTypstCode prints a value as a Typst literal, so it is never evaluated as code
+func escapeTypstCode(args ...interface{}) string {
+	if len(args) == 1 {
+		switch v := args[0].(type) {
+		case nil:
+			return "none"
+		case bool:
+			return strconv.FormatBool(v)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return fmt.Sprint(v)
+		case float32, float64:
+			f, _ := number(v)
+			s := strconv.FormatFloat(f, 'f', -1, 64)
+			// A float stays one in Typst, which types 3 and 3.0 differently
+			if !strings.Contains(s, ".") {
+				s += ".0"
+			}
+			return s
+		}
+	}
+	s, trusted := stringify(args)
+	if trusted {
+		return s
+	}
+	return `"` + typstStringReplacer.Replace(s) + `"`
+}
+
+// typstRawEscaper returns the escaper of raw text closed by ticks backticks
+func typstRawEscaper(ticks int) func(...interface{}) (string, error) {
+	closing := strings.Repeat("`", ticks)
+	return func(args ...interface{}) (string, error) {
+		s, trusted := stringify(args)
+		if trusted {
+			return s, nil
+		}
+		if strings.Contains(s, closing) {
+			return "", fmt.Errorf("value %q would end the raw text it is printed in", s)
+		}
+		return s, nil
+	}
+}
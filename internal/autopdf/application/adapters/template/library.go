@@ -0,0 +1,608 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// The function library every template can call, besides text/template's builtins
+// Functions take the value they operate on last, so they chain in pipelines:
+// delim[[.total | currency "EUR"]] is delim[[currency "EUR" .total]]
+//
+// Strings (s may be any value, printed as it would be: upper .invoice.number)
+//
+//	upper s, lower s, title s   change case
+//	trim s                      remove leading and trailing white space
+//	truncate n s                shorten s to n characters, ending in "..."
+//	replace old new s           replace every occurrence of old
+//	join sep list               join the items of a list
+//
+// Missing values (nil, "", empty lists and maps; numbers and bools are never empty)
+//
+//	default fallback v          v, or fallback when v is empty
+//	coalesce a b ...            the first value that is not empty
+//	required message v          v, or fail the build with message when v is empty
+//
+// Dates (layouts are Go's, e.g. "02/01/2006" or "January 2, 2006")
+//
+//	now                         the current time
+//	parseDate layout s          parse s with layout
+//	date layout v               format a time, an RFC 3339 or YYYY-MM-DD string or Unix seconds
+//
+// Numbers (numbers may be given as numeric strings)
+//
+//	number decimals v           1234.5 -> 1,234.50 with 2 decimals
+//	percent decimals v          0.125 -> 12.5% with 1 decimal
+//	currency code v             USD 1234.5 -> $1,234.50, BRL -> R$ 1.234,50, see currencies
+//	add, mul a b ...            sum and product
+//	sub, div, mod a b           difference, quotient and remainder
+//	round decimals v            round half away from zero
+//	min, max a b ...            smallest and largest
+//
+// Lists (items of lists of maps are addressed by key, dotted for nested maps)
+//
+//	sum [key] list              total of the items, or of their key: sum "amount" .items
+//	avg [key] list              mean of the same
+//	sort list                   numbers or strings in ascending order
+//	sortBy key list             maps ordered by key
+//	reverse list                items in reverse order
+//	groupBy key list            groups of maps sharing key, in order of first appearance,
+//	                            each a map with the shared "key" and its "items"
+//	dict k1 v1 k2 v2 ...        build a map
+//	list a b ...                build a list
+//
+// Markdown (see markdown.go)
+//
+//	markdown [options] s        convert Markdown to escaped LaTeX; options are a heading
+//	                            command for #, such as "subsection", and "restricted"
+
+// libraryFuncs returns the function library
+func libraryFuncs() template.FuncMap {
+	return template.FuncMap{
+		"upper":    textFunc(strings.ToUpper),
+		"lower":    textFunc(strings.ToLower),
+		"title":    textFunc(title),
+		"trim":     textFunc(strings.TrimSpace),
+		"truncate": truncate,
+		"replace":  func(from, to string, v interface{}) string { return strings.ReplaceAll(toText(v), from, to) },
+		"join":     join,
+
+		"default":  func(fallback, v interface{}) interface{} { return coalesce(v, fallback) },
+		"coalesce": coalesce,
+		"required": required,
+
+		"now":       time.Now,
+		"parseDate": time.Parse,
+		"date":      formatDate,
+
+		"number":   formatNumber,
+		"percent":  formatPercent,
+		"currency": formatCurrency,
+		"add":      fold(func(a, b float64) float64 { return a + b }),
+		"mul":      fold(func(a, b float64) float64 { return a * b }),
+		"sub":      arithmetic(func(a, b float64) (float64, error) { return a - b, nil }),
+		"div":      arithmetic(divide),
+		"mod":      arithmetic(modulo),
+		"round":    round,
+		"min":      fold(math.Min),
+		"max":      fold(math.Max),
+
+		"sum":     sum,
+		"avg":     avg,
+		"sort":    sortList,
+		"sortBy":  sortBy,
+		"reverse": reverse,
+		"groupBy": groupBy,
+		"dict":    dict,
+		"list":    func(items ...interface{}) []interface{} { return items },
+
+		"markdown": markdownFunc,
+	}
+}
+
+// reservedFuncs cannot be replaced by registered functions: escaping relies on them
+var reservedFuncs = map[string]bool{"escape": true, "raw": true, "latex": true}
+
+var funcName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// registry holds the functions registered with RegisterFunc
+var registry = struct {
+	sync.RWMutex
+	funcs template.FuncMap
+}{funcs: template.FuncMap{}}
+
+// RegisterFunc makes fn callable as name from every template
+// fn must return one value, or a value and an error failing the build when non-nil
+// A registered function replaces a library function of the same name
+func RegisterFunc(name string, fn interface{}) error {
+	if !funcName.MatchString(name) {
+		return fmt.Errorf("invalid template function name %q", name)
+	}
+	if reservedFuncs[name] {
+		return fmt.Errorf("template function %q is reserved", name)
+	}
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		return fmt.Errorf("template function %q is a %T, not a function", name, fn)
+	}
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	if t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) {
+		return fmt.Errorf("template function %q must return a value, optionally followed by an error", name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	registry.funcs[name] = fn
+	return nil
+}
+
+// registeredFuncs returns a copy of the registered functions
+func registeredFuncs() template.FuncMap {
+	registry.RLock()
+	defer registry.RUnlock()
+	funcs := make(template.FuncMap, len(registry.funcs))
+	for name, fn := range registry.funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// toText stringifies a value the way printing it would
+func toText(v interface{}) string {
+	s, _ := stringify([]interface{}{v})
+	return s
+}
+
+// textFunc makes a string function take any value, stringified by toText
+func textFunc(fn func(string) string) func(interface{}) string {
+	return func(v interface{}) string { return fn(toText(v)) }
+}
+
+func title(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '-' {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+func truncate(n int, v interface{}) string {
+	s := toText(v)
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return strings.TrimRightFunc(string(runes[:n-3]), unicode.IsSpace) + "..."
+}
+
+func join(sep string, list interface{}) (string, error) {
+	items, err := toList(list)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprint(item)
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// empty reports whether a value counts as missing
+func empty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, v := range values {
+		if !empty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+func required(message string, v interface{}) (interface{}, error) {
+	if empty(v) {
+		return nil, errors.New(message)
+	}
+	return v, nil
+}
+
+// dateLayouts are tried in order to read dates given as strings
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+func formatDate(layout string, v interface{}) (string, error) {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(layout), nil
+	case string:
+		for _, l := range dateLayouts {
+			if t, err := time.Parse(l, val); err == nil {
+				return t.Format(layout), nil
+			}
+		}
+		return "", fmt.Errorf("cannot read %q as a date", val)
+	}
+	if seconds, ok := number(v); ok {
+		return time.Unix(int64(seconds), 0).UTC().Format(layout), nil
+	}
+	return "", fmt.Errorf("cannot format a %T as a date", v)
+}
+
+// toNumber reads numbers and numeric strings
+func toNumber(v interface{}) (float64, error) {
+	if f, ok := number(v); ok {
+		return f, nil
+	}
+	if s, ok := v.(string); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
+
+// result returns whole numbers as int, so they can index lists and print without decimals
+func result(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int(f)
+	}
+	return f
+}
+
+// group formats f with decimals, separating thousands with sep and decimals with point
+func group(f float64, decimals int, sep, point string) string {
+	digits := strconv.FormatFloat(math.Abs(f), 'f', decimals, 64)
+	whole, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		whole, fraction = digits[:i], digits[i+1:]
+	}
+
+	var b strings.Builder
+	if f < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	if fraction != "" {
+		b.WriteString(point + fraction)
+	}
+	return b.String()
+}
+
+func formatNumber(decimals int, v interface{}) (string, error) {
+	f, err := toNumber(v)
+	if err != nil {
+		return "", err
+	}
+	return group(f, decimals, ",", "."), nil
+}
+
+func formatPercent(decimals int, v interface{}) (string, error) {
+	f, err := toNumber(v)
+	if err != nil {
+		return "", err
+	}
+	return group(f*100, decimals, ",", ".") + "%", nil
+}
+
+// currencyFormat is how amounts of a currency are written
+type currencyFormat struct {
+	symbol   string
+	decimals int
+	sep      string
+	point    string
+	suffix   bool // The symbol follows the amount
+}
+
+// currencies maps ISO 4217 codes to their usual format; other codes are written "CHF 1,234.50"
+var currencies = map[string]currencyFormat{
+	"USD": {symbol: "$", decimals: 2, sep: ",", point: "."},
+	"GBP": {symbol: "£", decimals: 2, sep: ",", point: "."},
+	"EUR": {symbol: " €", decimals: 2, sep: ".", point: ",", suffix: true},
+	"BRL": {symbol: "R$ ", decimals: 2, sep: ".", point: ","},
+	"JPY": {symbol: "¥", decimals: 0, sep: ",", point: "."},
+}
+
+func formatCurrency(code string, v interface{}) (string, error) {
+	f, err := toNumber(v)
+	if err != nil {
+		return "", err
+	}
+	code = strings.ToUpper(code)
+	format, ok := currencies[code]
+	if !ok {
+		format = currencyFormat{symbol: code + " ", decimals: 2, sep: ",", point: "."}
+	}
+	amount := group(f, format.decimals, format.sep, format.point)
+	if format.suffix {
+		return amount + format.symbol, nil
+	}
+	if strings.HasPrefix(amount, "-") {
+		return "-" + format.symbol + amount[1:], nil
+	}
+	return format.symbol + amount, nil
+}
+
+// fold applies op to all its arguments from left to right
+func fold(op func(a, b float64) float64) func(interface{}, ...interface{}) (interface{}, error) {
+	return func(first interface{}, rest ...interface{}) (interface{}, error) {
+		acc, err := toNumber(first)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range rest {
+			f, err := toNumber(v)
+			if err != nil {
+				return nil, err
+			}
+			acc = op(acc, f)
+		}
+		return result(acc), nil
+	}
+}
+
+// arithmetic lifts a binary operation to template values
+func arithmetic(op func(a, b float64) (float64, error)) func(a, b interface{}) (interface{}, error) {
+	return func(a, b interface{}) (interface{}, error) {
+		x, err := toNumber(a)
+		if err != nil {
+			return nil, err
+		}
+		y, err := toNumber(b)
+		if err != nil {
+			return nil, err
+		}
+		f, err := op(x, y)
+		if err != nil {
+			return nil, err
+		}
+		return result(f), nil
+	}
+}
+
+func divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+
+func modulo(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return math.Mod(a, b), nil
+}
+
+func round(decimals int, v interface{}) (interface{}, error) {
+	f, err := toNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	scale := math.Pow(10, float64(decimals))
+	return result(math.Round(f*scale) / scale), nil
+}
+
+// toList reads any slice or array as a list
+func toList(v interface{}) ([]interface{}, error) {
+	if items, ok := v.([]interface{}); ok {
+		return items, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list, got %T", v)
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// field returns the value of a dotted key in a map
+func field(item interface{}, key string) (interface{}, error) {
+	v := item
+	for _, part := range strings.Split(key, ".") {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot read %q from a %T", key, item)
+		}
+		value := rv.MapIndex(reflect.ValueOf(part).Convert(rv.Type().Key()))
+		if !value.IsValid() {
+			return nil, fmt.Errorf("item has no %q", key)
+		}
+		v = value.Interface()
+	}
+	return v, nil
+}
+
+// values returns the items of a list, or the values of their key when one is given
+func values(args []interface{}) ([]interface{}, error) {
+	switch len(args) {
+	case 1:
+		return toList(args[0])
+	case 2:
+		key, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("key must be a string, got %T", args[0])
+		}
+		items, err := toList(args[1])
+		if err != nil {
+			return nil, err
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			if out[i], err = field(item, key); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("expected a list and an optional key, got %d arguments", len(args))
+}
+
+func total(args []interface{}) (float64, int, error) {
+	items, err := values(args)
+	if err != nil {
+		return 0, 0, err
+	}
+	var sum float64
+	for _, item := range items {
+		f, err := toNumber(item)
+		if err != nil {
+			return 0, 0, err
+		}
+		sum += f
+	}
+	return sum, len(items), nil
+}
+
+func sum(args ...interface{}) (interface{}, error) {
+	s, _, err := total(args)
+	if err != nil {
+		return nil, err
+	}
+	return result(s), nil
+}
+
+func avg(args ...interface{}) (interface{}, error) {
+	s, n, err := total(args)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	return result(s / float64(n)), nil
+}
+
+// sortByValue orders items by the value keyOf returns for each
+func sortByValue(items []interface{}, keyOf func(interface{}) (interface{}, error)) ([]interface{}, error) {
+	keys := make([]interface{}, len(items))
+	for i, item := range items {
+		k, err := keyOf(item)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = k
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	var sortErr error
+	sort.SliceStable(indexes, func(i, j int) bool {
+		c, err := compare(keys[indexes[i]], keys[indexes[j]])
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	sorted := make([]interface{}, len(items))
+	for i, idx := range indexes {
+		sorted[i] = items[idx]
+	}
+	return sorted, nil
+}
+
+func sortList(list interface{}) ([]interface{}, error) {
+	items, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	return sortByValue(items, func(item interface{}) (interface{}, error) { return item, nil })
+}
+
+func sortBy(key string, list interface{}) ([]interface{}, error) {
+	items, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	return sortByValue(items, func(item interface{}) (interface{}, error) { return field(item, key) })
+}
+
+func reverse(list interface{}) ([]interface{}, error) {
+	items, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	reversed := make([]interface{}, len(items))
+	for i, item := range items {
+		reversed[len(items)-1-i] = item
+	}
+	return reversed, nil
+}
+
+func groupBy(key string, list interface{}) ([]interface{}, error) {
+	items, err := toList(list)
+	if err != nil {
+		return nil, err
+	}
+	var groups []interface{}
+	index := make(map[string]map[string]interface{})
+	for _, item := range items {
+		k, err := field(item, key)
+		if err != nil {
+			return nil, err
+		}
+		id := fmt.Sprint(k)
+		g, ok := index[id]
+		if !ok {
+			g = map[string]interface{}{"key": k, "items": []interface{}{}}
+			index[id] = g
+			groups = append(groups, g)
+		}
+		g["items"] = append(g["items"].([]interface{}), item)
+	}
+	return groups, nil
+}
+
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict expects key and value pairs")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
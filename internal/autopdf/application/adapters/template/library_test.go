@@ -0,0 +1,108 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryFuncs(t *testing.T) {
+	data := map[string]interface{}{
+		"name":    "  ada lovelace  ",
+		"missing": "",
+		"tags":    []interface{}{"b", "a", "c"},
+		"issued":  "2025-03-07",
+		"total":   1234567.891,
+		"rate":    0.125,
+		"count":   7,
+		"items": []interface{}{
+			map[string]interface{}{"name": "Design", "kind": "work", "qty": 2, "price": 150.5},
+			map[string]interface{}{"name": "Hosting", "kind": "service", "qty": 1, "price": 40},
+			map[string]interface{}{"name": "Review", "kind": "work", "qty": 3, "price": 20},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"case", `delim[[.name | trim | title]] delim[[upper "x"]] delim[[lower "Y"]]`, `Ada Lovelace X y`},
+		{"truncate", `delim[[truncate 10 "A rather long title"]]`, `A rathe...`},
+		{"replace", `delim[[replace "-" " " "a-b-c"]]`, `a b c`},
+		{"numeric upper", `delim[[upper .rate]] delim[[upper 1e21]]`, `0.125 1E+21`},
+		{"numeric lower", `delim[[lower .count]]`, `7`},
+		{"numeric title", `delim[[title .count]]`, `7`},
+		{"numeric trim", `delim[[trim .rate]]`, `0.125`},
+		{"numeric truncate", `delim[[truncate 5 1234567]]`, `12...`},
+		{"numeric replace", `delim[[replace "." "," .rate]]`, `0,125`},
+		{"numeric escape", `delim[[escape .count]] delim[[escape .rate]]`, `7 0.125`},
+		{"join", `delim[[join ", " .tags]]`, `b, a, c`},
+		{"default", `delim[[.missing | default "n/a"]] delim[[.nothing | default "n/a"]] delim[[default 1 0]]`, `n/a n/a 0`},
+		{"coalesce", `delim[[coalesce .missing .nothing "third"]]`, `third`},
+		{"date", `delim[[date "02/01/2006" .issued]] delim[[date "January 2, 2006" (parseDate "2006-01-02" "2024-12-25")]]`, `07/03/2025 December 25, 2024`},
+		{"unix date", `delim[[date "2006-01-02" 86400]]`, `1970-01-02`},
+		{"number", `delim[[number 2 .total]] delim[[number 0 -999.5]] delim[[number 1 "12"]]`, `1,234,567.89 -1,000 12.0`},
+		{"percent", `delim[[percent 1 .rate]]`, `12.5\%`},
+		{"currency", `delim[[currency "USD" .total]] delim[[.total | currency "BRL"]] delim[[currency "eur" -5]] delim[[currency "JPY" 1500]] delim[[currency "CHF" 3]]`,
+			`\$1,234,567.89 R\$ 1.234.567,89 -5,00 € ¥1,500 CHF 3.00`},
+		{"arithmetic", `delim[[add 1 2 3]] delim[[sub 10 2.5]] delim[[mul 2 "3"]] delim[[div 7 2]] delim[[mod 7 3]] delim[[round 2 3.14159]]`, `6 7.5 6 3.5 1 3.14`},
+		{"min max", `delim[[min 3 1 2]] delim[[max 3 1 2]]`, `1 3`},
+		{"whole results index lists", `delim[[index .tags (sub 2 1)]]`, `a`},
+		{"sum avg", `delim[[sum "qty" .items]] delim[[avg "qty" .items]] delim[[sum (list 1 2.5)]]`, `6 2 3.5`},
+		{"invoice total", `delim[[$total := 0]]delim[[range .items]]delim[[$total = add $total (mul .qty .price)]]delim[[end]]delim[[number 2 $total]]`, `401.00`},
+		{"sort", `delim[[join "" (sort .tags)]] delim[[join "" (.tags | sort | reverse)]]`, `abc cba`},
+		{"sortBy", `delim[[range sortBy "price" .items]]delim[[.name]] delim[[end]]`, `Review Hosting Design `},
+		{"groupBy", `delim[[range groupBy "kind" .items]]delim[[.key]]:delim[[len .items]] delim[[end]]`, `work:2 service:1 `},
+		{"dict", `delim[[with dict "a" 1 "b" (list "x" "y")]]delim[[.a]]delim[[index .b 1]]delim[[end]]`, `1y`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Execute("test", tt.template, data, LaTeXDialect)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestLibraryFuncs_Errors(t *testing.T) {
+	for _, tmpl := range []string{
+		`delim[[required "a client name is needed" .client]]`,
+		`delim[[div 1 0]]`,
+		`delim[[number 2 "ten"]]`,
+		`delim[[sum "amount" .items]]`,
+		`delim[[date "2006" "yesterday"]]`,
+		`delim[[dict "a"]]`,
+	} {
+		_, err := Execute("test", tmpl, map[string]interface{}{"items": []interface{}{map[string]interface{}{"qty": 1}}}, LaTeXDialect)
+		assert.Error(t, err, tmpl)
+	}
+
+	_, err := Execute("test", `delim[[required "a client name is needed" .client]]`, nil, LaTeXDialect)
+	assert.ErrorContains(t, err, "a client name is needed")
+}
+
+func TestRegisterFunc(t *testing.T) {
+	require.NoError(t, RegisterFunc("shout", func(s string) string { return strings.ToUpper(s) + "!" }))
+	t.Cleanup(func() {
+		registry.Lock()
+		delete(registry.funcs, "shout")
+		registry.Unlock()
+	})
+
+	out, err := Execute("test", `delim[[shout "hi & bye"]]`, nil, LaTeXDialect)
+	require.NoError(t, err)
+	assert.Equal(t, `HI \& BYE!`, out, "registered functions are escaped like any value")
+
+	assert.Error(t, RegisterFunc("raw", func(s string) string { return s }))
+	assert.Error(t, RegisterFunc("bad name", func() string { return "" }))
+	assert.Error(t, RegisterFunc("notFunc", "value"))
+	assert.Error(t, RegisterFunc("noResult", func() {}))
+	assert.Error(t, RegisterFunc("badError", func() (string, string) { return "", "" }))
+}
@@ -0,0 +1,712 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+)
+
+// Lint checks a template without rendering it. It walks the parsed template from its
+// root through the partials it includes and reports, as file:line diagnostics:
+//   - syntax errors, calls to functions that are not defined and to templates that are not
+//   - variables the template uses and vars does not define, and those vars defines and the
+//     template never uses
+//   - range over values that are neither lists nor maps
+//   - for LaTeX, environments and braces left open or closed twice, outside comments and
+//     verbatim text
+//
+// Variables tested by if and with, or given to default, coalesce, and, or and not, are
+// expected to be optional and not reported missing. Without vars, the checks depending
+// on the variables' values are skipped
+func Lint(name, content string, dialect Dialect, partials []Partial, vars *LintVariables) []ports.Diagnostic {
+	l := newLinter(dialect, vars)
+	if err := l.load(name, content, partials); err != nil {
+		return l.diagnostics
+	}
+	l.run(name)
+	l.checkUnused()
+	if dialect.Name == LaTeXDialect.Name {
+		walked := make([]string, 0, len(l.walked))
+		for parseName := range l.walked {
+			walked = append(walked, parseName)
+		}
+		sort.Strings(walked)
+		for _, parseName := range walked {
+			if f := l.files[parseName]; f != nil {
+				l.checkStructure(f, f.text())
+			}
+		}
+	}
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		a, b := l.diagnostics[i], l.diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return l.diagnostics
+}
+
+// ReferencedVariables returns the names of the variables content refers to, directly or
+// through the partials it includes, in the order they are first referred to
+func ReferencedVariables(name, content string, dialect Dialect, partials []Partial) ([]string, error) {
+	l := newLinter(dialect, nil)
+	if err := l.load(name, content, partials); err != nil {
+		return nil, err
+	}
+	l.run(name)
+	return l.order, nil
+}
+
+// LintVariables are the variables a template is linted against
+type LintVariables struct {
+	Data  map[string]interface{}
+	File  string         // File defining the variables, such as the config
+	Lines map[string]int // Line of each variable in File, when known
+}
+
+// builtinFuncs are the functions text/template predefines
+var builtinFuncs = map[string]bool{
+	"and": true, "call": true, "html": true, "index": true, "slice": true, "js": true,
+	"len": true, "not": true, "or": true, "print": true, "printf": true, "println": true,
+	"urlquery": true, "eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+}
+
+// optionalFuncs are given variables that may be missing
+var optionalFuncs = map[string]bool{"default": true, "coalesce": true, "and": true, "or": true, "not": true}
+
+// maxCallDepth bounds the walk through templates calling each other
+const maxCallDepth = 32
+
+// lintFile is a file of the template set being linted
+type lintFile struct {
+	path    string
+	content string // As parsed, with its magic comment blanked
+	trees   map[string]*parse.Tree
+}
+
+// lintValue is what the linter knows of the value of an expression
+type lintValue struct {
+	root   bool          // The variables themselves
+	path   string        // Variable path the value is reached through, such as items[].name
+	known  bool          // values holds every value the expression can take
+	values []interface{} // Several for the elements of a range
+}
+
+// lintScope is the dot and the template variables at a point of the walk
+type lintScope struct {
+	dot     lintValue
+	vars    map[string]lintValue
+	guarded map[string]bool // Paths an enclosing if tested, which may be missing below it
+}
+
+// inner returns the scope of a nested block, whose declarations do not leak out of it
+func (s *lintScope) inner() *lintScope {
+	inner := &lintScope{
+		dot:     s.dot,
+		vars:    make(map[string]lintValue, len(s.vars)),
+		guarded: make(map[string]bool, len(s.guarded)),
+	}
+	for k, v := range s.vars {
+		inner.vars[k] = v
+	}
+	for path := range s.guarded {
+		inner.guarded[path] = true
+	}
+	return inner
+}
+
+// isGuarded reports whether names, resolved from v, is or is below a guarded path
+func (s *lintScope) isGuarded(v lintValue, names []string) bool {
+	path := v.path
+	for _, name := range names {
+		if path != "" {
+			path += "."
+		}
+		path += name
+		if s.guarded[path] {
+			return true
+		}
+	}
+	return false
+}
+
+type linter struct {
+	dialect     Dialect
+	funcs       template.FuncMap
+	vars        *LintVariables
+	files       map[string]*lintFile   // By parse name: the template's name or a partial's
+	trees       map[string]*parse.Tree // The set as executed, defines overriding blocks
+	walked      map[string]bool        // Parse names of the files the walk went through
+	visited     map[string]bool
+	depth       int
+	used        map[string]bool // Variables the template refers to
+	order       []string
+	rootEscapes bool      // The variables reach a function whole, so any may be used
+	tested      *[]string // Collects the paths an if condition refers to
+	seen        map[string]bool
+	diagnostics []ports.Diagnostic
+}
+
+func newLinter(dialect Dialect, vars *LintVariables) *linter {
+	return &linter{
+		dialect: dialect,
+		funcs:   allFuncs(dialect),
+		vars:    vars,
+		files:   make(map[string]*lintFile),
+		trees:   make(map[string]*parse.Tree),
+		walked:  make(map[string]bool),
+		visited: make(map[string]bool),
+		used:    make(map[string]bool),
+		seen:    make(map[string]bool),
+	}
+}
+
+// parseError matches the location text/template/parse prefixes its errors with
+var parseError = regexp.MustCompile(`(?s)^template: (.+?):(\d+): (.*)$`)
+
+// load parses the partials and the template, reporting syntax errors
+// Functions are not checked while parsing, so every unknown one is reported by the walk
+func (l *linter) load(name, content string, partials []Partial) error {
+	var firstErr error
+	add := func(parseName, path, content string) {
+		dialect, content := l.dialect.ForContent(content)
+		f := &lintFile{path: path, content: content, trees: make(map[string]*parse.Tree)}
+		l.files[parseName] = f
+
+		t := parse.New(parseName)
+		t.Mode = parse.SkipFuncCheck
+		if _, err := t.Parse(content, dialect.LeftDelim, dialect.RightDelim, f.trees); err != nil {
+			l.reportSyntax(f, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		for treeName, tree := range f.trees {
+			// As in text/template, an empty definition does not replace a template
+			if old := l.trees[treeName]; old != nil && parse.IsEmptyTree(tree.Root) {
+				continue
+			}
+			l.trees[treeName] = tree
+		}
+	}
+	for _, p := range partials {
+		add(p.Name, p.Path, p.Content)
+	}
+	add(name, name, content)
+	return firstErr
+}
+
+func (l *linter) reportSyntax(f *lintFile, err error) {
+	d := ports.Diagnostic{
+		Kind:     ports.DiagnosticTemplateSyntax,
+		Severity: ports.SeverityError,
+		File:     f.path,
+		Message:  err.Error(),
+	}
+	if m := parseError.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Message = m[3]
+		d.Context = lineText(f.content, d.Line)
+	}
+	l.add(d)
+}
+
+// run walks the template named name with the variables as dot
+func (l *linter) run(name string) {
+	root := lintValue{root: true}
+	if l.vars != nil {
+		data := l.vars.Data
+		if data == nil {
+			data = map[string]interface{}{}
+		}
+		root.known, root.values = true, []interface{}{data}
+	}
+	l.call(name, root)
+}
+
+// call walks the template named name with dot
+func (l *linter) call(name string, dot lintValue) {
+	key := name + "\x00" + dot.path
+	if l.visited[key] || l.depth >= maxCallDepth {
+		return
+	}
+	l.visited[key] = true
+	t := l.trees[name]
+	if t == nil || t.Root == nil {
+		return
+	}
+	l.walked[t.ParseName] = true
+
+	l.depth++
+	defer func() { l.depth-- }()
+	l.walk(t.Root, &lintScope{dot: dot, vars: map[string]lintValue{"$": dot}, guarded: map[string]bool{}}, t)
+}
+
+func (l *linter) walk(node parse.Node, s *lintScope, tree *parse.Tree) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			l.walk(child, s, tree)
+		}
+	case *parse.ActionNode:
+		v := l.pipe(n.Pipe, s, tree, false)
+		if len(n.Pipe.Decl) == 0 && v.root {
+			l.rootEscapes = true
+		}
+		for _, decl := range n.Pipe.Decl {
+			s.vars[decl.Ident[0]] = v
+		}
+	case *parse.IfNode:
+		inner := s.inner()
+		var tested []string
+		l.tested = &tested
+		v := l.pipe(n.Pipe, s, tree, true)
+		l.tested = nil
+		// What the condition tests is there in the branch it guards
+		for _, path := range tested {
+			inner.guarded[path] = true
+		}
+		for _, decl := range n.Pipe.Decl {
+			inner.vars[decl.Ident[0]] = v
+		}
+		l.walk(n.List, inner, tree)
+		l.walk(n.ElseList, s.inner(), tree)
+	case *parse.WithNode:
+		inner := s.inner()
+		inner.dot = l.pipe(n.Pipe, s, tree, true)
+		for _, decl := range n.Pipe.Decl {
+			inner.vars[decl.Ident[0]] = inner.dot
+		}
+		l.walk(n.List, inner, tree)
+		l.walk(n.ElseList, s.inner(), tree)
+	case *parse.RangeNode:
+		elem := l.element(l.pipe(n.Pipe, s, tree, false), n.Pipe.Pos, tree)
+		inner := s.inner()
+		inner.dot = elem
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner.vars[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			inner.vars[n.Pipe.Decl[0].Ident[0]] = lintValue{}
+			inner.vars[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		l.walk(n.List, inner, tree)
+		l.walk(n.ElseList, s.inner(), tree)
+	case *parse.TemplateNode:
+		dot := l.pipe(n.Pipe, s, tree, false)
+		if l.trees[n.Name] == nil {
+			l.report(tree, n.Pos, ports.DiagnosticTemplateSyntax, ports.SeverityError,
+				fmt.Sprintf("template %q is not defined", n.Name))
+			return
+		}
+		l.call(n.Name, dot)
+	}
+}
+
+// pipe evaluates a pipeline, whose commands are optional when the next one is given
+// variables that may be missing, as in .note | default ""
+func (l *linter) pipe(p *parse.PipeNode, s *lintScope, tree *parse.Tree, optional bool) lintValue {
+	if p == nil {
+		return lintValue{}
+	}
+	var v lintValue
+	for i, cmd := range p.Cmds {
+		next := i+1 < len(p.Cmds) && optionalFuncs[calledFunc(p.Cmds[i+1])]
+		piped := v
+		v = l.command(cmd, s, tree, optional || next)
+		if i > 0 && piped.root && calledFunc(cmd) != "" {
+			l.rootEscapes = true
+		}
+	}
+	return v
+}
+
+func calledFunc(cmd *parse.CommandNode) string {
+	if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		return id.Ident
+	}
+	return ""
+}
+
+func (l *linter) command(cmd *parse.CommandNode, s *lintScope, tree *parse.Tree, optional bool) lintValue {
+	if fn := calledFunc(cmd); fn != "" {
+		l.checkFunc(cmd.Args[0].(*parse.IdentifierNode), tree)
+		for _, arg := range cmd.Args[1:] {
+			if l.arg(arg, s, tree, optional || optionalFuncs[fn]).root {
+				l.rootEscapes = true
+			}
+		}
+		return lintValue{}
+	}
+	v := l.arg(cmd.Args[0], s, tree, optional)
+	for _, arg := range cmd.Args[1:] {
+		l.arg(arg, s, tree, optional)
+	}
+	return v
+}
+
+func (l *linter) arg(node parse.Node, s *lintScope, tree *parse.Tree, optional bool) lintValue {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return s.dot
+	case *parse.FieldNode:
+		return l.fields(s.dot, n.Ident, n.Pos, tree, optional || s.isGuarded(s.dot, n.Ident))
+	case *parse.VariableNode:
+		v := s.vars[n.Ident[0]]
+		return l.fields(v, n.Ident[1:], n.Pos, tree, optional || s.isGuarded(v, n.Ident[1:]))
+	case *parse.ChainNode:
+		return l.fields(l.arg(n.Node, s, tree, optional), n.Field, n.Pos, tree, optional)
+	case *parse.PipeNode:
+		return l.pipe(n, s, tree, optional)
+	case *parse.IdentifierNode:
+		l.checkFunc(n, tree)
+	}
+	return lintValue{}
+}
+
+func (l *linter) checkFunc(id *parse.IdentifierNode, tree *parse.Tree) {
+	if _, ok := l.funcs[id.Ident]; ok || builtinFuncs[id.Ident] {
+		return
+	}
+	l.report(tree, id.Pos, ports.DiagnosticUnknownFunction, ports.SeverityError,
+		fmt.Sprintf("function %q is not defined", id.Ident))
+}
+
+// fields resolves a chain of field names from v, reporting the first one that is missing
+func (l *linter) fields(v lintValue, names []string, pos parse.Pos, tree *parse.Tree, optional bool) lintValue {
+	for _, name := range names {
+		v = l.field(v, name, pos, tree, optional)
+		if !v.known && !v.root {
+			// Nothing is known below an unknown value
+			optional = true
+		}
+	}
+	return v
+}
+
+func (l *linter) field(v lintValue, name string, pos parse.Pos, tree *parse.Tree, optional bool) lintValue {
+	if v.root {
+		if name == legacyRoot && !l.defines(legacyRoot) {
+			return v
+		}
+		if !l.used[name] {
+			l.used[name] = true
+			l.order = append(l.order, name)
+		}
+	}
+	path := name
+	if v.path != "" {
+		path = v.path + "." + name
+	}
+	if l.tested != nil {
+		*l.tested = append(*l.tested, path)
+	}
+
+	out := lintValue{path: path}
+	if !v.known {
+		return out
+	}
+	var values []interface{}
+	for _, candidate := range v.values {
+		m, ok := candidate.(map[string]interface{})
+		if !ok {
+			return out
+		}
+		if value, ok := m[name]; ok {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		if !optional && len(v.values) > 0 {
+			l.report(tree, pos, ports.DiagnosticUndefinedVariable, ports.SeverityWarning,
+				fmt.Sprintf("variable %q is not defined", path))
+		}
+		return out
+	}
+	out.known, out.values = true, values
+	return out
+}
+
+// defines reports whether the variables define name
+func (l *linter) defines(name string) bool {
+	if l.vars == nil {
+		return false
+	}
+	_, ok := l.vars.Data[name]
+	return ok
+}
+
+// element returns the value of the elements range iterates over, reporting values it cannot range over
+func (l *linter) element(v lintValue, pos parse.Pos, tree *parse.Tree) lintValue {
+	elem := lintValue{path: v.path + "[]"}
+	if v.root {
+		// Every variable is ranged over
+		l.rootEscapes = true
+		return elem
+	}
+	if !v.known {
+		return elem
+	}
+	var values []interface{}
+	for _, candidate := range v.values {
+		rv := reflect.ValueOf(candidate)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				values = append(values, rv.Index(i).Interface())
+			}
+		case reflect.Map:
+			iter := rv.MapRange()
+			for iter.Next() {
+				values = append(values, iter.Value().Interface())
+			}
+		case reflect.Invalid:
+			// A nil value ranges over nothing
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			// Ranging over an integer counts up to it
+			return elem
+		default:
+			l.report(tree, pos, ports.DiagnosticRangeNonList, ports.SeverityError,
+				fmt.Sprintf("range over %q, a %T rather than a list", v.path, candidate))
+			return elem
+		}
+	}
+	elem.known, elem.values = true, values
+	return elem
+}
+
+// checkUnused reports the variables the template never refers to
+func (l *linter) checkUnused() {
+	if l.vars == nil || l.rootEscapes {
+		return
+	}
+	names := make([]string, 0, len(l.vars.Data))
+	for name := range l.vars.Data {
+		if !l.used[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		l.add(ports.Diagnostic{
+			Kind:     ports.DiagnosticUnusedVariable,
+			Severity: ports.SeverityWarning,
+			File:     l.vars.File,
+			Line:     l.vars.Lines[name],
+			Message:  fmt.Sprintf("variable %q is not used by the template", name),
+		})
+	}
+}
+
+// text returns the file's content with its actions blanked out, keeping every offset and line
+func (f *lintFile) text() string {
+	buf := []byte(f.content)
+	for i := range buf {
+		if buf[i] != '\n' {
+			buf[i] = ' '
+		}
+	}
+	for _, t := range f.trees {
+		copyText(t.Root, buf)
+	}
+	return string(buf)
+}
+
+func copyText(node parse.Node, buf []byte) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			copyText(child, buf)
+		}
+	case *parse.TextNode:
+		copy(buf[n.Pos:], n.Text)
+	case *parse.IfNode:
+		copyText(n.List, buf)
+		copyText(n.ElseList, buf)
+	case *parse.RangeNode:
+		copyText(n.List, buf)
+		copyText(n.ElseList, buf)
+	case *parse.WithNode:
+		copyText(n.List, buf)
+		copyText(n.ElseList, buf)
+	}
+}
+
+// checkStructure reports the LaTeX environments and braces of text that are not balanced,
+// skipping comments and verbatim text
+func (l *linter) checkStructure(f *lintFile, text string) {
+	type environment struct {
+		name string
+		pos  int
+	}
+	var braces []int
+	var envs []environment
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '%':
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return
+			}
+			i += end
+		case '{':
+			braces = append(braces, i)
+		case '}':
+			if len(braces) == 0 {
+				l.reportAt(f, i, ports.DiagnosticUnbalancedBrace, "closing brace without an opening one")
+				continue
+			}
+			braces = braces[:len(braces)-1]
+		case '\\':
+			start := i + 1
+			end := start
+			for end < len(text) && isLetter(text[end]) {
+				end++
+			}
+			if end == start {
+				// A control symbol such as \{ or \%
+				i++
+				continue
+			}
+			name := text[start:end]
+			i = end - 1
+
+			switch {
+			case name == "begin" || name == "end":
+				m := beginPattern.FindStringSubmatch(text[end:])
+				if m == nil {
+					continue
+				}
+				env := m[1]
+				i = end + len(m[0]) - 1
+				if name == "end" {
+					if len(envs) == 0 {
+						l.reportAt(f, start-1, ports.DiagnosticUnbalancedEnvironment,
+							fmt.Sprintf(`\end{%s} without a matching \begin{%s}`, env, env))
+						continue
+					}
+					open := envs[len(envs)-1]
+					envs = envs[:len(envs)-1]
+					if open.name != env {
+						l.reportAt(f, open.pos, ports.DiagnosticUnbalancedEnvironment,
+							fmt.Sprintf(`\begin{%s} is ended by \end{%s} on line %d`, open.name, env, lineOf(f.content, start)))
+					}
+					continue
+				}
+				if !isVerbatimEnv(env) {
+					envs = append(envs, environment{name: env, pos: start - 1})
+					continue
+				}
+				closing := `\end{` + env + `}`
+				idx := strings.Index(text[i+1:], closing)
+				if idx < 0 {
+					l.reportAt(f, start-1, ports.DiagnosticUnbalancedEnvironment, fmt.Sprintf(`\begin{%s} is never ended`, env))
+					return
+				}
+				i += idx + len(closing)
+			case verbCommands[name]:
+				j := end
+				for j < len(text) && text[j] == '*' {
+					j++
+				}
+				if j >= len(text) {
+					continue
+				}
+				delim := text[j]
+				if delim == '{' {
+					delim = '}'
+				}
+				if idx := strings.IndexByte(text[j+1:], delim); idx >= 0 {
+					i = j + 1 + idx
+				}
+			}
+		}
+	}
+
+	for _, pos := range braces {
+		l.reportAt(f, pos, ports.DiagnosticUnbalancedBrace, "opening brace is never closed")
+	}
+	for _, env := range envs {
+		l.reportAt(f, env.pos, ports.DiagnosticUnbalancedEnvironment, fmt.Sprintf(`\begin{%s} is never ended`, env.name))
+	}
+}
+
+func isVerbatimEnv(name string) bool {
+	for _, env := range verbatimEnvs {
+		if env == name {
+			return true
+		}
+	}
+	return false
+}
+
+// report adds a diagnostic at a position of tree's file
+func (l *linter) report(tree *parse.Tree, pos parse.Pos, kind ports.DiagnosticKind, severity ports.DiagnosticSeverity, message string) {
+	d := ports.Diagnostic{Kind: kind, Severity: severity, Message: message}
+	if f := l.files[tree.ParseName]; f != nil {
+		d.File = f.path
+		d.Line = lineOf(f.content, int(pos))
+		d.Context = lineText(f.content, d.Line)
+	}
+	l.add(d)
+}
+
+// reportAt adds a LaTeX structure error at an offset of f
+func (l *linter) reportAt(f *lintFile, offset int, kind ports.DiagnosticKind, message string) {
+	line := lineOf(f.content, offset)
+	l.add(ports.Diagnostic{
+		Kind:     kind,
+		Severity: ports.SeverityError,
+		File:     f.path,
+		Line:     line,
+		Message:  message,
+		Context:  lineText(f.content, line),
+	})
+}
+
+// add records a diagnostic once, as a partial included twice is walked twice
+func (l *linter) add(d ports.Diagnostic) {
+	key := string(d.Kind) + "\x00" + d.String()
+	if l.seen[key] {
+		return
+	}
+	l.seen[key] = true
+	l.diagnostics = append(l.diagnostics, d)
+}
+
+// lineOf returns the 1-based line of an offset of content
+func lineOf(content string, offset int) int {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+// lineText returns a line of content, trimmed
+func lineText(content string, line int) string {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
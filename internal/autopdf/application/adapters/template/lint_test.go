@@ -0,0 +1,137 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lintSummary formats diagnostics as "file:line kind" for comparison
+func lintSummary(diagnostics []ports.Diagnostic) []string {
+	var out []string
+	for _, d := range diagnostics {
+		out = append(out, d.String()+" "+string(d.Kind))
+	}
+	return out
+}
+
+func TestLint_Variables(t *testing.T) {
+	content := `\documentclass{article}
+\begin{document}
+delim[[.title]] delim[[.subtitle]]
+delim[[if .note]]delim[[.note]]delim[[end]] delim[[.footer | default "-"]]
+delim[[range .items]]delim[[.name]] delim[[.price]]delim[[end]]
+delim[[range .author]]x delim[[end]]
+delim[[with .client]]delim[[.name]] delim[[.vat]]delim[[end]]
+delim[[.vars.title]]
+\end{document}
+`
+	vars := &LintVariables{
+		Data: map[string]interface{}{
+			"title":  "Invoice",
+			"unused": true,
+			"items": []interface{}{
+				map[string]interface{}{"name": "A", "price": 1},
+				map[string]interface{}{"name": "B"},
+			},
+			"author": "Ann",
+			"client": map[string]interface{}{"name": "ACME"},
+		},
+		File:  "autopdf.yaml",
+		Lines: map[string]int{"unused": 7},
+	}
+
+	diagnostics := Lint("letter.tex", content, LaTeXDialect, nil, vars)
+	assert.Equal(t, []string{
+		`autopdf.yaml:7: variable "unused" is not used by the template unused_variable`,
+		`letter.tex:3: variable "subtitle" is not defined undefined_variable`,
+		`letter.tex:6: range over "author", a string rather than a list range_non_list`,
+		`letter.tex:7: variable "client.vat" is not defined undefined_variable`,
+	}, lintSummary(diagnostics))
+	assert.Equal(t, "delim[[.title]] delim[[.subtitle]]", diagnostics[1].Context)
+	assert.Equal(t, ports.SeverityWarning, diagnostics[1].Severity)
+	assert.Equal(t, ports.SeverityError, diagnostics[2].Severity)
+}
+
+func TestLint_WithoutVariables(t *testing.T) {
+	diagnostics := Lint("letter.tex", `delim[[.title]] delim[[range .items]]delim[[.name]]delim[[end]]`, LaTeXDialect, nil, nil)
+	assert.Empty(t, diagnostics)
+}
+
+func TestLint_WholeDataSuppressesUnused(t *testing.T) {
+	vars := &LintVariables{Data: map[string]interface{}{"a": 1, "b": 2}}
+	assert.Empty(t, Lint("t.typ", `{{ printf "%v" . }}`, TypstDialect, nil, vars))
+}
+
+func TestLint_Functions(t *testing.T) {
+	diagnostics := Lint("t.typ", "{{ .a | upper }}\n{{ shout .a }}\n{{ template \"missing\" . }}", TypstDialect, nil, nil)
+	assert.Equal(t, []string{
+		`t.typ:2: function "shout" is not defined unknown_function`,
+		`t.typ:3: template "missing" is not defined template_syntax`,
+	}, lintSummary(diagnostics))
+}
+
+func TestLint_Syntax(t *testing.T) {
+	diagnostics := Lint("t.typ", "= Title\n{{ end }}\n", TypstDialect, nil, nil)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, ports.DiagnosticTemplateSyntax, diagnostics[0].Kind)
+	assert.Equal(t, "t.typ", diagnostics[0].File)
+	assert.Equal(t, 2, diagnostics[0].Line)
+}
+
+func TestLint_Partials(t *testing.T) {
+	partials := []Partial{
+		{Name: "address", Path: "partials/address.tex", Content: "delim[[.street]]\n\\textbf{delim[[.zip]]"},
+		{Name: "unused", Path: "partials/unused.tex", Content: "\\end{itemize} delim[[.nope]]"},
+	}
+	vars := &LintVariables{Data: map[string]interface{}{
+		"client": map[string]interface{}{"street": "Main St"},
+	}}
+
+	diagnostics := Lint("letter.tex", `delim[[template "address" .client]]`, LaTeXDialect, partials, vars)
+	assert.Equal(t, []string{
+		`partials/address.tex:2: variable "client.zip" is not defined undefined_variable`,
+		`partials/address.tex:2: opening brace is never closed unbalanced_brace`,
+	}, lintSummary(diagnostics))
+}
+
+func TestLint_LaTeXStructure(t *testing.T) {
+	content := `\begin{document}
+% a comment } \begin{itemize}
+\{ \verb|}| \section{delim[[.title]]}
+\begin{verbatim}
+\end{itemize} }
+\end{verbatim}
+\begin{itemize}
+\end{enumerate}
+}
+\end{document}
+\end{document}
+`
+	diagnostics := Lint("doc.tex", content, LaTeXDialect, nil, nil)
+	assert.Equal(t, []string{
+		`doc.tex:7: \begin{itemize} is ended by \end{enumerate} on line 8 unbalanced_environment`,
+		`doc.tex:9: closing brace without an opening one unbalanced_brace`,
+		`doc.tex:11: \end{document} without a matching \begin{document} unbalanced_environment`,
+	}, lintSummary(diagnostics))
+
+	// Typst templates are not checked for LaTeX structure
+	assert.Empty(t, Lint("doc.typ", `\begin{x} {`, TypstDialect, nil, nil))
+}
+
+func TestReferencedVariables(t *testing.T) {
+	partials := []Partial{{Name: "sign", Path: "sign.tex", Content: "delim[[$.author]]"}}
+	content := `delim[[range .items]]delim[[.name]]delim[[end]] delim[[.title | upper]] delim[[template "sign" .]] delim[[.vars.date]]`
+
+	names, err := ReferencedVariables("t.tex", content, LaTeXDialect, partials)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"items", "title", "author", "date"}, names)
+
+	_, err = ReferencedVariables("t.tex", "delim[[if]]", LaTeXDialect, nil)
+	assert.Error(t, err)
+}
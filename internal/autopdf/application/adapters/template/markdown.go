@@ -0,0 +1,33 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+
+	"github.com/BuddhiLW/AutoPDF/pkg/latex"
+)
+
+// markdownFunc is the markdown template function: markdown [options] s, see latex.ParseMarkdownOptions
+func markdownFunc(args ...interface{}) (LaTeX, error) {
+	var spec string
+	switch len(args) {
+	case 1:
+	case 2:
+		s, ok := args[0].(string)
+		if !ok {
+			return "", fmt.Errorf("markdown options must be a string, got %T", args[0])
+		}
+		spec = s
+	default:
+		return "", fmt.Errorf("markdown takes optional options and the text, got %d arguments", len(args))
+	}
+	options, err := latex.ParseMarkdownOptions(spec)
+	if err != nil {
+		return "", err
+	}
+	source, _ := stringify(args[len(args)-1:])
+	out, err := latex.Markdown(source, options)
+	return LaTeX(out), err
+}
@@ -0,0 +1,26 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownFunc(t *testing.T) {
+	data := map[string]interface{}{"body": "# Offer\nA **great** deal & more"}
+
+	out, err := Execute("test", `delim[[markdown .body]]`, data, LaTeXDialect)
+	require.NoError(t, err)
+	assert.Equal(t, "\\section{Offer}\n\nA \\textbf{great} deal \\& more", out)
+
+	out, err = Execute("test", `delim[[.body | markdown "subsection restricted"]]`, data, LaTeXDialect)
+	require.NoError(t, err)
+	assert.Equal(t, "Offer\n\nA \\textbf{great} deal \\& more", out)
+
+	_, err = Execute("test", `delim[[markdown "h1" .body]]`, data, LaTeXDialect)
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+)
+
+// OutputPathDialect renders output paths: {{ }} delimiters whatever the engine, since paths
+// contain no markup, and printed values turned into safe file names
+var OutputPathDialect = Dialect{
+	Name:       "path",
+	LeftDelim:  "{{",
+	RightDelim: "}}",
+	Escape:     SanitizeFileName,
+}
+
+// sanitizerName is the function appended to the pipeline of every action of an output path
+const sanitizerName = "_path_segment"
+
+// RenderOutputPath renders an output path written as a template with the document's variables
+func (tpa *TemplateProcessorAdapter) RenderOutputPath(ctx context.Context, pattern string, data ports.TemplateData) (string, error) {
+	return RenderOutputPath(pattern, data)
+}
+
+// RenderOutputPath renders an output path pattern such as out/{{.client.slug}}/invoice-{{.invoice.number}}.pdf
+// A pattern without actions is returned as it is. Every printed value goes through SanitizeFileName,
+// so a value never adds directories or leaves the pattern's; values marked raw are printed as they are.
+// A value with nothing left once sanitized, such as an empty one, fails the rendering naming its variable.
+// Like ExecuteStrict, a variable data does not define fails the rendering with a *ports.MissingVariableError
+func RenderOutputPath(pattern string, data map[string]interface{}) (string, error) {
+	if !strings.Contains(pattern, OutputPathDialect.LeftDelim) {
+		return pattern, nil
+	}
+
+	tmpl, err := template.New("output").
+		Funcs(allFuncs(OutputPathDialect)).
+		Delims(OutputPathDialect.LeftDelim, OutputPathDialect.RightDelim).
+		Option("missingkey=error").
+		Parse(pattern)
+	if err != nil {
+		return "", fmt.Errorf("output path %q: %w", pattern, err)
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			appendToActions(t.Tree.Root, sanitizerName)
+		}
+	}
+	tmpl.Funcs(template.FuncMap{sanitizerName: sanitizePathSegment})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, withCompatAliases(data)); err != nil {
+		var blank *blankSegmentError
+		if errors.As(err, &blank) {
+			return "", fmt.Errorf("output path %q: %w", pattern, blank)
+		}
+		return "", missingVariable(err, "output", pattern, nil)
+	}
+	rendered := buf.String()
+	path := filepath.Clean(rendered)
+	base := filepath.Base(path)
+	if strings.HasSuffix(rendered, "/") || strings.HasSuffix(rendered, string(filepath.Separator)) ||
+		base == "." || base == ".." || base == string(filepath.Separator) {
+		return "", fmt.Errorf("output path %q renders %q, which names no file", pattern, rendered)
+	}
+	return path, nil
+}
+
+// appendToActions appends the function name to the pipeline of every action printing a value,
+// called with the name of the action's variable before the value
+func appendToActions(node parse.Node, name string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			appendToActions(child, name)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			variable := actionVariable(n.Pipe)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pipe.Pos,
+				Args: []parse.Node{
+					parse.NewIdentifier(name).SetPos(n.Pipe.Pos),
+					&parse.StringNode{NodeType: parse.NodeString, Pos: n.Pipe.Pos, Quoted: strconv.Quote(variable), Text: variable},
+				},
+			})
+		}
+	case *parse.IfNode:
+		appendToBranch(&n.BranchNode, name)
+	case *parse.RangeNode:
+		appendToBranch(&n.BranchNode, name)
+	case *parse.WithNode:
+		appendToBranch(&n.BranchNode, name)
+	}
+}
+
+func appendToBranch(n *parse.BranchNode, name string) {
+	appendToActions(n.List, name)
+	if n.ElseList != nil {
+		appendToActions(n.ElseList, name)
+	}
+}
+
+// actionVariable names what an action prints: the first field or variable of its first
+// command, such as client.slug in {{upper .client.slug}}, or the action's text without one
+func actionVariable(pipe *parse.PipeNode) string {
+	if len(pipe.Cmds) > 0 {
+		for _, arg := range pipe.Cmds[0].Args {
+			switch arg := arg.(type) {
+			case *parse.FieldNode:
+				return strings.Join(arg.Ident, ".")
+			case *parse.VariableNode:
+				return strings.Join(arg.Ident, ".")
+			}
+		}
+	}
+	return pipe.String()
+}
+
+// blankSegmentError reports a value printed into an output path that leaves nothing once sanitized
+type blankSegmentError struct {
+	Variable string
+	Value    string
+}
+
+func (e *blankSegmentError) Error() string {
+	return fmt.Sprintf("%s prints %q, which leaves no file name", e.Variable, e.Value)
+}
+
+func sanitizePathSegment(variable string, args ...interface{}) (string, error) {
+	s, trusted := stringify(args)
+	if trusted {
+		return s, nil
+	}
+	sanitized := SanitizeFileName(s)
+	if sanitized == "" {
+		return "", &blankSegmentError{Variable: variable, Value: s}
+	}
+	return sanitized, nil
+}
+
+// fileNameReplacer replaces the characters that separate directories or that Windows refuses in file names
+var fileNameReplacer = strings.NewReplacer(
+	`/`, `_`, `\`, `_`, `:`, `_`, `*`, `_`, `?`, `_`,
+	`"`, `_`, `<`, `_`, `>`, `_`, `|`, `_`,
+)
+
+// SanitizeFileName makes a value safe to print into a file name: path separators, characters
+// Windows refuses and control characters become _, surrounding spaces are trimmed,
+// and . and .. become _ so a value never refers to a directory
+func SanitizeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, fileNameReplacer.Replace(s))
+	s = strings.TrimSpace(s)
+	if s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
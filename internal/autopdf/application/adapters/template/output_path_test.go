@@ -0,0 +1,85 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderOutputPath(t *testing.T) {
+	data := map[string]interface{}{
+		"client":  map[string]interface{}{"slug": "acme", "name": "ACME / Sons: Ltd"},
+		"invoice": map[string]interface{}{"number": 42},
+		"parent":  "..",
+		"year":    "2025/01",
+		"draft":   true,
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{"fixed", "out/invoice.pdf", "out/invoice.pdf"},
+		{"variables", "out/{{.client.slug}}/invoice-{{.invoice.number}}.pdf", "out/acme/invoice-42.pdf"},
+		{"separators in values", "out/{{.client.name}}.pdf", "out/ACME _ Sons_ Ltd.pdf"},
+		{"parent directory value", "out/{{.parent}}/x.pdf", "out/_/x.pdf"},
+		{"functions", "out/{{.client.slug | upper}}.pdf", "out/ACME.pdf"},
+		{"conditionals", "out/invoice{{if .draft}}-draft{{end}}.pdf", "out/invoice-draft.pdf"},
+		{"raw values", "out/{{raw .year}}/report.pdf", "out/2025/01/report.pdf"},
+		{"cleaned", "out//{{.client.slug}}/./a.pdf", "out/acme/a.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderOutputPath(tt.pattern, data)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.FromSlash(tt.want), got)
+		})
+	}
+}
+
+func TestRenderOutputPath_Errors(t *testing.T) {
+	data := map[string]interface{}{"client": map[string]interface{}{"slug": "acme", "code": "  "}, "empty": "", "dir": "out/"}
+
+	_, err := RenderOutputPath("out/{{.client.vat}}.pdf", data)
+	var missing *ports.MissingVariableError
+	require.True(t, errors.As(err, &missing), "got %v", err)
+	assert.Equal(t, "client.vat", missing.Variable)
+
+	_, err = RenderOutputPath("out/{{.client.slug", data)
+	assert.Error(t, err)
+
+	_, err = RenderOutputPath("out/{{raw .dir}}", data)
+	assert.ErrorContains(t, err, "names no file")
+
+	_, err = RenderOutputPath("out/{{.empty}}.pdf", data)
+	assert.ErrorContains(t, err, `empty prints "", which leaves no file name`)
+
+	_, err = RenderOutputPath("out/invoice-{{upper .client.code}}.pdf", data)
+	assert.ErrorContains(t, err, `client.code prints "  "`)
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := map[string]string{
+		"invoice":          "invoice",
+		"a/b\\c":           "a_b_c",
+		`what?*"<>|:`:      "what_______",
+		"line\nbreak\x7f":  "line_break_",
+		"  padded  ":       "padded",
+		".":                "_",
+		"..":               "_",
+		"v1.2":             "v1.2",
+		"Ação & Companhia": "Ação & Companhia",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, SanitizeFileName(in), "SanitizeFileName(%q)", in)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/BuddhiLW/AutoPDF/configs"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+)
+
+// PartialsDirName is the directory under ~/.autopdf holding the partials every project can use
+const PartialsDirName = "partials"
+
+// Partial is a template file parsed into the same set as the template being rendered
+// It is named after its path relative to its partials directory, without the extension:
+// partials/layouts/base.tex is included with delim[[template "layouts/base" .]]
+//
+// A partial can be a piece of markup to include, such as a letterhead, or a layout
+// whose blocks the template overrides:
+//
+//	delim[[define "body"]]Dear delim[[.client]],delim[[end]]
+//	delim[[template "layouts/letter" .]]
+//
+// where layouts/letter.tex declares delim[[block "body" .]]delim[[end]]
+type Partial struct {
+	Name    string
+	Path    string
+	Content string
+}
+
+// GlobalPartialsDir returns the partials directory shared by every project, ~/.autopdf/partials
+func GlobalPartialsDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, configs.ConfigDirName, PartialsDirName)
+}
+
+// PartialDirs returns the directories partials are loaded from, in increasing precedence:
+// the global directory when it exists, then the configured ones in order
+func PartialDirs(cfg *config.Config) []string {
+	var dirs []string
+	if global := GlobalPartialsDir(); global != "" {
+		if info, err := os.Stat(global); err == nil && info.IsDir() {
+			dirs = append(dirs, global)
+		}
+	}
+	if cfg != nil {
+		dirs = append(dirs, cfg.Partials...)
+	}
+	return dirs
+}
+
+// LoadPartials reads the dialect's template files below each directory
+// A partial in a later directory replaces one of the same name in an earlier one
+func LoadPartials(dirs []string, dialect Dialect) ([]Partial, error) {
+	byName := make(map[string]Partial)
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.EqualFold(filepath.Ext(path), dialect.Extension) {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+			byName[name] = Partial{Name: name, Path: path, Content: string(content)}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load partials from %s: %w", dir, err)
+		}
+	}
+
+	partials := make([]Partial, 0, len(byName))
+	for _, p := range byName {
+		partials = append(partials, p)
+	}
+	sort.Slice(partials, func(i, j int) bool { return partials[i].Name < partials[j].Name })
+	return partials, nil
+}
+
+// parseSet parses the partials and then content into one template set named name
+// The templates content defines are parsed last, so they override the partials' blocks
+// Each file is parsed with the delimiters its magic comment sets, if any
+func parseSet(name, content string, funcs template.FuncMap, dialect Dialect, partials []Partial) (*template.Template, error) {
+	root := template.New(name).Funcs(funcs)
+	for _, p := range partials {
+		partialDialect, partialContent := dialect.ForContent(p.Content)
+		_, err := root.New(p.Name).
+			Delims(partialDialect.LeftDelim, partialDialect.RightDelim).
+			Parse(partialContent)
+		if err != nil {
+			return nil, fmt.Errorf("partial %s: %w", p.Path, err)
+		}
+	}
+	dialect, content = dialect.ForContent(content)
+	return root.Delims(dialect.LeftDelim, dialect.RightDelim).Parse(content)
+}
+
+// UsedPartials returns the partials content includes, directly or through other partials,
+// such as the layouts it renders its blocks through
+func UsedPartials(name, content string, dialect Dialect, partials []Partial) ([]Partial, error) {
+	tmpl, err := parseSet(name, content, allFuncs(dialect), dialect, partials)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Partial, len(partials))
+	for _, p := range partials {
+		byName[p.Name] = p
+	}
+	files := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var visit func(templateName string)
+	visit = func(templateName string) {
+		if visited[templateName] {
+			return
+		}
+		visited[templateName] = true
+		t := tmpl.Lookup(templateName)
+		if t == nil || t.Tree == nil {
+			return
+		}
+		// Trees defined inside a partial file are parsed under the partial's name
+		if _, ok := byName[t.Tree.ParseName]; ok {
+			files[t.Tree.ParseName] = true
+		}
+		walkTemplateCalls(t.Tree.Root, visit)
+	}
+	visit(name)
+
+	var used []Partial
+	for _, p := range partials {
+		if files[p.Name] {
+			used = append(used, p)
+		}
+	}
+	return used, nil
+}
+
+// walkTemplateCalls calls visit with the name of each template node invokes
+func walkTemplateCalls(node parse.Node, visit func(string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateCalls(child, visit)
+		}
+	case *parse.TemplateNode:
+		visit(n.Name)
+	case *parse.IfNode:
+		walkTemplateCalls(n.List, visit)
+		walkTemplateCalls(n.ElseList, visit)
+	case *parse.RangeNode:
+		walkTemplateCalls(n.List, visit)
+		walkTemplateCalls(n.ElseList, visit)
+	case *parse.WithNode:
+		walkTemplateCalls(n.List, visit)
+		walkTemplateCalls(n.ElseList, visit)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestLoadPartials(t *testing.T) {
+	shared := t.TempDir()
+	project := t.TempDir()
+	writeFiles(t, shared, map[string]string{
+		"letterhead.tex":   "Shared letterhead",
+		"signature.tex":    "Shared signature",
+		"layouts/base.tex": "Base",
+		"notes.md":         "not a partial",
+		"invoice.typ":      "not a LaTeX partial",
+	})
+	writeFiles(t, project, map[string]string{
+		"letterhead.tex": "Project letterhead",
+	})
+
+	partials, err := LoadPartials([]string{shared, project}, LaTeXDialect)
+	require.NoError(t, err)
+
+	contents := make(map[string]string)
+	for _, p := range partials {
+		contents[p.Name] = p.Content
+	}
+	assert.Equal(t, map[string]string{
+		"layouts/base": "Base",
+		"letterhead":   "Project letterhead",
+		"signature":    "Shared signature",
+	}, contents, "later directories override earlier ones")
+
+	_, err = LoadPartials([]string{filepath.Join(shared, "missing")}, LaTeXDialect)
+	assert.Error(t, err)
+}
+
+func TestExecute_Partials(t *testing.T) {
+	partials := []Partial{
+		{Name: "letterhead", Content: `\textbf{delim[[.company]]}`},
+		{Name: "layouts/letter", Content: `\begin{document}delim[[template "letterhead" .]]
+delim[[block "body" .]]No content.delim[[end]]
+delim[[block "closing" .]]Regards,delim[[end]]
+\end{document}`},
+	}
+	data := map[string]interface{}{"company": "Smith & Sons", "client": "Ana"}
+
+	out, err := Execute("letter.tex", `delim[[define "body"]]Dear delim[[.client]],delim[[end]]delim[[template "layouts/letter" .]]`,
+		data, LaTeXDialect, partials...)
+	require.NoError(t, err)
+	assert.Equal(t, `\begin{document}\textbf{Smith \& Sons}
+Dear Ana,
+Regards,
+\end{document}`, out, "the template overrides the layout's body block and keeps its closing")
+
+	_, err = Execute("letter.tex", `delim[[template "missing" .]]`, data, LaTeXDialect, partials...)
+	assert.Error(t, err)
+}
+
+func TestUsedPartials(t *testing.T) {
+	partials := []Partial{
+		{Name: "letterhead", Content: `delim[[.company]]`},
+		{Name: "footer", Content: `delim[[.footer]]`},
+		{Name: "layouts/letter", Content: `delim[[template "letterhead" .]]delim[[block "body" .]]delim[[end]]`},
+		{Name: "unused", Content: `delim[[.unused]]`},
+	}
+
+	used, err := UsedPartials("letter.tex", `delim[[define "body"]]delim[[if .x]]delim[[template "footer" .]]delim[[end]]delim[[end]]delim[[template "layouts/letter" .]]`,
+		LaTeXDialect, partials)
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range used {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"letterhead", "footer", "layouts/letter"}, names)
+}
+
+func TestTemplateProcessorAdapter_Partials(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"partials/signature.tex": `-- delim[[.author]]`,
+		"letter.tex":             `Body delim[[template "signature" .]]`,
+	})
+
+	adapter := NewTemplateProcessorAdapter(&config.Config{Partials: []string{filepath.Join(dir, "partials")}})
+	out, err := adapter.Process(context.Background(), filepath.Join(dir, "letter.tex"), ports.TemplateData{"author": "Ana"})
+	require.NoError(t, err)
+	assert.Equal(t, `Body -- Ana`, out)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+)
+
+// Execute renders template content with data, using the dialect's delimiters and escaping
+// Data keeps the variables' types, so conditionals, range and comparisons see real
+// bools, numbers, slices and maps; see compat.go for templates relying on string values
+// With an auto-escaping dialect every printed value is escaped unless marked raw
+// Templates can call the function library (library.go), functions registered with RegisterFunc
+// and the partials given, see Partial
+func Execute(name, content string, data map[string]interface{}, dialect Dialect, partials ...Partial) (string, error) {
+	return execute(name, content, data, dialect, false, partials)
+}
+
+// ExecuteStrict renders like Execute, but fails on the first variable data does not define,
+// at any depth of nested maps, instead of printing nothing. The error is a
+// *ports.MissingVariableError locating the variable in the template or partial referring to it
+// Optional variables are tested with index, which does not fail: delim[[with index . "note"]]
+func ExecuteStrict(name, content string, data map[string]interface{}, dialect Dialect, partials ...Partial) (string, error) {
+	return execute(name, content, data, dialect, true, partials)
+}
+
+func execute(name, content string, data map[string]interface{}, dialect Dialect, strict bool, partials []Partial) (string, error) {
+	tmpl, err := parseSet(name, content, allFuncs(dialect), dialect, partials)
+	if err != nil {
+		return "", err
+	}
+	if dialect.AutoEscape {
+		autoEscape(tmpl, dialect.escapeContext())
+	}
+	if strict {
+		tmpl.Option("missingkey=error")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, withCompatAliases(data)); err != nil {
+		if strict {
+			return "", missingVariable(err, name, content, partials)
+		}
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// missingKeyError matches the error text/template returns for a missing map key:
+// template: NAME:LINE:COL: executing "TEMPLATE" at <NODE>: map has no entry for key "KEY"
+var missingKeyError = regexp.MustCompile(`^template: (.*):(\d+):(\d+): executing ".*" at <(.*)>: map has no entry for key "(.*)"$`)
+
+// missingVariable converts the execution error of a missing key to a *ports.MissingVariableError,
+// naming the file the tree it was raised in was parsed from; other errors are returned as they are
+func missingVariable(err error, name, content string, partials []Partial) error {
+	var execErr template.ExecError
+	if !errors.As(err, &execErr) {
+		return err
+	}
+	m := missingKeyError.FindStringSubmatch(execErr.Err.Error())
+	if m == nil {
+		return err
+	}
+
+	file, source := name, content
+	for _, p := range partials {
+		if p.Name == m[1] {
+			file, source = p.Path, p.Content
+		}
+	}
+	line, _ := strconv.Atoi(m[2])
+	column, _ := strconv.Atoi(m[3])
+	snippet := sourceLine(source, line)
+	// The position is the node's last field; point at the start of the whole reference
+	if start := strings.LastIndex(snippet[:min(column+len(m[4]), len(snippet))], m[4]); start >= 0 {
+		column = start
+	}
+
+	return &ports.MissingVariableError{
+		Variable: missingPath(m[4], m[5]),
+		File:     file,
+		Line:     line,
+		Column:   column + 1,
+		Snippet:  snippet,
+		Err:      err,
+	}
+}
+
+// missingPath returns the path of node up to the missing key: .client.vat is client when
+// client is missing. Paths inside range and with are relative to their dot
+func missingPath(node, key string) string {
+	switch {
+	case strings.HasPrefix(node, "$."):
+		node = node[2:]
+	case strings.HasPrefix(node, "."):
+		node = node[1:]
+	}
+	fields := strings.Split(node, ".")
+	for i, field := range fields {
+		if field == key {
+			return strings.Join(fields[:i+1], ".")
+		}
+	}
+	return key
+}
+
+// sourceLine returns the 1-based line of content, without its line ending
+func sourceLine(content string, line int) string {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(lines[line-1], "\r")
+}
+
+// allFuncs returns every function templates of the dialect can call
+func allFuncs(dialect Dialect) template.FuncMap {
+	funcMap := libraryFuncs()
+	for fn, impl := range compatFuncs() {
+		funcMap[fn] = impl
+	}
+	for fn, impl := range registeredFuncs() {
+		funcMap[fn] = impl
+	}
+	funcMap["escape"] = func(v interface{}) string { return dialect.Escape(toText(v)) }
+	funcMap["raw"] = raw
+	funcMap["latex"] = raw
+	if dialect.AutoEscape {
+		// An escaped value is not escaped again
+		funcMap["escape"] = func(v interface{}) LaTeX { return LaTeX(dialect.Escape(toText(v))) }
+	}
+	return funcMap
+}
+
+// raw marks a value as trusted markup, printed as is
+func raw(v interface{}) LaTeX {
+	if s, ok := v.(LaTeX); ok {
+		return s
+	}
+	return LaTeX(fmt.Sprint(v))
+}
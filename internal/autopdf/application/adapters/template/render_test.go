@@ -0,0 +1,180 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, content string, data map[string]interface{}) string {
+	t.Helper()
+	out, err := Execute("test", content, data, LaTeXDialect)
+	require.NoError(t, err)
+	return out
+}
+
+func TestExecute_TypedData(t *testing.T) {
+	cfg, err := config.NewConfigFromYAML([]byte(`
+variables:
+  draft: false
+  pages: 12
+  rate: 80.5
+  tags: [urgent, internal]
+  client:
+    name: ACME
+    vip: true
+  items:
+    - name: Design
+      hours: 6
+    - name: Review
+      hours: 2
+`))
+	require.NoError(t, err)
+	data := cfg.Variables.TemplateData()
+
+	assert.Equal(t, "final", render(t, `delim[[if .draft]]draft delim[[else]]finaldelim[[end]]`, data),
+		"a false bool is false, where the string \"false\" was not")
+	assert.Equal(t, "12 80.5", render(t, `delim[[.pages]] delim[[.rate]]`, data))
+	assert.Equal(t, "long", render(t, `delim[[if gt .pages 10]]longdelim[[end]]`, data))
+	assert.Equal(t, "urgent,internal,", render(t, `delim[[range .tags]]delim[[.]],delim[[end]]`, data))
+	assert.Equal(t, "ACME (VIP)", render(t, `delim[[.client.name]]delim[[if .client.vip]] (VIP)delim[[end]]`, data))
+	assert.Equal(t, "Design: 6h; Review: 2h; 2", render(t,
+		`delim[[range .items]]delim[[.name]]: delim[[.hours]]h; delim[[end]]delim[[len .items]]`, data))
+	assert.Equal(t, "internal", render(t, `delim[[index .tags 1]]`, data))
+}
+
+func TestExecute_CompatShim(t *testing.T) {
+	data := map[string]interface{}{
+		"title":   "Report",
+		"count":   3,
+		"ratio":   2.5,
+		"enabled": true,
+	}
+
+	// Comparisons written against string values
+	assert.Equal(t, "yes", render(t, `delim[[if eq .count "3"]]yesdelim[[end]]`, data))
+	assert.Equal(t, "yes", render(t, `delim[[if eq .enabled "true"]]yesdelim[[end]]`, data))
+	assert.Equal(t, "yes", render(t, `delim[[if ne .title "Draft"]]yesdelim[[end]]`, data))
+	assert.Equal(t, "yes", render(t, `delim[[if eq .title "Memo" "Report"]]yesdelim[[end]]`, data))
+
+	// Mixed int and float comparisons, which text/template rejects
+	assert.Equal(t, "yes", render(t, `delim[[if lt .ratio .count]]yesdelim[[end]]`, data))
+	assert.Equal(t, "yes", render(t, `delim[[if and (gt .ratio 2) (le .count 3.0)]]yesdelim[[end]]`, data))
+
+	// Variables reached through .vars
+	assert.Equal(t, "Report 3", render(t, `delim[[.vars.title]] delim[[.vars.count]]`, data))
+	assert.Equal(t, "own", render(t, `delim[[.vars]]`, map[string]interface{}{"vars": "own"}),
+		"a variable named vars wins over the alias")
+
+	_, err := Execute("test", `delim[[if lt .title 3]]delim[[end]]`, data, LaTeXDialect)
+	assert.Error(t, err)
+}
+
+func TestTemplateProcessorAdapter_Process(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "letter.tex")
+	require.NoError(t, os.WriteFile(path, []byte(`\title{delim[[.title]]}delim[[range .signers]] \and delim[[.]]delim[[end]]`), 0644))
+
+	out, err := NewTemplateProcessorAdapter(nil).Process(context.Background(), path,
+		ports.TemplateData{"title": "Letter", "signers": []interface{}{"Ana", "Bo"}})
+	require.NoError(t, err)
+	assert.Equal(t, `\title{Letter} \and Ana \and Bo`, out)
+
+	_, err = NewTemplateProcessorAdapter(nil).Process(context.Background(), "", nil)
+	assert.Error(t, err)
+}
+
+func TestExecuteStrict(t *testing.T) {
+	data := map[string]interface{}{
+		"client": map[string]interface{}{"name": "ACME"},
+		"items":  []interface{}{map[string]interface{}{"name": "Design"}},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		variable string
+		line     int
+		column   int
+		snippet  string
+	}{
+		{"top level", "Dear\ndelim[[.title]]", "title", 2, 8, "delim[[.title]]"},
+		{"nested key", "To: delim[[.client.vat]]", "client.vat", 1, 12, "To: delim[[.client.vat]]"},
+		{"missing parent", "To: delim[[.company.name]]", "company", 1, 12, "To: delim[[.company.name]]"},
+		{"root variable", `delim[[range .items]]delim[[$.client.vat]]delim[[end]]`, "client.vat", 1, 29, `delim[[range .items]]delim[[$.client.vat]]delim[[end]]`},
+		{"inside range", `delim[[range .items]]delim[[.hours]]delim[[end]]`, "hours", 1, 29, `delim[[range .items]]delim[[.hours]]delim[[end]]`},
+		{"conditional", `delim[[if .draft]]DRAFT delim[[end]]`, "draft", 1, 11, `delim[[if .draft]]DRAFT delim[[end]]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExecuteStrict("letter.tex", tt.template, data, LaTeXDialect)
+			var missing *ports.MissingVariableError
+			require.ErrorAs(t, err, &missing)
+			assert.Equal(t, tt.variable, missing.Variable)
+			assert.Equal(t, "letter.tex", missing.File)
+			assert.Equal(t, tt.line, missing.Line)
+			assert.Equal(t, tt.column, missing.Column)
+			assert.Equal(t, tt.snippet, missing.Snippet)
+		})
+	}
+}
+
+func TestExecuteStrict_DefinedVariables(t *testing.T) {
+	data := map[string]interface{}{
+		"client": map[string]interface{}{"name": "R&D", "vat": nil},
+	}
+
+	out, err := ExecuteStrict("letter.tex", `delim[[.client.name]]delim[[.client.vat]]delim[[with index . "note"]]delim[[.]]delim[[end]]`, data, LaTeXDialect)
+	require.NoError(t, err)
+	assert.Equal(t, `R\&D`, out)
+
+	out, err = Execute("letter.tex", `[delim[[.client.missing]]]`, data, LaTeXDialect)
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, out)
+}
+
+func TestExecuteStrict_InPartial(t *testing.T) {
+	partials := []Partial{{
+		Name:    "letterhead",
+		Path:    "partials/letterhead.tex",
+		Content: "\\textbf{delim[[.client.name]]}\n  VAT: delim[[.client.vat]]\n",
+	}}
+	data := map[string]interface{}{"client": map[string]interface{}{"name": "ACME"}}
+
+	_, err := ExecuteStrict("letter.tex", `delim[[template "letterhead" .]]`, data, LaTeXDialect, partials...)
+	var missing *ports.MissingVariableError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, "client.vat", missing.Variable)
+	assert.Equal(t, "partials/letterhead.tex", missing.File)
+	assert.Equal(t, 2, missing.Line)
+	assert.Equal(t, 15, missing.Column)
+	assert.Equal(t, "  VAT: delim[[.client.vat]]", missing.Snippet)
+	assert.Contains(t, missing.Error(), `partials/letterhead.tex:2:15: variable "client.vat" is missing`)
+}
+
+func TestTemplateProcessorAdapter_Strict(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "letter.tex")
+	require.NoError(t, os.WriteFile(templatePath, []byte(`Dear delim[[.name]]`), 0644))
+
+	cfg := &config.Config{Engine: "pdflatex"}
+	out, err := NewTemplateProcessorAdapter(cfg).Process(context.Background(), templatePath, ports.TemplateData{})
+	require.NoError(t, err)
+	assert.Equal(t, "Dear ", out)
+
+	cfg.Strict = true
+	_, err = NewTemplateProcessorAdapter(cfg).Process(context.Background(), templatePath, ports.TemplateData{})
+	var missing *ports.MissingVariableError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, "name", missing.Variable)
+	assert.Equal(t, templatePath, missing.File)
+}
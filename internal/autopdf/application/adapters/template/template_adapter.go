@@ -4,20 +4,19 @@
 package template
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"os"
 	"path/filepath"
-	"strings"
-	"text/template"
 
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // TemplateProcessorAdapter wraps the existing template engine
 type TemplateProcessorAdapter struct {
-	// config is used to initialize the template engine
+	// config selects the template dialect through its engine and delimiters, lists the partial
+	// directories and sets whether missing variables fail the rendering
 	config *config.Config
 }
 
@@ -28,8 +27,8 @@ func NewTemplateProcessorAdapter(cfg *config.Config) *TemplateProcessorAdapter {
 	}
 }
 
-// Process processes a template with variables
-func (tpa *TemplateProcessorAdapter) Process(ctx context.Context, templatePath string, variables map[string]string) (string, error) {
+// Process processes a template with the variables' template data
+func (tpa *TemplateProcessorAdapter) Process(ctx context.Context, templatePath string, data ports.TemplateData) (string, error) {
 	if templatePath == "" {
 		return "", errors.New("no template file specified")
 	}
@@ -40,135 +39,66 @@ func (tpa *TemplateProcessorAdapter) Process(ctx context.Context, templatePath s
 		return "", err
 	}
 
-	// Create function map for template functions
-	funcMap := template.FuncMap{
-		"upper": func(s string) string {
-			return s
-		},
-		// Add more helper functions as needed
-	}
-
-	// Create new template with custom delimiters to avoid conflicts with LaTeX
-	tmpl, err := template.New(templatePath).
-		Funcs(funcMap).
-		Delims("delim[[", "]]").
-		Parse(string(content))
+	dialect, err := tpa.dialect()
 	if err != nil {
 		return "", err
 	}
-
-	// Reconstruct nested structure from flattened variables for direct template access
-	// Variables are placed at root level so templates can use delim[[.logo_path]]
-	// instead of delim[[.vars.logo_path]]
-	nestedVariables := tpa.reconstructNestedStructure(variables)
-
-	// Create template data with variables at root level
-	templateData := nestedVariables
-	if templateData == nil {
-		templateData = make(map[string]interface{})
-	}
-
-	// Add complex structure for range loops (if available)
-	complex := tpa.getComplexVariables()
-	if len(complex) > 0 {
-		for k, v := range complex {
-			templateData[k] = v
-		}
-	}
-
-	// Apply template with both flattened and complex variables
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, templateData); err != nil {
+	partials, err := LoadPartials(PartialDirs(tpa.config), dialect)
+	if err != nil {
 		return "", err
 	}
 
-	return buf.String(), nil
-}
-
-// reconstructNestedStructure converts flattened variables back to nested structure
-// Handles flattened dot-notation keys from Flatten() method
-// Variables are placed at root level for direct template access (e.g., .logo_path)
-func (tpa *TemplateProcessorAdapter) reconstructNestedStructure(variables map[string]string) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	for key, value := range variables {
-		// Handle flattened dot-notation keys (e.g., "velorio.dia", "velorio.periodo.inicio")
-		parts := strings.Split(key, ".")
-		tpa.setNestedValue(result, parts, value)
+	// Variables are at root level, so templates use delim[[.logo_path]] and
+	// range directly over slices such as delim[[range .items]]
+	if tpa.config != nil && tpa.config.Strict {
+		return ExecuteStrict(templatePath, string(content), data, dialect, partials...)
 	}
-
-	// Return variables at root level (NOT wrapped under "vars" key)
-	// This allows templates to use direct access like delim[[.logo_path]]
-	// instead of requiring delim[[.vars.logo_path]]
-	return result
+	return Execute(templatePath, string(content), data, dialect, partials...)
 }
 
-// setNestedValue sets a value in a nested map structure
-func (tpa *TemplateProcessorAdapter) setNestedValue(data map[string]interface{}, parts []string, value string) {
-	if len(parts) == 0 {
-		return
-	}
+// PassThroughProcessor returns a source that was rendered before it reached the document
+// service as it is. Rendering it again would evaluate actions that values printed into it
+// spell out, such as a variable holding delim[[raw .x]]
+type PassThroughProcessor struct{}
 
-	if len(parts) == 1 {
-		data[parts[0]] = value
-		return
-	}
+// NewPassThroughProcessor creates a processor for already rendered sources
+func NewPassThroughProcessor() *PassThroughProcessor {
+	return &PassThroughProcessor{}
+}
 
-	// Create nested map if it doesn't exist
-	if _, exists := data[parts[0]]; !exists {
-		data[parts[0]] = make(map[string]interface{})
+// Process returns the content of the rendered source; data is not used
+func (p *PassThroughProcessor) Process(ctx context.Context, templatePath string, data ports.TemplateData) (string, error) {
+	if templatePath == "" {
+		return "", errors.New("no template file specified")
 	}
-
-	// Ensure it's a map
-	if nested, ok := data[parts[0]].(map[string]interface{}); ok {
-		tpa.setNestedValue(nested, parts[1:], value)
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return "", err
 	}
+	return string(content), nil
 }
 
-// getComplexVariables returns the complex variable structure for range loops
-func (tpa *TemplateProcessorAdapter) getComplexVariables() map[string]interface{} {
-	if tpa.config == nil || tpa.config.Variables.VariableSet == nil {
-		return make(map[string]interface{})
-	}
-
-	// Convert the complex variables to a map that can be used in range loops
-	result := make(map[string]interface{})
-	for k, v := range tpa.config.Variables.VariableSet.GetVariables() {
-		result[k] = tpa.convertToMap(v)
-	}
-	return result
+// dialect returns the template dialect of the configured engine, with the configured delimiters
+func (tpa *TemplateProcessorAdapter) dialect() (Dialect, error) {
+	return ConfigDialect(tpa.config)
 }
 
-// convertToMap converts a Variable to a map for template range loops
-func (tpa *TemplateProcessorAdapter) convertToMap(variable config.Variable) interface{} {
-	switch v := variable.(type) {
-	case *config.StringVariable:
-		return v.Value
-	case *config.NumberVariable:
-		return v.Value
-	case *config.BoolVariable:
-		return v.Value
-	case *config.MapVariable:
-		result := make(map[string]interface{})
-		for k, val := range v.Values {
-			result[k] = tpa.convertToMap(val)
-		}
-		return result
-	case *config.SliceVariable:
-		result := make([]interface{}, len(v.Values))
-		for i, val := range v.Values {
-			result[i] = tpa.convertToMap(val)
-		}
-		return result
-	default:
-		return variable.String()
+// ConfigDialect returns the template dialect of a config's engine, with its delimiters
+func ConfigDialect(cfg *config.Config) (Dialect, error) {
+	if cfg == nil {
+		return LaTeXDialect, nil
+	}
+	delimiters := cfg.Delimiters
+	if err := delimiters.Validate(); err != nil {
+		return Dialect{}, err
 	}
+	return DialectFor(cfg.Engine.String()).WithDelimiters(delimiters.Left, delimiters.Right), nil
 }
 
 // ProcessToFile processes a template and writes it to a file
-func (tpa *TemplateProcessorAdapter) ProcessToFile(ctx context.Context, templatePath string, variables map[string]string, outputPath string) error {
+func (tpa *TemplateProcessorAdapter) ProcessToFile(ctx context.Context, templatePath string, data ports.TemplateData, outputPath string) error {
 	// Process the template
-	result, err := tpa.Process(ctx, templatePath, variables)
+	result, err := tpa.Process(ctx, templatePath, data)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,211 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package typst
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	application "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+)
+
+var (
+	unsafeSourceChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	// diagnosticPattern matches typst's short diagnostic format: "file:line:col: severity: message"
+	// Diagnostics without a source span omit the location
+	diagnosticPattern = regexp.MustCompile(`^(?:(.+?):(\d+):(\d+): )?(error|warning|hint): (.*)$`)
+)
+
+// TypstCompilerAdapter implements LaTeXCompiler with the typst compiler
+// Typst lays the document out as often as it needs in a single run, so passes,
+// latexmk and the bibliography toolchain do not apply
+type TypstCompilerAdapter struct {
+	fileSystem application.FileSystem
+	executor   application.CommandExecutor
+	settings   config.Typst
+}
+
+// NewTypstCompilerAdapter creates a new typst compiler adapter
+func NewTypstCompilerAdapter(
+	cfg *config.Config,
+	fileSystem application.FileSystem,
+	executor application.CommandExecutor,
+) *TypstCompilerAdapter {
+	var settings config.Typst
+	if cfg != nil {
+		settings = cfg.Typst
+	}
+	return &TypstCompilerAdapter{
+		fileSystem: fileSystem,
+		executor:   executor,
+		settings:   settings,
+	}
+}
+
+// Compile compiles Typst content to PDF
+func (tca *TypstCompilerAdapter) Compile(ctx context.Context, content string, opts application.CompileOptions) (application.CompileResult, error) {
+	if content == "" {
+		return application.CompileResult{}, errors.New("no Typst content provided")
+	}
+	if opts.Engine != application.EngineTypst {
+		return application.CompileResult{}, fmt.Errorf("%w %q for this compiler: only %s is run by TypstCompilerAdapter",
+			application.ErrUnsupportedEngine, opts.Engine, application.EngineTypst)
+	}
+
+	pdfPath := opts.OutputPath
+	if pdfPath == "" {
+		pdfPath = filepath.Join(opts.WorkingDir, opts.JobName+".pdf")
+	}
+	// Typst resolves the output path itself, not relative to the working directory
+	pdfPath, err := filepath.Abs(pdfPath)
+	if err != nil {
+		return application.CompileResult{}, fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	workingDir := opts.WorkingDir
+	if workingDir == "" {
+		workingDir = filepath.Dir(pdfPath)
+	}
+	for _, dir := range []string{workingDir, filepath.Dir(pdfPath)} {
+		if err := tca.fileSystem.MkdirAll(ctx, dir, 0755); err != nil {
+			return application.CompileResult{}, fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	// Verify that the compiler is installed
+	if _, err := tca.executor.Execute(ctx, application.NewCommand("which", []string{application.EngineTypst}, "")); err != nil {
+		return application.CompileResult{}, fmt.Errorf("Typst compiler not found: %s", application.EngineTypst)
+	}
+
+	sourceFile := sourceFileName(opts.JobName)
+	concreteFile := filepath.Join(workingDir, sourceFile)
+	if err := tca.fileSystem.WriteFile(ctx, concreteFile, []byte(content), 0644); err != nil {
+		return application.CompileResult{}, err
+	}
+	if !opts.Debug {
+		defer func() {
+			_ = tca.fileSystem.Remove(ctx, concreteFile)
+		}()
+	}
+
+	cmd := application.NewCommand(application.EngineTypst, tca.args(workingDir, sourceFile, pdfPath), workingDir).
+		WithTimeout(5 * time.Minute).
+		WithSecurity(opts.Security)
+
+	result, err := tca.executor.Execute(ctx, cmd)
+	diagnostics := ParseDiagnostics(result.Stderr)
+	if err == nil && !result.Success {
+		err = fmt.Errorf("typst exited with code %d", result.ExitCode)
+	}
+	if err != nil {
+		return application.CompileResult{Diagnostics: diagnostics}, compilationError(diagnostics, cmd, result, err)
+	}
+
+	info, err := tca.fileSystem.Stat(ctx, pdfPath)
+	if err != nil {
+		return application.CompileResult{Diagnostics: diagnostics}, fmt.Errorf("PDF file was not created at %s: %w", pdfPath, err)
+	}
+	if info.Size() == 0 {
+		return application.CompileResult{Diagnostics: diagnostics}, fmt.Errorf("PDF file was created but is empty at %s", pdfPath)
+	}
+
+	return application.CompileResult{
+		PDFPath:     pdfPath,
+		Diagnostics: diagnostics,
+		Passes:      1,
+	}, nil
+}
+
+// args builds typst's argv
+// The working directory is the project root: the document cannot read files outside it
+func (tca *TypstCompilerAdapter) args(workingDir, sourceFile, pdfPath string) []string {
+	args := []string{
+		"compile",
+		"--root", workingDir,
+		"--diagnostic-format", "short",
+	}
+	for _, fontPath := range tca.settings.FontPaths {
+		args = append(args, "--font-path", fontPath)
+	}
+	if tca.settings.PackagePath != "" {
+		args = append(args, "--package-path", tca.settings.PackagePath)
+	}
+	if tca.settings.PackageCacheDir != "" {
+		args = append(args, "--package-cache-path", tca.settings.PackageCacheDir)
+	}
+	return append(args, sourceFile, pdfPath)
+}
+
+// ParseDiagnostics extracts diagnostics from typst's short-format output
+// Hints are attached to the diagnostic they follow
+func ParseDiagnostics(output string) []application.Diagnostic {
+	var diagnostics []application.Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		m := diagnosticPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		if m[4] == "hint" {
+			if len(diagnostics) > 0 {
+				last := &diagnostics[len(diagnostics)-1]
+				last.Context = strings.TrimPrefix(last.Context+"\nhint: "+m[5], "\n")
+			}
+			continue
+		}
+
+		d := application.Diagnostic{
+			Kind:     application.DiagnosticWarning,
+			Severity: application.SeverityWarning,
+			File:     m[1],
+			Message:  m[5],
+		}
+		if m[4] == "error" {
+			d.Kind = application.DiagnosticError
+			d.Severity = application.SeverityError
+		}
+		if strings.HasPrefix(m[5], "file not found") {
+			d.Kind = application.DiagnosticMissingFile
+		}
+		d.Line, _ = strconv.Atoi(m[2])
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// sourceFileName derives the .typ file name from the job name
+// Anything beyond letters, digits, dots, dashes and underscores is replaced, as for LaTeX sources
+func sourceFileName(jobName string) string {
+	name := unsafeSourceChars.ReplaceAllString(jobName, "_")
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") {
+		name = "_" + name
+	}
+	return name + application.SourceExtension(application.EngineTypst)
+}
+
+// compilationError wraps a failed typst run with its parsed diagnostics
+// Raw compiler output is only included when it yielded no error diagnostics
+func compilationError(diagnostics []application.Diagnostic, cmd application.Command, result application.CommandResult, err error) error {
+	for _, d := range diagnostics {
+		if d.IsError() {
+			return &application.CompilationError{
+				Diagnostics: diagnostics,
+				Err:         fmt.Errorf("Typst compilation failed: %w", err),
+			}
+		}
+	}
+	errorDetails := fmt.Sprintf(
+		"Typst compilation failed:\nCommand: %s %s\nWorking Dir: %s\nStderr:\n%s\nStdout:\n%s",
+		cmd.Executable, strings.Join(cmd.Args, " "), cmd.Dir, result.Stderr, result.Stdout,
+	)
+	return &application.CompilationError{
+		Diagnostics: diagnostics,
+		Err:         fmt.Errorf("%s: %w", errorDetails, err),
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package typst
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTypst records typst runs and writes the PDF to the output argument
+type fakeTypst struct {
+	commands []ports.Command
+	stderr   string
+	exitCode int
+}
+
+func (f *fakeTypst) Execute(ctx context.Context, cmd ports.Command) (ports.CommandResult, error) {
+	if cmd.Executable == "which" {
+		return ports.NewCommandResult("", "", 0, time.Millisecond), nil
+	}
+	f.commands = append(f.commands, cmd)
+
+	if f.exitCode != 0 {
+		return ports.NewCommandResult("", f.stderr, f.exitCode, time.Millisecond), errors.New("exit status 1")
+	}
+	_ = os.WriteFile(cmd.Args[len(cmd.Args)-1], []byte("%PDF-1.7"), 0644)
+	return ports.NewCommandResult("", f.stderr, 0, time.Millisecond), nil
+}
+
+func TestTypstCompilerAdapter_Compile(t *testing.T) {
+	workingDir := t.TempDir()
+	output := filepath.Join(t.TempDir(), "out", "invoice 42.pdf")
+	engine := &fakeTypst{stderr: "invoice_42.typ:7:1: warning: unknown font family: inter\n"}
+	cfg := &config.Config{Typst: config.Typst{FontPaths: []string{"/srv/fonts"}, PackageCacheDir: "/var/cache/typst"}}
+	adapter := NewTypstCompilerAdapter(cfg, infraadapters.NewOSFileSystem(), engine)
+
+	opts := ports.NewCompileOptions(ports.EngineTypst, output, workingDir).
+		WithJobName("invoice 42").
+		WithSecurity(ports.NewSandboxPolicy())
+
+	result, err := adapter.Compile(context.Background(), "= Invoice", opts)
+	require.NoError(t, err)
+	assert.Equal(t, output, result.PDFPath)
+	assert.FileExists(t, output)
+	assert.Equal(t, 1, result.Passes)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, ports.SeverityWarning, result.Diagnostics[0].Severity)
+	assert.Equal(t, 7, result.Diagnostics[0].Line)
+
+	require.Len(t, engine.commands, 1)
+	cmd := engine.commands[0]
+	assert.Equal(t, "typst", cmd.Executable)
+	assert.Equal(t, workingDir, cmd.Dir)
+	assert.Equal(t, []string{
+		"compile",
+		"--root", workingDir,
+		"--diagnostic-format", "short",
+		"--font-path", "/srv/fonts",
+		"--package-cache-path", "/var/cache/typst",
+		"invoice_42.typ",
+		output,
+	}, cmd.Args)
+	assert.Equal(t, ports.NewSandboxPolicy(), cmd.Security)
+
+	// The source file is removed outside debug mode
+	assert.NoFileExists(t, filepath.Join(workingDir, "invoice_42.typ"))
+}
+
+func TestTypstCompilerAdapter_Failure(t *testing.T) {
+	dir := t.TempDir()
+	engine := &fakeTypst{
+		exitCode: 1,
+		stderr: "doc.typ:3:2: error: unknown variable: totl\n" +
+			"doc.typ:3:2: hint: if you meant to display text, use #[...]\n",
+	}
+	adapter := NewTypstCompilerAdapter(nil, infraadapters.NewOSFileSystem(), engine)
+
+	opts := ports.NewCompileOptions(ports.EngineTypst, filepath.Join(dir, "doc.pdf"), dir).WithJobName("doc").WithDebug(true)
+	result, err := adapter.Compile(context.Background(), "#totl", opts)
+
+	require.Error(t, err)
+	var compileErr *ports.CompilationError
+	require.ErrorAs(t, err, &compileErr)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, "doc.typ:3: unknown variable: totl", result.Diagnostics[0].String())
+	assert.Contains(t, result.Diagnostics[0].Context, "hint: if you meant")
+	assert.FileExists(t, filepath.Join(dir, "doc.typ"), "debug builds keep the source")
+}
+
+func TestTypstCompilerAdapter_RejectsOtherEngines(t *testing.T) {
+	engine := &fakeTypst{}
+	adapter := NewTypstCompilerAdapter(nil, infraadapters.NewOSFileSystem(), engine)
+
+	for _, name := range []string{ports.EnginePDFLaTeX, ports.EngineTectonic, "", "typst2"} {
+		_, err := adapter.Compile(context.Background(), "= Title", ports.NewCompileOptions(name, "doc.pdf", t.TempDir()))
+		assert.ErrorIs(t, err, ports.ErrUnsupportedEngine, name)
+	}
+	assert.Empty(t, engine.commands)
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	diagnostics := ParseDiagnostics("error: file not found (searched at /work/logo.png)\n" +
+		"compiled with errors\n" +
+		"chapters/intro.typ:12:5: warning: unused label\n")
+
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, ports.DiagnosticMissingFile, diagnostics[0].Kind)
+	assert.True(t, diagnostics[0].IsError())
+	assert.Empty(t, diagnostics[0].File)
+	assert.Equal(t, "chapters/intro.typ", diagnostics[1].File)
+	assert.Equal(t, 12, diagnostics[1].Line)
+	assert.False(t, diagnostics[1].IsError())
+}
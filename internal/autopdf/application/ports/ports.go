@@ -5,33 +5,273 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io/fs"
+	"strings"
 	"time"
 )
 
 // TemplateProcessor processes templates with variables
 // Pure transport types - no domain dependencies
 type TemplateProcessor interface {
-	Process(ctx context.Context, templatePath string, variables map[string]string) (string, error)
+	Process(ctx context.Context, templatePath string, data TemplateData) (string, error)
 }
 
+// TemplateData is the data a template is executed with, keyed by top-level variable name
+// Values keep their types: strings, bools, ints, float64s, []interface{} and map[string]interface{}
+type TemplateData map[string]interface{}
+
+// MissingVariableError is returned by TemplateProcessor implementations rendering strictly
+// when a template refers to a variable its data does not define
+type MissingVariableError struct {
+	Variable string // Path as written in the template, such as client.vat
+	File     string // Template or partial file referring to it
+	Line     int
+	Column   int
+	Snippet  string // The template line referring to it
+	Err      error
+}
+
+func (e *MissingVariableError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: variable %q is missing", e.File, e.Line, e.Column, e.Variable)
+}
+
+func (e *MissingVariableError) Unwrap() error { return e.Err }
+
 // LaTeXCompiler compiles LaTeX content to PDF
 // Pure transport types - no domain dependencies
 type LaTeXCompiler interface {
-	Compile(ctx context.Context, content string, opts CompileOptions) (string, error)
+	Compile(ctx context.Context, content string, opts CompileOptions) (CompileResult, error)
+}
+
+// CompileResult represents the outcome of a LaTeX compilation
+// Diagnostics are populated on success (warnings) as well as on failure
+type CompileResult struct {
+	PDFPath     string
+	Diagnostics []Diagnostic
+	Passes      int         // Number of engine passes actually run
+	Cache       CacheStatus // Whether the PDF came from the compile cache
+	CacheKey    string      // Hash of the compile inputs, set when a cache is in use
+}
+
+// CacheStatus reports how the compile cache served a compilation
+type CacheStatus string
+
+const (
+	CacheDisabled CacheStatus = ""         // No cache is configured
+	CacheHit      CacheStatus = "hit"      // The stored PDF was reused
+	CacheMiss     CacheStatus = "miss"     // The engine ran and its PDF was stored
+	CacheBypassed CacheStatus = "bypassed" // The cache was skipped for this build
+)
+
+// DiagnosticKind classifies a diagnostic extracted from the engine log or found by the template linter
+type DiagnosticKind string
+
+const (
+	DiagnosticError              DiagnosticKind = "error"
+	DiagnosticWarning            DiagnosticKind = "warning"
+	DiagnosticOverfullBox        DiagnosticKind = "overfull_box"
+	DiagnosticUnderfullBox       DiagnosticKind = "underfull_box"
+	DiagnosticUndefinedReference DiagnosticKind = "undefined_reference"
+	DiagnosticUndefinedCitation  DiagnosticKind = "undefined_citation"
+	DiagnosticMissingFile        DiagnosticKind = "missing_file"
+
+	// Found by linting a template
+	DiagnosticTemplateSyntax        DiagnosticKind = "template_syntax"
+	DiagnosticUndefinedVariable     DiagnosticKind = "undefined_variable"
+	DiagnosticUnusedVariable        DiagnosticKind = "unused_variable"
+	DiagnosticRangeNonList          DiagnosticKind = "range_non_list"
+	DiagnosticUnknownFunction       DiagnosticKind = "unknown_function"
+	DiagnosticUnbalancedEnvironment DiagnosticKind = "unbalanced_environment"
+	DiagnosticUnbalancedBrace       DiagnosticKind = "unbalanced_brace"
+)
+
+// DiagnosticSeverity tells whether a diagnostic prevented the build
+type DiagnosticSeverity string
+
+const (
+	SeverityError   DiagnosticSeverity = "error"
+	SeverityWarning DiagnosticSeverity = "warning"
+)
+
+// Diagnostic is a Value Object describing a single finding in the engine log
+type Diagnostic struct {
+	Kind     DiagnosticKind
+	Severity DiagnosticSeverity
+	File     string // Source file the engine was reading, empty if unknown
+	Line     int    // Line in File, 0 if unknown
+	Message  string
+	Context  string // Surrounding log lines (e.g. the offending source line)
+}
+
+// IsError reports whether the diagnostic is error-level
+func (d Diagnostic) IsError() bool {
+	return d.Severity == SeverityError
+}
+
+// String formats the diagnostic as "file:line: message"
+func (d Diagnostic) String() string {
+	switch {
+	case d.File != "" && d.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+	case d.File != "":
+		return fmt.Sprintf("%s: %s", d.File, d.Message)
+	case d.Line > 0:
+		return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+	default:
+		return d.Message
+	}
+}
+
+// CompilationError is returned by LaTeXCompiler implementations when the engine fails
+// It keeps the parsed diagnostics so callers can surface them instead of raw logs
+type CompilationError struct {
+	Diagnostics []Diagnostic
+	Err         error
+}
+
+func (e *CompilationError) Error() string {
+	var errs []string
+	for _, d := range e.Diagnostics {
+		if d.IsError() {
+			errs = append(errs, d.String())
+		}
+	}
+	if len(errs) == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %s", e.Err, strings.Join(errs, "; "))
+}
+
+func (e *CompilationError) Unwrap() error { return e.Err }
+
+// Engines AutoPDF compiles with
+const (
+	EnginePDFLaTeX = "pdflatex"
+	EngineXeLaTeX  = "xelatex"
+	EngineLuaLaTeX = "lualatex"
+	EngineTectonic = "tectonic" // Self-contained engine, run by its own compiler adapter
+	EngineTypst    = "typst"    // Typst markup rather than LaTeX, run by its own compiler adapter
+)
+
+// ErrUnsupportedEngine is returned for engines no compiler adapter runs
+var ErrUnsupportedEngine = errors.New("unsupported engine")
+
+// SupportedEngines lists every engine a compiler adapter exists for
+func SupportedEngines() []string {
+	return []string{EnginePDFLaTeX, EngineXeLaTeX, EngineLuaLaTeX, EngineTectonic, EngineTypst}
+}
+
+// SourceExtension returns the extension of the sources an engine compiles
+func SourceExtension(engine string) string {
+	if engine == EngineTypst {
+		return ".typ"
+	}
+	return ".tex"
+}
+
+// retiredEngines maps the engines older configs may name, and AutoPDF no longer runs, to their replacement
+var retiredEngines = map[string]string{
+	"latex": EnginePDFLaTeX, // latex writes DVI; pdflatex compiles the same sources to PDF
+}
+
+// ValidateEngine rejects engines no compiler adapter runs, instead of substituting another
+// Retired engines are rejected with the engine to set instead
+func ValidateEngine(engine string) error {
+	if replacement, ok := retiredEngines[engine]; ok {
+		return fmt.Errorf("%w %q: it writes DVI rather than PDF and is no longer supported; set engine to %s, which compiles the same sources",
+			ErrUnsupportedEngine, engine, replacement)
+	}
+	for _, supported := range SupportedEngines() {
+		if engine == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q (use %s)", ErrUnsupportedEngine, engine, strings.Join(SupportedEngines(), ", "))
 }
 
 // CompileOptions represents LaTeX compilation parameters
 // Value Object following DDD principles - immutable and validated
 type CompileOptions struct {
-	Engine     string // pdflatex, xelatex, lualatex
+	Engine     string // pdflatex, xelatex, lualatex, tectonic, typst
 	OutputPath string
 	WorkingDir string
-	Passes     int  // Number of compilation passes
+	Passes     int  // Number of compilation passes (the upper bound when AutoPasses is set)
+	AutoPasses bool // Whether to stop as soon as cross-references have converged
 	UseLatexmk bool // Whether to use latexmk
+	Toolchain  Toolchain
 	JobName    string
 	Cleanup    bool // Whether to cleanup aux files
 	Debug      bool // Whether debug mode is enabled
+	Security   SecurityPolicy
+	Inputs     []string // Files the document reads, relative to WorkingDir; part of the cache key, nil when unknown
+	SearchDirs []string // Directories the engine also finds inputs in, such as the template's when it runs elsewhere
+	NoCache    bool     // Whether to compile even when a cached PDF exists
+
+	requestedPasses int // Passes as given to WithPasses, resolved again when AutoPasses changes
+}
+
+// DefaultAutoPasses is the cap of AutoPasses when no number of passes is given
+const DefaultAutoPasses = 5
+
+// Tool selection values shared by every Toolchain field
+const (
+	ToolAuto           = "auto" // Detect the need from the files the engine wrote
+	ToolNone           = "none" // Never run the tool
+	ToolBibtex         = "bibtex"
+	ToolBiber          = "biber"
+	ToolMakeindex      = "makeindex"
+	ToolMakeglossaries = "makeglossaries"
+)
+
+// Toolchain selects the bibliography, index and glossary tools run between engine passes
+// Value Object: empty fields mean ToolAuto
+type Toolchain struct {
+	Bibliography string // auto, bibtex, biber, none
+	Index        string // auto, makeindex, none
+	Glossary     string // auto, makeglossaries, none
+}
+
+// NewToolchain creates a Toolchain, defaulting empty selections to auto
+func NewToolchain(bibliography, index, glossary string) Toolchain {
+	orAuto := func(tool string) string {
+		if tool == "" {
+			return ToolAuto
+		}
+		return tool
+	}
+	return Toolchain{
+		Bibliography: orAuto(bibliography),
+		Index:        orAuto(index),
+		Glossary:     orAuto(glossary),
+	}
+}
+
+// Validate checks that every selection is supported
+func (t Toolchain) Validate() error {
+	checks := []struct {
+		name, value string
+		allowed     []string
+	}{
+		{"bibliography", t.Bibliography, []string{ToolBibtex, ToolBiber}},
+		{"index", t.Index, []string{ToolMakeindex}},
+		{"glossary", t.Glossary, []string{ToolMakeglossaries}},
+	}
+	for _, c := range checks {
+		if c.value == "" || c.value == ToolAuto || c.value == ToolNone {
+			continue
+		}
+		supported := false
+		for _, tool := range c.allowed {
+			supported = supported || c.value == tool
+		}
+		if !supported {
+			return fmt.Errorf("unsupported %s tool %q (use %s, %s or %s)",
+				c.name, c.value, ToolAuto, strings.Join(c.allowed, ", "), ToolNone)
+		}
+	}
+	return nil
 }
 
 // NewCompileOptions creates a validated CompileOptions with defaults
@@ -41,22 +281,45 @@ func NewCompileOptions(engine, outputPath, workingDir string) CompileOptions {
 		OutputPath: outputPath,
 		WorkingDir: workingDir,
 		Passes:     1,
+		AutoPasses: false,
 		UseLatexmk: false,
+		Toolchain:  NewToolchain("", "", ""),
 		JobName:    "document",
 		Cleanup:    true,
 		Debug:      false,
 	}
 }
 
-// WithPasses sets the number of compilation passes
+// WithPasses sets the number of compilation passes, between 1 and 10
+// With AutoPasses, passes below 1 cap the reruns at DefaultAutoPasses
 func (opts CompileOptions) WithPasses(passes int) CompileOptions {
-	if passes < 1 {
-		passes = 1
-	}
-	if passes > 10 {
-		passes = 10
+	opts.requestedPasses = passes
+	opts.Passes = resolvePasses(passes, opts.AutoPasses)
+	return opts
+}
+
+// WithAutoPasses enables rerun detection, treating Passes as the cap
+func (opts CompileOptions) WithAutoPasses(autoPasses bool) CompileOptions {
+	opts.AutoPasses = autoPasses
+	opts.Passes = resolvePasses(opts.requestedPasses, autoPasses)
+	return opts
+}
+
+func resolvePasses(passes int, autoPasses bool) int {
+	switch {
+	case passes < 1 && autoPasses:
+		return DefaultAutoPasses
+	case passes < 1:
+		return 1
+	case passes > 10:
+		return 10
 	}
-	opts.Passes = passes
+	return passes
+}
+
+// WithToolchain sets the bibliography, index and glossary tools
+func (opts CompileOptions) WithToolchain(toolchain Toolchain) CompileOptions {
+	opts.Toolchain = toolchain
 	return opts
 }
 
@@ -87,6 +350,30 @@ func (opts CompileOptions) WithDebug(debug bool) CompileOptions {
 	return opts
 }
 
+// WithSecurity sets the policy every engine and tool run is subject to
+func (opts CompileOptions) WithSecurity(policy SecurityPolicy) CompileOptions {
+	opts.Security = policy
+	return opts
+}
+
+// WithInputs sets the files besides the source that the compilation depends on
+func (opts CompileOptions) WithInputs(inputs []string) CompileOptions {
+	opts.Inputs = inputs
+	return opts
+}
+
+// WithSearchDirs sets the directories the engine also finds inputs in
+func (opts CompileOptions) WithSearchDirs(dirs []string) CompileOptions {
+	opts.SearchDirs = dirs
+	return opts
+}
+
+// WithNoCache disables reusing a cached PDF for this compilation
+func (opts CompileOptions) WithNoCache(noCache bool) CompileOptions {
+	opts.NoCache = noCache
+	return opts
+}
+
 // Converter converts PDFs to images
 // Pure transport types - no domain dependencies
 type Converter interface {
@@ -99,6 +386,192 @@ type Cleaner interface {
 	Clean(ctx context.Context, pdfPath string) error
 }
 
+// WorkspaceManager gives each build its own directory (DIP for build isolation)
+// Concurrent builds never share .tex, .aux or .pdf files
+type WorkspaceManager interface {
+	// Create allocates a new, empty workspace for the job
+	Create(ctx context.Context, jobName string) (Workspace, error)
+	// Stage links the entries of sourceDir into the workspace, except the job's own files;
+	// the fallback for builds wired without an AssetStager
+	Stage(ctx context.Context, ws Workspace, sourceDir string) error
+	// Release marks the build as finished and applies the retention policy
+	Release(ctx context.Context, ws Workspace, failed bool) error
+}
+
+// Workspace is a Value Object describing a build directory
+type Workspace struct {
+	ID        string
+	Path      string
+	JobName   string
+	CreatedAt time.Time
+}
+
+// RetentionMode selects what happens to released workspaces
+type RetentionMode string
+
+const (
+	RetentionDelete     RetentionMode = "delete"      // Remove as soon as the build is done
+	RetentionKeepLast   RetentionMode = "keep_last"   // Keep the N most recently released
+	RetentionKeepFailed RetentionMode = "keep_failed" // Remove successful builds, keep failed ones
+	RetentionTTL        RetentionMode = "ttl"         // Keep for a duration after release
+)
+
+// RetentionPolicy is a Value Object deciding how long workspaces are kept
+// KeepLast and TTL also bound RetentionKeepFailed when set
+type RetentionPolicy struct {
+	Mode     RetentionMode
+	KeepLast int
+	TTL      time.Duration
+}
+
+// NewRetentionPolicy creates a RetentionPolicy, defaulting an empty mode to delete
+func NewRetentionPolicy(mode string, keepLast int, ttl time.Duration) RetentionPolicy {
+	if mode == "" {
+		mode = string(RetentionDelete)
+	}
+	return RetentionPolicy{
+		Mode:     RetentionMode(mode),
+		KeepLast: keepLast,
+		TTL:      ttl,
+	}
+}
+
+// Validate checks the mode and the parameters it needs
+func (p RetentionPolicy) Validate() error {
+	switch p.Mode {
+	case RetentionDelete, RetentionKeepFailed:
+	case RetentionKeepLast:
+		if p.KeepLast < 1 {
+			return fmt.Errorf("retention %s needs keep_last >= 1, got %d", p.Mode, p.KeepLast)
+		}
+	case RetentionTTL:
+		if p.TTL <= 0 {
+			return fmt.Errorf("retention %s needs a positive ttl, got %s", p.Mode, p.TTL)
+		}
+	default:
+		return fmt.Errorf("unsupported workspace retention %q (use %s, %s, %s or %s)",
+			p.Mode, RetentionDelete, RetentionKeepLast, RetentionKeepFailed, RetentionTTL)
+	}
+	if p.KeepLast < 0 || p.TTL < 0 {
+		return fmt.Errorf("keep_last and ttl must not be negative")
+	}
+	return nil
+}
+
+// AssetStager makes the files a document depends on available where the engine runs
+type AssetStager interface {
+	// Stage scans the processed LaTeX for local dependencies and links or copies them
+	// from sourceDir into targetDir; the files are listed also when both directories are the same
+	Stage(ctx context.Context, content, sourceDir, targetDir string) (StagedAssets, error)
+}
+
+// StagedAssets are the dependencies an AssetStager found
+// Value Object: Files is never nil, so an empty list still means the inputs are known
+type StagedAssets struct {
+	Files    []string // Staged files and directories, relative to the target directory
+	Unstaged []string // Existing references outside the source directory, absolute or cleaned ../ paths, left where they are
+}
+
+// LeavesSourceDir reports whether a relative reference reaches above the source directory;
+// those resolve only when the engine runs in the source directory itself
+func (a StagedAssets) LeavesSourceDir() bool {
+	for _, ref := range a.Unstaged {
+		if strings.HasPrefix(ref, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputPathRenderer renders an output path written as a template with the document's variables,
+// such as out/{{.client.slug}}/invoice-{{.invoice.number}}.pdf
+type OutputPathRenderer interface {
+	RenderOutputPath(ctx context.Context, pattern string, data TemplateData) (string, error)
+}
+
+// Output collision policies: what a build does when its output file already exists
+const (
+	OutputCollisionOverwrite = "overwrite" // Replace the existing file (default)
+	OutputCollisionFail      = "fail"      // Fail before the template is processed
+	OutputCollisionSuffix    = "suffix"    // Write name-1.pdf, name-2.pdf, ... next to it
+)
+
+// ErrOutputExists is returned for an existing output under the fail collision policy
+var ErrOutputExists = errors.New("output already exists")
+
+// ValidateOutputCollision rejects unknown collision policies; empty means overwrite
+func ValidateOutputCollision(policy string) error {
+	switch policy {
+	case "", OutputCollisionOverwrite, OutputCollisionFail, OutputCollisionSuffix:
+		return nil
+	}
+	return fmt.Errorf("unsupported output collision policy %q (use %s, %s or %s)",
+		policy, OutputCollisionOverwrite, OutputCollisionFail, OutputCollisionSuffix)
+}
+
+// Asset staging modes
+const (
+	AssetModeSymlink = "symlink" // Link each asset (default)
+	AssetModeCopy    = "copy"    // Copy each asset, for engines that cannot follow links
+)
+
+// Shell escape modes of a SecurityPolicy
+const (
+	ShellEscapeNone       = "none"       // \write18 is disabled
+	ShellEscapeRestricted = "restricted" // Only the installation's shell_escape_commands may run
+)
+
+// SecurityPolicy is a Value Object restricting what a compile may do on the host
+// The zero value imposes no restrictions; NewSandboxPolicy suits untrusted templates
+type SecurityPolicy struct {
+	ShellEscape      string        // none or restricted; empty keeps the TeX installation's setting
+	ParanoidFiles    bool          // openin_any/openout_any=p: no dotfiles, parent directories or absolute paths
+	CleanEnv         bool          // Start from a minimal environment instead of inheriting the caller's
+	CPUTime          time.Duration // CPU time limit per process
+	MemoryBytes      int64         // Address space limit per process
+	FileSizeBytes    int64         // Largest file a process may write
+	KillProcessGroup bool          // Kill every process the command started when it is cancelled
+}
+
+// NewSandboxPolicy creates the restrictive policy used for templates from untrusted sources
+func NewSandboxPolicy() SecurityPolicy {
+	return SecurityPolicy{
+		ShellEscape:      ShellEscapeNone,
+		ParanoidFiles:    true,
+		CleanEnv:         true,
+		CPUTime:          2 * time.Minute,
+		MemoryBytes:      4 << 30,
+		FileSizeBytes:    512 << 20,
+		KillProcessGroup: true,
+	}
+}
+
+// Enabled reports whether the policy restricts anything
+func (p SecurityPolicy) Enabled() bool {
+	return p != SecurityPolicy{}
+}
+
+// HasLimits reports whether any resource limit is set
+func (p SecurityPolicy) HasLimits() bool {
+	return p.CPUTime > 0 || p.MemoryBytes > 0 || p.FileSizeBytes > 0
+}
+
+// Validate checks the shell escape mode and the limits
+func (p SecurityPolicy) Validate() error {
+	switch p.ShellEscape {
+	case "", ShellEscapeNone, ShellEscapeRestricted:
+	default:
+		return fmt.Errorf("unsupported shell escape mode %q (use %s or %s)", p.ShellEscape, ShellEscapeNone, ShellEscapeRestricted)
+	}
+	if p.CPUTime < 0 || p.MemoryBytes < 0 || p.FileSizeBytes < 0 {
+		return fmt.Errorf("resource limits must not be negative")
+	}
+	if p.CPUTime > 0 && p.CPUTime < time.Second {
+		return fmt.Errorf("cpu time limit must be at least 1s, got %s", p.CPUTime)
+	}
+	return nil
+}
+
 // FontValidator checks if required fonts are available
 type FontValidator interface {
 	ValidateFonts(ctx context.Context, fontNames []string) (ValidationResult, error)
@@ -120,6 +593,10 @@ type FileSystem interface {
 	Remove(ctx context.Context, path string) error
 	Stat(ctx context.Context, path string) (FileInfo, error)
 	IsNotExist(err error) bool
+	// CreateExclusive creates an empty file, failing with an error IsExist recognizes when
+	// the path exists, so of concurrent callers only one creates it
+	CreateExclusive(ctx context.Context, path string, perm fs.FileMode) error
+	IsExist(err error) bool
 
 	// Directory operations
 	MkdirAll(ctx context.Context, path string, perm fs.FileMode) error
@@ -167,6 +644,7 @@ type Command struct {
 	Dir        string
 	Env        []string
 	Timeout    time.Duration
+	Security   SecurityPolicy // Enforced by a sandboxing CommandExecutor
 }
 
 // NewCommand creates a validated command
@@ -191,6 +669,12 @@ func (c Command) WithEnv(env []string) Command {
 	return c
 }
 
+// WithSecurity sets the policy the command runs under
+func (c Command) WithSecurity(policy SecurityPolicy) Command {
+	c.Security = policy
+	return c
+}
+
 // CommandResult represents the result of a command execution
 type CommandResult struct {
 	Stdout   string
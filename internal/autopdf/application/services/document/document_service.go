@@ -5,6 +5,8 @@ package document
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	"github.com/BuddhiLW/AutoPDF/pkg/config"
@@ -20,6 +22,9 @@ type DocumentService struct {
 	Cleaner           ports.Cleaner
 	PathOps           ports.PathOperations
 	FileSystem        ports.FileSystem
+	Workspaces        ports.WorkspaceManager   // Optional: isolates each build in its own directory
+	AssetStager       ports.AssetStager        // Optional: stages only the files the document references
+	OutputPaths       ports.OutputPathRenderer // Optional: renders output paths written as templates
 	ErrorFactory      *apperrors.DomainErrorFactory
 }
 
@@ -27,15 +32,22 @@ type DocumentService struct {
 type BuildRequest struct {
 	TemplatePath string
 	ConfigPath   string
+	Profile      string            // Config profile the request was resolved with, reported in the result
 	Variables    *config.Variables // Use complex variables from pkg/
+	Schema       *config.Schema    // Validates the variables before the template is processed
 	Engine       string
-	OutputPath   string
-	WorkingDir   string // Working directory for LaTeX compilation (where assets are symlinked)
+	OutputPath   string // May be a template rendered with the variables when OutputPaths is set
+	OnCollision  string // What to do when the output exists: overwrite (default), fail or suffix
+	WorkingDir   string // Asset directory (defaults to the template's); the engine runs here only without a workspace manager
 	DoConvert    bool
 	DoClean      bool
-	DebugEnabled bool // Enable debug mode for persistent concrete files
-	Passes       int  // Number of compilation passes
-	UseLatexmk   bool // Whether to use latexmk
+	DebugEnabled bool                 // Enable debug mode for persistent concrete files
+	Passes       int                  // Number of compilation passes (the cap when AutoPasses is set)
+	AutoPasses   bool                 // Stop rerunning once cross-references have converged
+	UseLatexmk   bool                 // Whether to use latexmk
+	Toolchain    ports.Toolchain      // Bibliography, index and glossary tools
+	Security     ports.SecurityPolicy // Restrictions on the engine and tool runs
+	NoCache      bool                 // Compile even when the compile cache holds the PDF
 	Conversion   ConversionSettings
 }
 
@@ -47,40 +59,74 @@ type ConversionSettings struct {
 
 // BuildResult encapsulates the results of building a document
 type BuildResult struct {
-	PDFPath    string
-	ImagePaths []string
-	Success    bool
-	Error      error
+	PDFPath     string // The rendered output path, suffixed when it already existed
+	ImagePaths  []string
+	Diagnostics []ports.Diagnostic // Parsed from the engine log, on success and failure
+	Passes      int                // Number of engine passes actually run
+	Workspace   string             // Directory the engine ran in; kept after the build in debug mode
+	Assets      []string           // Files staged into the working directory, relative to it
+	Cache       ports.CacheStatus  // hit, miss or bypassed; empty without a compile cache
+	CacheKey    string             // Hash of the compile inputs when a compile cache is used
+	Profile     string             // Config profile the document was built with; empty for none
+	Success     bool
+	Error       error
 }
 
 // Build orchestrates the entire document generation workflow:
-// 1. Process template with variables
-// 2. Compile LaTeX to PDF
-// 3. Optionally convert PDF to images
-// 4. Optionally clean auxiliary files
+// 1. Render the output path and apply the collision policy
+// 2. Process template with variables
+// 3. Compile LaTeX to PDF
+// 4. Optionally convert PDF to images
+// 5. Optionally clean auxiliary files
 func (s *DocumentService) Build(ctx context.Context, req BuildRequest) (BuildResult, error) {
-	// Step 1: Convert complex variables to simple map for template processing
-	simpleVariables := make(map[string]string)
+	// An unknown engine fails before any work is done, rather than falling back to another engine
+	if err := ports.ValidateEngine(req.Engine); err != nil {
+		return BuildResult{
+			Success: false,
+			Error:   s.ErrorFactory.EngineInvalid(req.Engine),
+		}, err
+	}
+
+	// Step 1: Convert the variables to template data, keeping their types and nesting
+	data := ports.TemplateData{}
 	if req.Variables != nil {
-		// Flatten complex variables to simple key-value pairs
-		simpleVariables = req.Variables.Flatten()
+		data = req.Variables.TemplateData()
+	}
+	if fields := req.Schema.Validate(data); len(fields) > 0 {
+		err := &config.SchemaError{Fields: fields}
+		return BuildResult{
+			Success: false,
+			Error:   s.ErrorFactory.VariablesInvalid(req.TemplatePath, err.Messages()),
+		}, err
 	}
 
-	// Step 2: Process template
-	processedContent, err := s.TemplateProcessor.Process(ctx, req.TemplatePath, simpleVariables)
+	// Step 2: Render the output path and settle where the PDF goes before any work is done
+	outputPath, reserved, err := s.resolveOutputPath(ctx, req, data)
 	if err != nil {
 		return BuildResult{
 			Success: false,
-			Error:   s.ErrorFactory.TemplateProcessingFailed(req.TemplatePath, err),
+			Error:   s.outputPathFailed(req.OutputPath, outputPath, err),
 		}, err
 	}
+	req.OutputPath = outputPath
 
-	// Step 3: Compile LaTeX to PDF
-	// Note: Asset symlinks should be set up by the calling strategy (e.g., AutoPDFGenerationStrategy)
-	// Use the working directory from BuildRequest if provided, otherwise derive from output path
-	workingDir := req.WorkingDir
-	if workingDir == "" {
-		workingDir = s.PathOps.Dir(req.OutputPath)
+	result, err := s.buildTo(ctx, req, data)
+	if err != nil && reserved {
+		// Release the path the collision policy reserved, so a later build can take it
+		_ = s.FileSystem.Remove(ctx, outputPath)
+	}
+	return result, err
+}
+
+// buildTo processes, compiles and converts the document once its output path is settled
+func (s *DocumentService) buildTo(ctx context.Context, req BuildRequest, data ports.TemplateData) (BuildResult, error) {
+	// Step 3: Process template
+	processedContent, err := s.TemplateProcessor.Process(ctx, req.TemplatePath, data)
+	if err != nil {
+		return BuildResult{
+			Success: false,
+			Error:   s.templateProcessingFailed(req.TemplatePath, err),
+		}, err
 	}
 
 	// Extract jobname from output path (base filename without extension)
@@ -93,30 +139,234 @@ func (s *DocumentService) Build(ctx context.Context, req BuildRequest) (BuildRes
 		jobName = jobName[:len(jobName)-len(ext)]
 	}
 	// Fallback to "document" if extraction fails
-	if jobName == "" {
+	if jobName == "" || jobName == "." {
 		jobName = "document"
 	}
 
-	compileOptions := ports.NewCompileOptions(req.Engine, req.OutputPath, workingDir).
+	// Step 4: Prepare the directory the engine runs in
+	// With a workspace manager each build gets its own directory, so concurrent builds
+	// never share .tex, .aux or .pdf files; the asset directory is staged into it
+	// A document reading files above the asset directory compiles in the asset directory
+	// instead, since ../ paths resolve from where the engine runs and a workspace holds none
+	workingDir := req.WorkingDir
+	compileOutput := req.OutputPath
+	assetDir := req.WorkingDir
+	if assetDir == "" {
+		assetDir = s.PathOps.Dir(req.TemplatePath)
+	}
+	var workspace *ports.Workspace
+	var staged ports.StagedAssets
+	if s.Workspaces != nil {
+		ws, assets, err := s.createWorkspace(ctx, processedContent, assetDir, jobName)
+		if err != nil {
+			return BuildResult{
+				Success: false,
+				Error:   s.ErrorFactory.WorkspaceSetupFailed(req.TemplatePath, err),
+			}, err
+		}
+		staged = assets
+		if assets.LeavesSourceDir() {
+			_ = s.Workspaces.Release(ctx, ws, false)
+			workingDir = assetDir
+		} else {
+			workspace = &ws
+			workingDir = ws.Path
+			// The engine writes the PDF and its auxiliary files inside the workspace;
+			// the PDF is copied to the requested output once the build succeeded
+			compileOutput = s.PathOps.Join(ws.Path, jobName+".pdf")
+		}
+	}
+	if workingDir == "" {
+		workingDir = s.PathOps.Dir(req.OutputPath)
+		// Without a workspace the engine runs next to the output; bring the template's assets there
+		if s.AssetStager != nil {
+			assets, err := s.AssetStager.Stage(ctx, processedContent, assetDir, workingDir)
+			if err != nil {
+				return BuildResult{
+					Success: false,
+					Error:   s.ErrorFactory.WorkspaceSetupFailed(req.TemplatePath, err),
+				}, err
+			}
+			staged = assets
+			if assets.LeavesSourceDir() {
+				workingDir = assetDir
+			}
+		}
+	}
+	// Files the stager could not find, such as those a class loads through a macro,
+	// are still looked up in the asset directory
+	var searchDirs []string
+	if workingDir != assetDir {
+		searchDirs = []string{assetDir}
+	}
+
+	result, err := s.compile(ctx, req, processedContent, workingDir, compileOutput, jobName, staged, searchDirs)
+	result.Assets = staged.Files
+	result.Profile = req.Profile
+	if workspace != nil {
+		result.Workspace = workspace.Path
+		// Debug builds keep their workspace for inspection; without a requested
+		// output path the PDF lives in the workspace, so it is kept for the caller
+		if !req.DebugEnabled && req.OutputPath != "" {
+			if releaseErr := s.Workspaces.Release(ctx, *workspace, err != nil); releaseErr != nil && result.Error == nil {
+				result.Error = s.ErrorFactory.CleanupFailed(workspace.Path, releaseErr)
+			}
+		}
+	}
+	return result, err
+}
+
+// resolveOutputPath renders the requested output path with the template data, creates the
+// directory it is in and applies the collision policy to the rendered path; an existing output
+// under the fail policy is returned with ports.ErrOutputExists
+// The fail and suffix policies reserve the path they settle on with an empty file, created only
+// when none exists, so concurrent builds never settle on the same path; reserved reports it
+func (s *DocumentService) resolveOutputPath(ctx context.Context, req BuildRequest, data ports.TemplateData) (outputPath string, reserved bool, err error) {
+	if err := ports.ValidateOutputCollision(req.OnCollision); err != nil {
+		return "", false, err
+	}
+	outputPath = req.OutputPath
+	if outputPath == "" {
+		return "", false, nil
+	}
+	if s.OutputPaths != nil {
+		rendered, err := s.OutputPaths.RenderOutputPath(ctx, outputPath, data)
+		if err != nil {
+			return "", false, err
+		}
+		outputPath = rendered
+	}
+	if s.FileSystem == nil {
+		return outputPath, false, nil
+	}
+
+	if err := s.FileSystem.MkdirAll(ctx, s.PathOps.Dir(outputPath), 0755); err != nil {
+		return "", false, err
+	}
+	switch req.OnCollision {
+	case ports.OutputCollisionFail:
+		created, err := s.reserve(ctx, outputPath)
+		if err != nil {
+			return "", false, err
+		}
+		if !created {
+			return outputPath, false, fmt.Errorf("%w: %s", ports.ErrOutputExists, outputPath)
+		}
+		return outputPath, true, nil
+	case ports.OutputCollisionSuffix:
+		ext := s.PathOps.Ext(outputPath)
+		stem := outputPath[:len(outputPath)-len(ext)]
+		for i := 1; ; i++ {
+			created, err := s.reserve(ctx, outputPath)
+			if err != nil {
+				return "", false, err
+			}
+			if created {
+				return outputPath, true, nil
+			}
+			outputPath = fmt.Sprintf("%s-%d%s", stem, i, ext)
+		}
+	}
+	return outputPath, false, nil
+}
+
+// outputPathFailed builds the output path error of the requested pattern, blaming the rendered
+// path when it exists under the fail policy
+func (s *DocumentService) outputPathFailed(pattern, rendered string, err error) error {
+	if errors.Is(err, ports.ErrOutputExists) {
+		return s.ErrorFactory.OutputExists(rendered)
+	}
+	return s.ErrorFactory.OutputPathInvalid(pattern, err)
+}
+
+// reserve creates an empty file at path, reporting false when a file is already there
+func (s *DocumentService) reserve(ctx context.Context, path string) (bool, error) {
+	err := s.FileSystem.CreateExclusive(ctx, path, 0644)
+	if err == nil {
+		return true, nil
+	}
+	if s.FileSystem.IsExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// createWorkspace allocates a workspace for the job and stages the assets into it:
+// the referenced files when an AssetStager is set, the whole asset directory otherwise
+func (s *DocumentService) createWorkspace(ctx context.Context, content, assetDir, jobName string) (ports.Workspace, ports.StagedAssets, error) {
+	ws, err := s.Workspaces.Create(ctx, jobName)
+	if err != nil {
+		return ports.Workspace{}, ports.StagedAssets{}, err
+	}
+
+	var staged ports.StagedAssets
+	if s.AssetStager != nil {
+		staged, err = s.AssetStager.Stage(ctx, content, assetDir, ws.Path)
+	} else {
+		err = s.Workspaces.Stage(ctx, ws, assetDir)
+	}
+	if err != nil {
+		_ = s.Workspaces.Release(ctx, ws, true)
+		return ports.Workspace{}, ports.StagedAssets{}, err
+	}
+	return ws, staged, nil
+}
+
+// compile runs the engine in workingDir, moves the PDF to the requested output
+// and applies the optional conversion and cleanup steps; staged are the inputs keying the compile cache,
+// and searchDirs the directories the engine also looks up inputs in
+func (s *DocumentService) compile(ctx context.Context, req BuildRequest, processedContent, workingDir, compileOutput, jobName string, staged ports.StagedAssets, searchDirs []string) (BuildResult, error) {
+	// Step 5: Compile LaTeX to PDF
+	// Files read from outside the asset directory cannot be keyed, which leaves the inputs unknown
+	inputs := staged.Files
+	if len(staged.Unstaged) > 0 {
+		inputs = nil
+	}
+	compileOptions := ports.NewCompileOptions(req.Engine, compileOutput, workingDir).
 		WithDebug(req.DebugEnabled).
 		WithPasses(req.Passes).
+		WithAutoPasses(req.AutoPasses).
 		WithLatexmk(req.UseLatexmk).
+		WithToolchain(req.Toolchain).
+		WithSecurity(req.Security).
+		WithInputs(inputs).
+		WithSearchDirs(searchDirs).
+		WithNoCache(req.NoCache).
 		WithJobName(jobName) // Set jobname from output path
 
-	pdfPath, err := s.LaTeXCompiler.Compile(ctx, processedContent, compileOptions)
+	compileResult, err := s.LaTeXCompiler.Compile(ctx, processedContent, compileOptions)
 	if err != nil {
 		return BuildResult{
-			Success: false,
-			Error:   s.ErrorFactory.LaTeXCompilationFailed(req.OutputPath, err),
+			Diagnostics: compileResult.Diagnostics,
+			Cache:       compileResult.Cache,
+			CacheKey:    compileResult.CacheKey,
+			Success:     false,
+			Error:       s.ErrorFactory.LaTeXCompilationFailed(req.OutputPath, err, errorDiagnostics(compileResult.Diagnostics)...),
 		}, err
 	}
 
+	pdfPath := compileResult.PDFPath
+	if req.OutputPath != "" && compileOutput != req.OutputPath {
+		if err := s.copyFile(ctx, pdfPath, req.OutputPath); err != nil {
+			return BuildResult{
+				Diagnostics: compileResult.Diagnostics,
+				Success:     false,
+				Error:       s.ErrorFactory.LaTeXCompilationFailed(req.OutputPath, err),
+			}, err
+		}
+		pdfPath = req.OutputPath
+	}
+
 	result := BuildResult{
-		PDFPath: pdfPath,
-		Success: true,
+		PDFPath:     pdfPath,
+		Diagnostics: compileResult.Diagnostics,
+		Passes:      compileResult.Passes,
+		Cache:       compileResult.Cache,
+		CacheKey:    compileResult.CacheKey,
+		Success:     true,
 	}
 
-	// Step 5: Optionally convert PDF to images
+	// Step 6: Optionally convert PDF to images
 	if req.DoConvert && req.Conversion.Enabled {
 		imagePaths, err := s.Converter.ConvertToImages(ctx, pdfPath, req.Conversion.Formats)
 		if err != nil {
@@ -127,7 +377,7 @@ func (s *DocumentService) Build(ctx context.Context, req BuildRequest) (BuildRes
 		}
 	}
 
-	// Step 6: Optionally clean auxiliary files
+	// Step 7: Optionally clean auxiliary files
 	if req.DoClean {
 		if err := s.Cleaner.Clean(ctx, pdfPath); err != nil {
 			// Log warning but don't fail the build
@@ -138,6 +388,39 @@ func (s *DocumentService) Build(ctx context.Context, req BuildRequest) (BuildRes
 	return result, nil
 }
 
+// copyFile copies the compiled PDF out of the workspace, creating the destination directory
+func (s *DocumentService) copyFile(ctx context.Context, src, dst string) error {
+	data, err := s.FileSystem.ReadFile(ctx, src)
+	if err != nil {
+		return err
+	}
+	if err := s.FileSystem.MkdirAll(ctx, s.PathOps.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return s.FileSystem.WriteFile(ctx, dst, data, 0644)
+}
+
+// templateProcessingFailed builds the template error, locating a variable a strict template
+// refers to and the request does not define
+func (s *DocumentService) templateProcessingFailed(templatePath string, err error) error {
+	var missing *ports.MissingVariableError
+	if errors.As(err, &missing) {
+		err = s.ErrorFactory.VariableMissing(missing.Variable, missing.File, missing.Line, missing.Column, missing.Snippet)
+	}
+	return s.ErrorFactory.TemplateProcessingFailed(templatePath, err)
+}
+
+// errorDiagnostics formats the error-level diagnostics as "file:line: message" strings
+func errorDiagnostics(diagnostics []ports.Diagnostic) []string {
+	var errs []string
+	for _, d := range diagnostics {
+		if d.IsError() {
+			errs = append(errs, d.String())
+		}
+	}
+	return errs
+}
+
 // ConvertDocument converts an existing PDF to images
 func (s *DocumentService) ConvertDocument(ctx context.Context, pdfPath string, formats []string) ([]string, error) {
 	return s.Converter.ConvertToImages(ctx, pdfPath, formats)
@@ -6,11 +6,18 @@ package document
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/assets"
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
 	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	apperrors "github.com/BuddhiLW/AutoPDF/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock implementations for testing
@@ -19,8 +26,8 @@ type MockTemplateProcessor struct {
 	mock.Mock
 }
 
-func (m *MockTemplateProcessor) Process(ctx context.Context, templatePath string, variables map[string]string) (string, error) {
-	args := m.Called(ctx, templatePath, variables)
+func (m *MockTemplateProcessor) Process(ctx context.Context, templatePath string, data ports.TemplateData) (string, error) {
+	args := m.Called(ctx, templatePath, data)
 	return args.String(0), args.Error(1)
 }
 
@@ -28,9 +35,16 @@ type MockLaTeXCompiler struct {
 	mock.Mock
 }
 
-func (m *MockLaTeXCompiler) Compile(ctx context.Context, content string, engine string, outputPath string) (string, error) {
-	args := m.Called(ctx, content, engine, outputPath)
-	return args.String(0), args.Error(1)
+func (m *MockLaTeXCompiler) Compile(ctx context.Context, content string, opts ports.CompileOptions) (ports.CompileResult, error) {
+	args := m.Called(ctx, content, opts)
+	return args.Get(0).(ports.CompileResult), args.Error(1)
+}
+
+// compileOptionsFor matches CompileOptions by engine and output path
+func compileOptionsFor(engine, outputPath string) interface{} {
+	return mock.MatchedBy(func(opts ports.CompileOptions) bool {
+		return opts.Engine == engine && opts.OutputPath == outputPath
+	})
 }
 
 type MockConverter struct {
@@ -65,6 +79,8 @@ func TestDocumentService_Build_Success(t *testing.T) {
 		LaTeXCompiler:     mockTex,
 		Converter:         mockConv,
 		Cleaner:           mockClean,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
 	}
 
 	ctx := context.Background()
@@ -81,7 +97,7 @@ func TestDocumentService_Build_Success(t *testing.T) {
 	}
 
 	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("\\documentclass{article}...", nil)
-	mockTex.On("Compile", ctx, "\\documentclass{article}...", "pdflatex", "output.pdf").Return("output.pdf", nil)
+	mockTex.On("Compile", ctx, "\\documentclass{article}...", compileOptionsFor("pdflatex", "output.pdf")).Return(ports.CompileResult{PDFPath: "output.pdf"}, nil)
 
 	// Act
 	result, err := svc.Build(ctx, req)
@@ -107,6 +123,8 @@ func TestDocumentService_Build_TemplateProcessingFails(t *testing.T) {
 		LaTeXCompiler:     mockTex,
 		Converter:         mockConv,
 		Cleaner:           mockClean,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
 	}
 
 	ctx := context.Background()
@@ -128,10 +146,113 @@ func TestDocumentService_Build_TemplateProcessingFails(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.False(t, result.Success)
-	assert.Contains(t, result.Error.Error(), "template processing failed")
+	assert.Contains(t, result.Error.Error(), "TEMPLATE_PROCESSING_FAILED")
 	mockTpl.AssertExpectations(t)
 }
 
+func TestDocumentService_Build_MissingVariable(t *testing.T) {
+	// Arrange
+	mockTpl := new(MockTemplateProcessor)
+
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	ctx := context.Background()
+	req := BuildRequest{
+		TemplatePath: "template.tex",
+		Engine:       "pdflatex",
+		OutputPath:   "output.pdf",
+	}
+
+	missing := &ports.MissingVariableError{
+		Variable: "client.vat",
+		File:     "partials/letterhead.tex",
+		Line:     3,
+		Column:   5,
+		Snippet:  "VAT: delim[[.client.vat]]",
+		Err:      errors.New(`map has no entry for key "vat"`),
+	}
+	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("", missing)
+
+	// Act
+	result, err := svc.Build(ctx, req)
+
+	// Assert
+	assert.ErrorIs(t, err, missing)
+	assert.False(t, result.Success)
+	var domainErr *apperrors.DomainError
+	require.True(t, errors.As(result.Error, &domainErr))
+	assert.Equal(t, "TEMPLATE_PROCESSING_FAILED", domainErr.Code)
+	assert.Equal(t, `partials/letterhead.tex:3:5: "client.vat" in: VAT: delim[[.client.vat]]`, domainErr.Blame)
+	assert.Equal(t, "client.vat", domainErr.Details["variable"])
+	assert.Equal(t, "partials/letterhead.tex", domainErr.Details["template"])
+	assert.Equal(t, 3, domainErr.Details["line"])
+	assert.Equal(t, 5, domainErr.Details["column"])
+	assert.Equal(t, "VAT: delim[[.client.vat]]", domainErr.Details["snippet"])
+	var causeErr *apperrors.DomainError
+	require.True(t, errors.As(domainErr.Cause, &causeErr))
+	assert.Equal(t, apperrors.CodeVariableMissing, causeErr.Code)
+	mockTpl.AssertExpectations(t)
+}
+
+func TestDocumentService_Build_SchemaRejectsVariables(t *testing.T) {
+	// Arrange
+	mockTpl := new(MockTemplateProcessor)
+
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	vars := config.NewVariables()
+	items := config.NewSliceVariable()
+	for _, price := range []float64{10, 5, -1} {
+		item := config.NewMapVariable()
+		item.Values["price"] = &config.NumberVariable{Value: price}
+		items.Values = append(items.Values, item)
+	}
+	vars.Set("items", items)
+
+	minimum := 0.0
+	req := BuildRequest{
+		TemplatePath: "invoice.tex",
+		Engine:       "pdflatex",
+		OutputPath:   "invoice.pdf",
+		Variables:    vars,
+		Schema: &config.Schema{
+			Type:     "object",
+			Required: []string{"client"},
+			Properties: map[string]*config.Schema{
+				"items": {Type: "array", Items: &config.Schema{
+					Type:       "object",
+					Properties: map[string]*config.Schema{"price": {Type: "number", Minimum: &minimum}},
+				}},
+			},
+		},
+	}
+
+	// Act
+	result, err := svc.Build(context.Background(), req)
+
+	// Assert: the template is never processed
+	var schemaErr *config.SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, []config.FieldError{
+		{Field: "client", Message: "is required"},
+		{Field: "items[2].price", Message: "must be >= 0"},
+	}, schemaErr.Fields)
+	assert.False(t, result.Success)
+	var domainErr *apperrors.DomainError
+	require.True(t, errors.As(result.Error, &domainErr))
+	assert.Equal(t, "VARIABLES_INVALID", domainErr.Code)
+	assert.Equal(t, []string{"client: is required", "items[2].price: must be >= 0"}, domainErr.Details["fields"])
+	mockTpl.AssertNotCalled(t, "Process", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestDocumentService_Build_LaTeXCompilationFails(t *testing.T) {
 	// Arrange
 	mockTpl := new(MockTemplateProcessor)
@@ -144,6 +265,8 @@ func TestDocumentService_Build_LaTeXCompilationFails(t *testing.T) {
 		LaTeXCompiler:     mockTex,
 		Converter:         mockConv,
 		Cleaner:           mockClean,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
 	}
 
 	ctx := context.Background()
@@ -158,7 +281,46 @@ func TestDocumentService_Build_LaTeXCompilationFails(t *testing.T) {
 	}
 
 	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("\\documentclass{article}...", nil)
-	mockTex.On("Compile", ctx, "\\documentclass{article}...", "pdflatex", "output.pdf").Return("", errors.New("compilation error"))
+	mockTex.On("Compile", ctx, "\\documentclass{article}...", compileOptionsFor("pdflatex", "output.pdf")).Return(ports.CompileResult{}, errors.New("compilation error"))
+
+	// Act
+	result, err := svc.Build(ctx, req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error.Error(), "LATEX_COMPILATION_FAILED")
+	mockTpl.AssertExpectations(t)
+	mockTex.AssertExpectations(t)
+}
+
+func TestDocumentService_Build_LaTeXCompilationFailsWithDiagnostics(t *testing.T) {
+	// Arrange
+	mockTpl := new(MockTemplateProcessor)
+	mockTex := new(MockLaTeXCompiler)
+
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		LaTeXCompiler:     mockTex,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	ctx := context.Background()
+	req := BuildRequest{
+		TemplatePath: "template.tex",
+		Engine:       "pdflatex",
+		OutputPath:   "output.pdf",
+	}
+
+	diagnostics := []ports.Diagnostic{
+		{Kind: ports.DiagnosticError, Severity: ports.SeverityError, File: "output.tex", Line: 5, Message: "Undefined control sequence."},
+		{Kind: ports.DiagnosticUndefinedReference, Severity: ports.SeverityWarning, File: "output.tex", Line: 9, Message: "Reference `fig' on page 1 undefined on input line 9."},
+	}
+
+	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("\\documentclass{article}...", nil)
+	mockTex.On("Compile", ctx, "\\documentclass{article}...", compileOptionsFor("pdflatex", "output.pdf")).
+		Return(ports.CompileResult{Diagnostics: diagnostics}, &ports.CompilationError{Diagnostics: diagnostics, Err: errors.New("compilation error")})
 
 	// Act
 	result, err := svc.Build(ctx, req)
@@ -166,7 +328,8 @@ func TestDocumentService_Build_LaTeXCompilationFails(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.False(t, result.Success)
-	assert.Contains(t, result.Error.Error(), "LaTeX compilation failed")
+	assert.Equal(t, diagnostics, result.Diagnostics)
+	assert.Contains(t, result.Error.Error(), "Blame: output.tex:5: Undefined control sequence.")
 	mockTpl.AssertExpectations(t)
 	mockTex.AssertExpectations(t)
 }
@@ -183,6 +346,8 @@ func TestDocumentService_Build_WithConversion(t *testing.T) {
 		LaTeXCompiler:     mockTex,
 		Converter:         mockConv,
 		Cleaner:           mockClean,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
 	}
 
 	ctx := context.Background()
@@ -202,7 +367,7 @@ func TestDocumentService_Build_WithConversion(t *testing.T) {
 	}
 
 	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("\\documentclass{article}...", nil)
-	mockTex.On("Compile", ctx, "\\documentclass{article}...", "pdflatex", "output.pdf").Return("output.pdf", nil)
+	mockTex.On("Compile", ctx, "\\documentclass{article}...", compileOptionsFor("pdflatex", "output.pdf")).Return(ports.CompileResult{PDFPath: "output.pdf"}, nil)
 	mockConv.On("ConvertToImages", ctx, "output.pdf", []string{"png", "jpg"}).Return([]string{"output.png", "output.jpg"}, nil)
 
 	// Act
@@ -230,6 +395,8 @@ func TestDocumentService_Build_WithClean(t *testing.T) {
 		LaTeXCompiler:     mockTex,
 		Converter:         mockConv,
 		Cleaner:           mockClean,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
 	}
 
 	ctx := context.Background()
@@ -245,7 +412,7 @@ func TestDocumentService_Build_WithClean(t *testing.T) {
 	}
 
 	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("\\documentclass{article}...", nil)
-	mockTex.On("Compile", ctx, "\\documentclass{article}...", "pdflatex", "output.pdf").Return("output.pdf", nil)
+	mockTex.On("Compile", ctx, "\\documentclass{article}...", compileOptionsFor("pdflatex", "output.pdf")).Return(ports.CompileResult{PDFPath: "output.pdf"}, nil)
 	mockClean.On("Clean", ctx, "output.pdf").Return(nil)
 
 	// Act
@@ -303,3 +470,343 @@ func TestDocumentService_CleanDocument(t *testing.T) {
 	assert.NoError(t, err)
 	mockClean.AssertExpectations(t)
 }
+
+func TestDocumentService_Build_InWorkspace(t *testing.T) {
+	root := t.TempDir()
+	templateDir := t.TempDir()
+	outputPath := filepath.Join(t.TempDir(), "invoice.pdf")
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "logo.png"), []byte("png"), 0644))
+
+	workspaces, err := infraadapters.NewOSWorkspaceManager(root, ports.NewRetentionPolicy("delete", 0, 0))
+	require.NoError(t, err)
+
+	mockTpl := new(MockTemplateProcessor)
+	mockTex := new(MockLaTeXCompiler)
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		LaTeXCompiler:     mockTex,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		FileSystem:        infraadapters.NewOSFileSystem(),
+		Workspaces:        workspaces,
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	ctx := context.Background()
+	templatePath := filepath.Join(templateDir, "invoice.tex")
+	mockTpl.On("Process", ctx, templatePath, mock.Anything).Return("\\documentclass{article}", nil)
+
+	var compiledIn string
+	call := mockTex.On("Compile", ctx, "\\documentclass{article}", mock.Anything).Once()
+	call.Run(func(args mock.Arguments) {
+		opts := args.Get(2).(ports.CompileOptions)
+		compiledIn = opts.WorkingDir
+		// Engine output stays inside the workspace, next to the staged assets
+		assert.Equal(t, filepath.Join(opts.WorkingDir, "invoice.pdf"), opts.OutputPath)
+		assert.FileExists(t, filepath.Join(opts.WorkingDir, "logo.png"))
+		assert.Equal(t, []string{templateDir}, opts.SearchDirs, "the engine still finds inputs the stager missed")
+		require.NoError(t, os.WriteFile(opts.OutputPath, []byte("%PDF-1.5"), 0644))
+		call.ReturnArguments = mock.Arguments{ports.CompileResult{PDFPath: opts.OutputPath, Passes: 1}, nil}
+	})
+
+	result, err := svc.Build(ctx, BuildRequest{
+		TemplatePath: templatePath,
+		Engine:       "pdflatex",
+		OutputPath:   outputPath,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, outputPath, result.PDFPath)
+	assert.Equal(t, compiledIn, result.Workspace)
+	assert.Equal(t, root, filepath.Dir(result.Workspace))
+	assert.FileExists(t, outputPath)
+	assert.NoDirExists(t, result.Workspace, "delete policy removes the workspace")
+}
+
+func TestDocumentService_Build_ParentReferencesCompileInTemplateDir(t *testing.T) {
+	root := t.TempDir()
+	parent := t.TempDir()
+	templateDir := filepath.Join(parent, "invoices")
+	outputPath := filepath.Join(t.TempDir(), "invoice.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Join(parent, "shared"), 0755))
+	require.NoError(t, os.MkdirAll(templateDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(parent, "shared", "logo.png"), []byte("png"), 0644))
+
+	workspaces, err := infraadapters.NewOSWorkspaceManager(root, ports.NewRetentionPolicy("delete", 0, 0))
+	require.NoError(t, err)
+	stager, err := assets.NewAssetStagerAdapter(infraadapters.NewOSFileSystem(), ports.EnginePDFLaTeX, "", nil)
+	require.NoError(t, err)
+
+	mockTpl := new(MockTemplateProcessor)
+	mockTex := new(MockLaTeXCompiler)
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		LaTeXCompiler:     mockTex,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		FileSystem:        infraadapters.NewOSFileSystem(),
+		Workspaces:        workspaces,
+		AssetStager:       stager,
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	ctx := context.Background()
+	templatePath := filepath.Join(templateDir, "invoice.tex")
+	content := "\\includegraphics{../shared/logo.png}"
+	mockTpl.On("Process", ctx, templatePath, mock.Anything).Return(content, nil)
+
+	call := mockTex.On("Compile", ctx, content, mock.Anything).Once()
+	call.Run(func(args mock.Arguments) {
+		opts := args.Get(2).(ports.CompileOptions)
+		// ../shared resolves from the template directory only
+		assert.Equal(t, templateDir, opts.WorkingDir)
+		assert.Empty(t, opts.SearchDirs)
+		assert.Nil(t, opts.Inputs, "files outside the template directory are not keyed, so the cache is bypassed")
+		require.NoError(t, os.WriteFile(opts.OutputPath, []byte("%PDF-1.5"), 0644))
+		call.ReturnArguments = mock.Arguments{ports.CompileResult{PDFPath: opts.OutputPath, Passes: 1}, nil}
+	})
+
+	result, err := svc.Build(ctx, BuildRequest{
+		TemplatePath: templatePath,
+		Engine:       "pdflatex",
+		OutputPath:   outputPath,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Workspace)
+	assert.FileExists(t, outputPath)
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the unused workspace is released")
+}
+
+func TestDocumentService_Build_ReportsCacheStatus(t *testing.T) {
+	mockTpl := new(MockTemplateProcessor)
+	mockTex := new(MockLaTeXCompiler)
+
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		LaTeXCompiler:     mockTex,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	ctx := context.Background()
+	req := BuildRequest{
+		TemplatePath: "template.tex",
+		Engine:       "pdflatex",
+		OutputPath:   "output.pdf",
+		NoCache:      true,
+	}
+
+	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("\\documentclass{article}", nil)
+	mockTex.On("Compile", ctx, "\\documentclass{article}", mock.MatchedBy(func(opts ports.CompileOptions) bool {
+		return opts.NoCache
+	})).Return(ports.CompileResult{PDFPath: "output.pdf", Cache: ports.CacheBypassed, CacheKey: "abc"}, nil)
+
+	result, err := svc.Build(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, ports.CacheBypassed, result.Cache)
+	assert.Equal(t, "abc", result.CacheKey)
+	mockTex.AssertExpectations(t)
+}
+
+func TestDocumentService_Build_RejectsUnknownEngine(t *testing.T) {
+	mockTpl := new(MockTemplateProcessor)
+	mockTex := new(MockLaTeXCompiler)
+
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		LaTeXCompiler:     mockTex,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	result, err := svc.Build(context.Background(), BuildRequest{
+		TemplatePath: "template.tex",
+		Engine:       "context",
+		OutputPath:   "output.pdf",
+	})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ports.ErrUnsupportedEngine)
+	assert.Error(t, result.Error)
+	mockTpl.AssertNotCalled(t, "Process", mock.Anything, mock.Anything, mock.Anything)
+	mockTex.AssertNotCalled(t, "Compile", mock.Anything, mock.Anything, mock.Anything)
+
+	// Configs written for the retired latex engine are told what to use instead
+	_, err = svc.Build(context.Background(), BuildRequest{
+		TemplatePath: "template.tex",
+		Engine:       "latex",
+		OutputPath:   "output.pdf",
+	})
+	assert.ErrorIs(t, err, ports.ErrUnsupportedEngine)
+	assert.ErrorContains(t, err, "set engine to pdflatex")
+}
+
+func TestDocumentService_Build_PassesTypedTemplateData(t *testing.T) {
+	mockTpl := new(MockTemplateProcessor)
+	mockTex := new(MockLaTeXCompiler)
+
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		LaTeXCompiler:     mockTex,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	variables := config.NewVariables()
+	variables.Set("enabled", &config.BoolVariable{Value: false})
+	variables.Set("pages", &config.NumberVariable{Value: 3})
+	items := config.NewSliceVariable()
+	items.Values = append(items.Values, &config.StringVariable{Value: "first"})
+	variables.Set("items", items)
+
+	ctx := context.Background()
+	expected := ports.TemplateData{
+		"enabled": false,
+		"pages":   3,
+		"items":   []interface{}{"first"},
+	}
+	mockTpl.On("Process", ctx, "template.tex", expected).Return("\\documentclass{article}", nil)
+	mockTex.On("Compile", ctx, "\\documentclass{article}", mock.Anything).Return(ports.CompileResult{PDFPath: "output.pdf"}, nil)
+
+	_, err := svc.Build(ctx, BuildRequest{
+		TemplatePath: "template.tex",
+		Variables:    variables,
+		Engine:       "pdflatex",
+		OutputPath:   "output.pdf",
+	})
+
+	require.NoError(t, err)
+	mockTpl.AssertExpectations(t)
+}
+
+type MockOutputPathRenderer struct {
+	mock.Mock
+}
+
+func (m *MockOutputPathRenderer) RenderOutputPath(ctx context.Context, pattern string, data ports.TemplateData) (string, error) {
+	args := m.Called(ctx, pattern, data)
+	return args.String(0), args.Error(1)
+}
+
+func TestDocumentService_Build_RendersOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "out", "{{.client}}", "invoice.pdf")
+	rendered := filepath.Join(dir, "out", "acme", "invoice.pdf")
+	suffixed := filepath.Join(dir, "out", "acme", "invoice-2.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(rendered), 0755))
+	for _, existing := range []string{rendered, filepath.Join(dir, "out", "acme", "invoice-1.pdf")} {
+		require.NoError(t, os.WriteFile(existing, []byte("%PDF-1.5"), 0644))
+	}
+
+	mockTpl := new(MockTemplateProcessor)
+	mockTex := new(MockLaTeXCompiler)
+	mockPaths := new(MockOutputPathRenderer)
+	svc := DocumentService{
+		TemplateProcessor: mockTpl,
+		LaTeXCompiler:     mockTex,
+		PathOps:           infraadapters.NewOSPathOperations(),
+		FileSystem:        infraadapters.NewOSFileSystem(),
+		OutputPaths:       mockPaths,
+		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+	}
+
+	ctx := context.Background()
+	variables := config.NewVariables()
+	variables.SetString("client", "acme")
+	mockPaths.On("RenderOutputPath", ctx, pattern, ports.TemplateData{"client": "acme"}).Return(rendered, nil)
+	mockTpl.On("Process", ctx, "template.tex", mock.Anything).Return("\\documentclass{article}", nil)
+	mockTex.On("Compile", ctx, "\\documentclass{article}", mock.MatchedBy(func(opts ports.CompileOptions) bool {
+		return opts.OutputPath == suffixed && opts.JobName == "invoice-2"
+	})).Return(ports.CompileResult{PDFPath: suffixed}, nil)
+
+	result, err := svc.Build(ctx, BuildRequest{
+		TemplatePath: "template.tex",
+		Variables:    variables,
+		Engine:       "pdflatex",
+		OutputPath:   pattern,
+		OnCollision:  ports.OutputCollisionSuffix,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, suffixed, result.PDFPath)
+	mockTex.AssertExpectations(t)
+}
+
+func TestDocumentService_Build_OutputCollisionPolicies(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "invoice.pdf")
+	require.NoError(t, os.WriteFile(existing, []byte("%PDF-1.5"), 0644))
+
+	newService := func() (DocumentService, *MockTemplateProcessor) {
+		mockTpl := new(MockTemplateProcessor)
+		return DocumentService{
+			TemplateProcessor: mockTpl,
+			LaTeXCompiler:     new(MockLaTeXCompiler),
+			PathOps:           infraadapters.NewOSPathOperations(),
+			FileSystem:        infraadapters.NewOSFileSystem(),
+			ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
+		}, mockTpl
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		svc, mockTpl := newService()
+		result, err := svc.Build(context.Background(), BuildRequest{
+			TemplatePath: "template.tex",
+			Engine:       "pdflatex",
+			OutputPath:   existing,
+			OnCollision:  ports.OutputCollisionFail,
+		})
+
+		assert.ErrorIs(t, err, ports.ErrOutputExists)
+		var domainErr *apperrors.DomainError
+		require.True(t, errors.As(result.Error, &domainErr))
+		assert.Equal(t, "OUTPUT_EXISTS", domainErr.Code)
+		mockTpl.AssertNotCalled(t, "Process", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("suffix reserves each path", func(t *testing.T) {
+		svc, _ := newService()
+		req := BuildRequest{OutputPath: filepath.Join(dir, "report.pdf"), OnCollision: ports.OutputCollisionSuffix}
+
+		first, reserved, err := svc.resolveOutputPath(context.Background(), req, nil)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		second, _, err := svc.resolveOutputPath(context.Background(), req, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(dir, "report.pdf"), first)
+		assert.Equal(t, filepath.Join(dir, "report-1.pdf"), second)
+		assert.FileExists(t, second)
+	})
+
+	t.Run("failed build releases the reserved path", func(t *testing.T) {
+		svc, mockTpl := newService()
+		output := filepath.Join(dir, "draft.pdf")
+		mockTpl.On("Process", mock.Anything, "template.tex", mock.Anything).Return("", errors.New("template error"))
+
+		_, err := svc.Build(context.Background(), BuildRequest{
+			TemplatePath: "template.tex",
+			Engine:       "pdflatex",
+			OutputPath:   output,
+			OnCollision:  ports.OutputCollisionFail,
+		})
+
+		assert.Error(t, err)
+		assert.NoFileExists(t, output)
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		svc, mockTpl := newService()
+		_, err := svc.Build(context.Background(), BuildRequest{
+			TemplatePath: "template.tex",
+			Engine:       "pdflatex",
+			OutputPath:   existing,
+			OnCollision:  "rename",
+		})
+
+		assert.ErrorContains(t, err, "unsupported output collision policy")
+		mockTpl.AssertNotCalled(t, "Process", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
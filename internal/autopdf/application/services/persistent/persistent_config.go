@@ -26,6 +26,9 @@ type PersistentConfig struct {
 	// Force settings
 	ForceEnabled bool `yaml:"force_enabled" json:"force_enabled"`
 
+	// Profile settings
+	DefaultProfile string `yaml:"default_profile" json:"default_profile"` // Applied to configs defining it
+
 	// Metadata
 	LastUpdated time.Time `yaml:"last_updated" json:"last_updated"`
 	Version     string    `yaml:"version" json:"version"`
@@ -73,6 +76,12 @@ func (pc *PersistentConfig) SetForceEnabled(enabled bool) {
 	pc.LastUpdated = time.Now()
 }
 
+// SetDefaultProfile sets the profile applied to configs that define it, empty for none
+func (pc *PersistentConfig) SetDefaultProfile(name string) {
+	pc.DefaultProfile = name
+	pc.LastUpdated = time.Now()
+}
+
 // ToggleClean toggles the clean setting
 func (pc *PersistentConfig) ToggleClean() bool {
 	pc.CleanEnabled = !pc.CleanEnabled
@@ -128,6 +137,9 @@ func (pc *PersistentConfig) GetStatus() map[string]interface{} {
 		"force": map[string]interface{}{
 			"enabled": pc.ForceEnabled,
 		},
+		"profile": map[string]interface{}{
+			"default": pc.DefaultProfile,
+		},
 		"metadata": map[string]interface{}{
 			"last_updated": pc.LastUpdated.Format(time.RFC3339),
 			"version":      pc.Version,
@@ -90,6 +90,8 @@ func NewPersistentService() *PersistentService {
 		}
 	}
 
+	config.DefaultProfile = persister.Get("default_profile")
+
 	return &PersistentService{
 		config:    config,
 		persister: persister,
@@ -113,6 +115,7 @@ func (ps *PersistentService) SaveConfig() error {
 	ps.persister.Set("debug_enabled", strconv.FormatBool(ps.config.DebugEnabled))
 	ps.persister.Set("debug_output", ps.config.DebugOutput)
 	ps.persister.Set("force_enabled", strconv.FormatBool(ps.config.ForceEnabled))
+	ps.persister.Set("default_profile", ps.config.DefaultProfile)
 	ps.persister.Set("last_updated", ps.config.LastUpdated.Format(time.RFC3339))
 	ps.persister.Set("version", ps.config.Version)
 
@@ -198,6 +201,19 @@ func (ps *PersistentService) ToggleForce() (bool, error) {
 	return enabled, err
 }
 
+// SetDefaultProfile sets the profile applied to configs that define it and persists it
+func (ps *PersistentService) SetDefaultProfile(name string) error {
+	ps.config.SetDefaultProfile(name)
+
+	// Persist to file
+	return ps.SaveConfig()
+}
+
+// GetDefaultProfile returns the profile applied to configs that define it, empty for none
+func (ps *PersistentService) GetDefaultProfile() string {
+	return ps.config.DefaultProfile
+}
+
 // GetStatus returns the current status of all settings
 func (ps *PersistentService) GetStatus() map[string]interface{} {
 	return ps.config.GetStatus()
@@ -255,6 +271,8 @@ func (ps *PersistentService) LoadFromFile() error {
 		}
 	}
 
+	config.DefaultProfile = ps.persister.Get("default_profile")
+
 	ps.config = config
 	return nil
 }
@@ -273,6 +291,7 @@ func (ps *PersistentService) ExportConfig(path string) error {
 	exportPersister.Set("debug_enabled", strconv.FormatBool(ps.config.DebugEnabled))
 	exportPersister.Set("debug_output", ps.config.DebugOutput)
 	exportPersister.Set("force_enabled", strconv.FormatBool(ps.config.ForceEnabled))
+	exportPersister.Set("default_profile", ps.config.DefaultProfile)
 	exportPersister.Set("last_updated", ps.config.LastUpdated.Format(time.RFC3339))
 	exportPersister.Set("version", ps.config.Version)
 
@@ -323,6 +342,8 @@ func (ps *PersistentService) ImportConfig(path string) error {
 		}
 	}
 
+	config.DefaultProfile = importPersister.Get("default_profile")
+
 	ps.config = config
 
 	// Persist to main config file
@@ -5,6 +5,7 @@ package watch
 
 import (
 	"fmt"
+	"io/fs"
 	"path/filepath"
 	"strings"
 	"time"
@@ -120,6 +121,20 @@ func (w *WatchApplicationService) setupWatcher() error {
 		w.logger.InfoWithFields("Watching config directory", "directory", configDir)
 	}
 
+	// fsnotify does not watch subdirectories, so every directory of a partials tree is added
+	for _, partialDir := range w.config.PartialDirs {
+		err := filepath.WalkDir(partialDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			return w.watcher.Add(path)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch partials directory: %w", err)
+		}
+		w.logger.InfoWithFields("Watching partials directory", "directory", partialDir)
+	}
+
 	return nil
 }
 
@@ -7,9 +7,11 @@ package autopdf
 import (
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/build"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/convert"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/lint"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/options/clean"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/options/debug"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/options/force"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/options/profile"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/options/verbose"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/options/watch"
 	"github.com/rwxrob/bonzai"
@@ -36,10 +38,12 @@ operations and workflow management.
 # Commands:
 - build:    Process template and compile to PDF
 - convert:  Convert PDF to images
+- lint:     Check a template for mistakes without compiling it
 - clean:    Remove LaTeX auxiliary files
 - verbose:  Set verbose logging level
 - debug:    Enable debug information output
 - force:    Enable force operations
+- profile:  Show or set the default config profile
 - vars:     View and set configuration variables
 
 Use 'autopdf help <command> <subcommand>...' for detailed information
@@ -51,10 +55,12 @@ about each command.
 		vars.Cmd,
 		build.BuildServiceCmd,     // Use new service-based build command
 		convert.ConvertServiceCmd, // Use new service-based convert command
+		lint.LintServiceCmd,       // Check templates without compiling
 		clean.CleanServiceCmd,     // Use new service-based clean command
 		verbose.VerboseServiceCmd, // Use new service-based verbose command
 		debug.DebugServiceCmd,     // Use new service-based debug command
 		force.ForceServiceCmd,     // Use new service-based force command
+		profile.ProfileServiceCmd, // Persistent default config profile
 		watch.WatchServiceCmd,     // Use new service-based watch command
 	},
 	Def: help.Cmd,
@@ -9,6 +9,7 @@ import (
 
 	"github.com/BuddhiLW/AutoPDF/configs"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/document"
+	persistentService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/persistent"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common"
 	argsPkg "github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common/args"
 	configPkg "github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common/config"
@@ -30,7 +31,7 @@ var BuildServiceCmd = &bonzai.Cmd{
 	Short:   `process template and compile to PDF`,
 	Usage:   `TEMPLATE [CONFIG] [OPTIONS...]`,
 	MinArgs: 1,
-	MaxArgs: 10, // Allow up to 10 arguments for multiple options
+	MaxArgs: 0, // Options and repeated --set flags are not limited
 	Long: `
 The build command processes a template file using variables from a configuration,
 compiles the processed template to LaTeX, and produces a PDF output.
@@ -44,12 +45,43 @@ Available options:
 - verbose: Enable verbose logging
 - debug: Enable debug information output
 - force: Force operations (overwrite existing files)
+- no-cache (or --no-cache): Compile even when the compile cache holds the PDF
+- profile=NAME: Apply the named profile of the config, such as draft or final,
+  instead of the default set with 'autopdf profile'
+
+Variables can be read from more sources, each overriding the ones before it:
+- the config file
+- --vars FILE: a YAML, JSON or TOML file of variables, repeatable
+- the .env file next to the config file, or --env-file FILE
+- environment variables named AUTOPDF_VAR_ and the variable's path, with __
+  between its parts: AUTOPDF_VAR_CLIENT__NAME sets client.name; each part
+  matches an existing key regardless of case, so AUTOPDF_VAR_CLIENT__VATID
+  sets client.vatId, and new keys are lower case
+- --set-file PATH=FILE: set a variable to the content of a file, repeatable
+- --set PATH=VALUE: set a variable, such as items[0].qty=2, repeatable
+
+Environment and --set values are read as YAML scalars: 2 is a number, true a
+boolean and '"2"' a string. --set-file always sets a string.
+
+Once all are applied, ${...} in variables and output is replaced by another
+variable, such as ${client.name}, or by ${now:2006-01-02}, ${env:NAME}, ${uuid}
+or ${git:describe}; $${ is a literal ${. --set-file contents and front matter
+defaults are taken as written.
+
+The output can be a template printing variables, as templates do with the
+Typst delimiters, to write one file per client or invoice; printed values
+become safe file names, an empty one being an error, and missing directories
+are created. output_collision
+in the config sets what happens when the file exists: overwrite (default),
+fail or suffix (name-1.pdf, name-2.pdf, ...).
 
 Examples:
   autopdf build template.tex
   autopdf build template.tex config.yaml
   autopdf build template.tex config.yaml clean
   autopdf build template.tex clean verbose debug
+  autopdf build template.tex config.yaml profile=final
+  autopdf build template.tex config.yaml --set version=1.4.2 --set-file notes=CHANGES.md
 `,
 	Comp: comp.Cmds,
 	Cmds: []*bonzai.Cmd{
@@ -88,7 +120,9 @@ func executeBuildProcess(ctx context.Context, args []string) error {
 // executeSingleBuild performs a single build operation
 func executeSingleBuild(ctx context.Context, buildArgs *argsPkg.BuildArgs) error {
 	// Resolve and load configuration with logging
-	configResolver := configPkg.NewConfigResolver()
+	configResolver := configPkg.NewConfigResolver().
+		WithVariableSources(buildArgs.Sources).
+		WithProfile(buildArgs.Options.Profile.Name, persistentService.NewPersistentService().GetDefaultProfile())
 	cfg, err := configResolver.LoadConfigWithLogging(ctx, buildArgs.TemplateFile, buildArgs.ConfigFile)
 	if err != nil {
 		return err
@@ -102,12 +136,13 @@ func executeSingleBuild(ctx context.Context, buildArgs *argsPkg.BuildArgs) error
 	req := serviceBuilder.BuildRequest(buildArgs, cfg)
 
 	result, err := svc.Build(ctx, req)
+	resultHandler := resultPkg.NewResultHandler().WithDebug(buildArgs.Options.Debug.Enabled)
 	if err != nil {
+		resultHandler.HandleBuildFailure(result)
 		return configs.BuildError
 	}
 
 	// Handle result and delegation
-	resultHandler := resultPkg.NewResultHandler()
 	if err := resultHandler.HandleBuildResult(result); err != nil {
 		return err
 	}
@@ -135,7 +170,7 @@ func executeWatchedBuild(ctx context.Context, buildArgs *argsPkg.BuildArgs) erro
 	// Pass BuildOptions directly to watch service
 	// This preserves verbose level, debug settings, and other options
 	// Following CLARITY: explicit option passing instead of string-based args
-	return watch.ExecuteWatchProcessWithOptions(ctx, watchArgs, buildArgs.Options)
+	return watch.ExecuteWatchProcessWithOptions(ctx, watchArgs, buildArgs.Options, buildArgs.Sources)
 }
 
 // handleDelegation manages subcommand delegation using the new flexible approach
@@ -164,6 +164,9 @@ func TestArgsParser_IsOption(t *testing.T) {
 		{"verbose", true},
 		{"debug", true},
 		{"force", true},
+		{"no-cache", true},
+		{"--no-cache", true},
+		{"--profile=draft", true},
 		{"config.yaml", false},
 		{"template.tex", false},
 		{"invalid", false},
@@ -7,7 +7,9 @@ import (
 	"testing"
 
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/domain/options"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestArgsParser_ParseBuildArgs(t *testing.T) {
@@ -89,3 +91,47 @@ func TestArgsParser_ParseBuildArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestArgsParser_ParseVariableSources(t *testing.T) {
+	parser := NewArgsParser()
+
+	result, err := parser.ParseBuildArgs([]string{
+		"template.tex", "config.yaml",
+		"--set", "version=1.4.2", "--set=items[0].qty=3",
+		"--vars", "base.yaml", "--vars=release.toml",
+		"--set-file", "notes=CHANGES.md", "--env-file", "ci.env", "clean",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "config.yaml", result.ConfigFile)
+	assert.True(t, result.Options.Clean.Enabled)
+	assert.Equal(t, config.VariableSources{
+		Files:    []string{"base.yaml", "release.toml"},
+		EnvFile:  "ci.env",
+		SetFiles: []config.Override{{Path: "notes", Value: "CHANGES.md"}},
+		Sets:     []config.Override{{Path: "version", Value: "1.4.2"}, {Path: "items[0].qty", Value: "3"}},
+	}, result.Sources)
+
+	_, err = parser.ParseBuildArgs([]string{"template.tex", "--set"})
+	assert.Error(t, err)
+	_, err = parser.ParseBuildArgs([]string{"template.tex", "--set", "version"})
+	assert.Error(t, err)
+}
+
+func TestArgsParser_ParseProfile(t *testing.T) {
+	parser := NewArgsParser()
+
+	result, err := parser.ParseBuildArgs([]string{"template.tex", "config.yaml", "profile=final", "clean"})
+	require.NoError(t, err)
+	assert.Equal(t, "final", result.Options.Profile.Name)
+	assert.True(t, result.Options.Clean.Enabled)
+
+	cleanArgs, buildOptions, err := parser.ParseArgsWithOptions([]string{"template.tex", "profile=draft"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"template.tex"}, cleanArgs)
+	assert.Equal(t, "draft", buildOptions.Profile.Name)
+
+	_, err = parser.ParseBuildArgs([]string{"template.tex", "profile="})
+	assert.Error(t, err)
+	_, err = parser.ParseBuildArgs([]string{"template.tex", "engine=xelatex"})
+	assert.Error(t, err)
+}
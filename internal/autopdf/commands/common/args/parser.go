@@ -6,10 +6,12 @@ package args
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/domain/options"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // BuildArgs represents the parsed arguments for the build command
@@ -17,6 +19,7 @@ type BuildArgs struct {
 	TemplateFile  string
 	ConfigFile    string
 	Options       options.BuildOptions
+	Sources       config.VariableSources // --vars, --env-file, --set-file and --set
 	RemainingArgs []string
 }
 
@@ -38,9 +41,9 @@ func (ap *ArgsParser) ParseArgsWithOptions(args []string) (cleanArgs []string, b
 	cleanArgs = make([]string, 0, len(args))
 
 	for _, arg := range args {
-		if ap.registry.IsOption(arg) {
+		if ap.isOption(arg) {
 			// Parse and set the option
-			ap.setOption(&buildOptions, arg)
+			ap.setOption(&buildOptions, optionName(arg))
 		} else {
 			// Keep non-option arguments
 			cleanArgs = append(cleanArgs, arg)
@@ -52,6 +55,10 @@ func (ap *ArgsParser) ParseArgsWithOptions(args []string) (cleanArgs []string, b
 
 // ParseBuildArgs parses the build command arguments
 func (ap *ArgsParser) ParseBuildArgs(args []string) (*BuildArgs, error) {
+	args, sources, err := ap.ParseVariableSources(args)
+	if err != nil {
+		return nil, err
+	}
 	if len(args) < 1 {
 		return nil, fmt.Errorf("template file is required")
 	}
@@ -60,6 +67,7 @@ func (ap *ArgsParser) ParseBuildArgs(args []string) (*BuildArgs, error) {
 		TemplateFile:  args[0],
 		ConfigFile:    "",                        // Will be resolved by ConfigResolver
 		Options:       options.NewBuildOptions(), // Initialize with default values
+		Sources:       sources,
 		RemainingArgs: []string{},
 	}
 
@@ -94,6 +102,10 @@ func (ap *ArgsParser) ParseBuildArgs(args []string) (*BuildArgs, error) {
 
 // ParseBuildArgsWithDelegation parses build arguments and handles delegation to other commands
 func (ap *ArgsParser) ParseBuildArgsWithDelegation(args []string) (*BuildArgs, error) {
+	args, sources, err := ap.ParseVariableSources(args)
+	if err != nil {
+		return nil, err
+	}
 	if len(args) < 1 {
 		return nil, fmt.Errorf("template file is required")
 	}
@@ -102,6 +114,7 @@ func (ap *ArgsParser) ParseBuildArgsWithDelegation(args []string) (*BuildArgs, e
 		TemplateFile:  args[0],
 		ConfigFile:    "",                        // Will be resolved by ConfigResolver
 		Options:       options.NewBuildOptions(), // Initialize with default values
+		Sources:       sources,
 		RemainingArgs: []string{},
 	}
 
@@ -141,6 +154,56 @@ func (ap *ArgsParser) ParseBuildArgsWithDelegation(args []string) (*BuildArgs, e
 	return buildArgs, nil
 }
 
+// ParseVariableSources extracts the variable source flags from args and returns the other
+// arguments. Each flag takes its value as the next argument or after =:
+//
+//	--vars FILE          a YAML, JSON or TOML file of variables, repeatable
+//	--env-file FILE      the .env file, instead of the one next to the config file
+//	--set-file KEY=FILE  set a variable to the content of a file, repeatable
+//	--set KEY=VALUE      set a variable, repeatable
+//
+// Keys are paths such as client.name or items[0].qty, see config.VariableSources
+func (ap *ArgsParser) ParseVariableSources(args []string) ([]string, config.VariableSources, error) {
+	var sources config.VariableSources
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		flag, value, inline := strings.Cut(args[i], "=")
+		switch flag {
+		case "--vars", "--env-file", "--set-file", "--set":
+		default:
+			rest = append(rest, args[i])
+			continue
+		}
+		if !inline {
+			if i+1 >= len(args) {
+				return nil, sources, fmt.Errorf("%s requires a value", flag)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch flag {
+		case "--vars":
+			sources.Files = append(sources.Files, value)
+		case "--env-file":
+			sources.EnvFile = value
+		case "--set-file", "--set":
+			override, err := config.ParseOverride(value)
+			if err != nil {
+				return nil, sources, fmt.Errorf("%s: %w", flag, err)
+			}
+			if flag == "--set" {
+				sources.Sets = append(sources.Sets, override)
+			} else {
+				sources.SetFiles = append(sources.SetFiles, override)
+			}
+		}
+	}
+
+	return rest, sources, nil
+}
+
 // GetRemainingArgs returns the remaining arguments for delegation
 func (ba *BuildArgs) GetRemainingArgs() []string {
 	return ba.RemainingArgs
@@ -166,6 +229,9 @@ func (ap *ArgsParser) ParseBuildArgsWithLogging(ctx context.Context, args []stri
 		"template_file", buildArgs.TemplateFile,
 		"config_file", buildArgs.ConfigFile,
 		"options", buildArgs.Options,
+		"profile", buildArgs.Options.Profile.Name,
+		"variable_files", buildArgs.Sources.Files,
+		"overrides", len(buildArgs.Sources.Sets)+len(buildArgs.Sources.SetFiles),
 	)
 
 	return buildArgs, nil
@@ -207,7 +273,7 @@ func getLoggerFromContext(ctx context.Context) *logger.LoggerAdapter {
 
 // isOption checks if an argument is an option (starts with known option names)
 func (ap *ArgsParser) isOption(arg string) bool {
-	return ap.registry.IsOption(arg)
+	return ap.registry.IsOption(optionName(arg))
 }
 
 // isValidConfigFile checks if an argument looks like a valid config file
@@ -232,11 +298,24 @@ func (ap *ArgsParser) isValidConfigFile(arg string) bool {
 func (ap *ArgsParser) parseOption(option string) (string, error) {
 	// For now, we only support simple boolean options
 	// Future: could support key=value options like "verbose=2"
-	return option, nil
+	return optionName(option), nil
+}
+
+// optionName strips the leading dashes of an option written as a flag, so --no-cache is no-cache
+func optionName(arg string) string {
+	return strings.TrimPrefix(arg, "--")
 }
 
 // setOption sets the appropriate option in BuildOptions
 func (ap *ArgsParser) setOption(buildOptions *options.BuildOptions, option string) {
+	if name, value, ok := strings.Cut(option, "="); ok {
+		switch name {
+		case "profile":
+			buildOptions.SelectProfile(value)
+		}
+		return
+	}
+
 	switch option {
 	case "clean":
 		buildOptions.EnableClean(".") // Default to current directory
@@ -248,5 +327,7 @@ func (ap *ArgsParser) setOption(buildOptions *options.BuildOptions, option strin
 		buildOptions.EnableForce(true) // Default to overwrite enabled
 	case "watch":
 		buildOptions.EnableWatch(500 * time.Millisecond) // Default to 500ms interval
+	case "no-cache":
+		buildOptions.EnableNoCache()
 	}
 }
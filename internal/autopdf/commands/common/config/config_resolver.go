@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/BuddhiLW/AutoPDF/configs"
@@ -19,13 +20,46 @@ import (
 )
 
 // ConfigResolver handles config file resolution and template path resolution
-type ConfigResolver struct{}
+type ConfigResolver struct {
+	sources        config.VariableSources
+	profile        string // Selected with profile=NAME
+	defaultProfile string // Persistent default, applied when the config defines it
+}
 
 // NewConfigResolver creates a new config resolver
 func NewConfigResolver() *ConfigResolver {
 	return &ConfigResolver{}
 }
 
+// WithVariableSources sets the sources whose variables override the config file's
+func (cr *ConfigResolver) WithVariableSources(sources config.VariableSources) *ConfigResolver {
+	cr.sources = sources
+	return cr
+}
+
+// WithProfile sets the profile to apply to the config: the selected one, which the config must
+// define, or else the persistent default, which is skipped by configs that do not define it
+func (cr *ConfigResolver) WithProfile(selected, defaultProfile string) *ConfigResolver {
+	cr.profile = selected
+	cr.defaultProfile = defaultProfile
+	return cr
+}
+
+// ResolveProfile applies the resolver's profile to the config, see config.Config.ApplyProfile
+func (cr *ConfigResolver) ResolveProfile(cfg *config.Config) error {
+	name := cr.profile
+	if name == "" {
+		if _, ok := cfg.Profiles[cr.defaultProfile]; !ok {
+			return nil
+		}
+		name = cr.defaultProfile
+	}
+	if err := cfg.ApplyProfile(name); err != nil {
+		return fmt.Errorf("%w: %v", configs.ProfileError, err)
+	}
+	return nil
+}
+
 // ResolveConfigFile determines the config file to use and creates default if needed
 func (cr *ConfigResolver) ResolveConfigFile(templateFile string, providedConfigFile string) (string, error) {
 	if providedConfigFile != "" {
@@ -70,6 +104,82 @@ func (cr *ConfigResolver) ResolveTemplatePath(cfg *config.Config, templateFile,
 	return nil
 }
 
+// ResolvePartialDirs makes the configured partial directories relative to the config file's directory
+func (cr *ConfigResolver) ResolvePartialDirs(cfg *config.Config, configFile string) error {
+	configDir := filepath.Dir(configFile)
+	for i, dir := range cfg.Partials {
+		if filepath.IsAbs(dir) {
+			continue
+		}
+		absDir, err := filepath.Abs(filepath.Join(configDir, dir))
+		if err != nil {
+			return fmt.Errorf("failed to resolve partials directory: %w", err)
+		}
+		cfg.Partials[i] = absDir
+	}
+	return nil
+}
+
+// ApplyVariableSources sets the variables of the resolver's sources, the environment and
+// the .env file next to the config file over the config's variables, see config.VariableSources
+func (cr *ConfigResolver) ApplyVariableSources(cfg *config.Config, configFile string) error {
+	sources := cr.sources
+	if sources.EnvFile == "" {
+		dotEnv := filepath.Join(filepath.Dir(configFile), config.DotEnvFile)
+		if _, err := os.Stat(dotEnv); err == nil {
+			sources.EnvFile = dotEnv
+		}
+	}
+	if sources.Environ == nil {
+		sources.Environ = os.Environ()
+	}
+	if err := sources.Apply(&cfg.Variables); err != nil {
+		return fmt.Errorf("%w: %v", configs.VariableSourceError, err)
+	}
+	return nil
+}
+
+// ApplyFrontMatter merges the front matter of the config's template into the config,
+// see config.FrontMatter, and checks the config defines the variables it requires
+func (cr *ConfigResolver) ApplyFrontMatter(cfg *config.Config) error {
+	frontMatter, err := config.ReadFrontMatter(cfg.Template.String())
+	if err != nil {
+		return fmt.Errorf("%w: %v", configs.FrontMatterError, err)
+	}
+	frontMatter.Apply(cfg)
+	if missing := frontMatter.MissingVariables(&cfg.Variables); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", configs.RequiredVariableError, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ResolveSchema reads the schema file of the config's template, unless the config embeds a
+// schema, and defines the variables with a default the config does not
+// The variables are validated against it when the document is built
+func (cr *ConfigResolver) ResolveSchema(cfg *config.Config) error {
+	if cfg.Schema == nil {
+		schema, err := config.ReadSchema(cfg.Template.String())
+		if err != nil {
+			return fmt.Errorf("%w: %v", configs.SchemaError, err)
+		}
+		cfg.Schema = schema
+	} else if err := cfg.Schema.Check(); err != nil {
+		return fmt.Errorf("%w: %v", configs.SchemaError, err)
+	}
+	cfg.Schema.ApplyDefaults(&cfg.Variables)
+	return nil
+}
+
+// ResolveInterpolation resolves the ${...} expressions of the config's variables and output once
+// every source of variables is applied, running git describe in the config file's directory;
+// see config.Config.Interpolate
+func (cr *ConfigResolver) ResolveInterpolation(cfg *config.Config, configFile string) error {
+	if err := cfg.Interpolate(config.Interpolation{Dir: filepath.Dir(configFile)}); err != nil {
+		return fmt.Errorf("%w: %v", configs.InterpolationError, err)
+	}
+	return nil
+}
+
 // createDefaultConfig creates a default configuration file
 func (cr *ConfigResolver) createDefaultConfig(templateFile string) error {
 	// Create a basic default config
@@ -89,16 +199,15 @@ conversion:
 	return os.WriteFile(configs.DefaultConfigName, []byte(defaultConfig), 0644)
 }
 
-// LoadConfig loads and parses the config file
+// LoadConfig loads and parses the config file, merged over the configs it extends
 func (cr *ConfigResolver) LoadConfig(configFile string) (*config.Config, error) {
-	yamlData, err := os.ReadFile(configFile)
-	if err != nil {
+	if _, err := os.Stat(configFile); err != nil {
 		return nil, configs.ReadError
 	}
 
-	cfg, err := config.NewConfigFromYAML(yamlData)
+	cfg, err := config.LoadConfigFile(configFile)
 	if err != nil {
-		return nil, configs.ParseError
+		return nil, fmt.Errorf("%w: %v", configs.ParseError, err)
 	}
 
 	return cfg, nil
@@ -127,20 +236,45 @@ func (cr *ConfigResolver) LoadConfigWithLogging(ctx context.Context, templateFil
 		logger.ErrorWithFields("Failed to load configuration", "error", err)
 		return nil, err
 	}
-	logger.LogConfigBuilding(configFile, cfg.Variables.Flatten())
-
-	// Resolve template path with logging
-	logger.Debug("Resolving template path")
-	err = cr.ResolveTemplatePath(cfg, templateFile, configFile)
-	if err != nil {
-		logger.ErrorWithFields("Failed to resolve template path", "error", err)
+	logger.Debug("Resolving profile, variable sources, template path and front matter")
+	if err := cr.ResolveConfig(cfg, templateFile, configFile); err != nil {
+		logger.ErrorWithFields("Failed to resolve configuration", "error", err)
 		return nil, err
 	}
+	if cfg.Profile != "" {
+		logger.InfoWithFields("Config profile applied", "profile", cfg.Profile)
+	}
+	logger.LogConfigBuilding(configFile, cfg.Variables.Flatten())
 	logger.LogDataMapping(cfg.Template.String(), cfg.Variables.Flatten())
 
 	return cfg, nil
 }
 
+// ResolveConfig completes a loaded config the way every command sees it: the profile, then the
+// variable sources, the template and partials paths, the template's front matter and schema
+// defaults, and last the ${...} interpolation
+func (cr *ConfigResolver) ResolveConfig(cfg *config.Config, templateFile, configFile string) error {
+	if err := cr.ResolveProfile(cfg); err != nil {
+		return err
+	}
+	if err := cr.ApplyVariableSources(cfg, configFile); err != nil {
+		return err
+	}
+	if err := cr.ResolveTemplatePath(cfg, templateFile, configFile); err != nil {
+		return err
+	}
+	if err := cr.ResolvePartialDirs(cfg, configFile); err != nil {
+		return err
+	}
+	if err := cr.ApplyFrontMatter(cfg); err != nil {
+		return err
+	}
+	if err := cr.ResolveSchema(cfg); err != nil {
+		return err
+	}
+	return cr.ResolveInterpolation(cfg, configFile)
+}
+
 // contextKey is a custom type for context keys to avoid collisions
 type contextKey string
 
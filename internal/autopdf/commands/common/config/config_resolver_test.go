@@ -5,8 +5,10 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/BuddhiLW/AutoPDF/configs"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common/testutil"
 	"github.com/BuddhiLW/AutoPDF/pkg/config"
 	"github.com/stretchr/testify/assert"
@@ -70,6 +72,32 @@ func TestConfigResolver_ResolveTemplatePath(t *testing.T) {
 	}
 }
 
+func TestConfigResolver_ResolvePartialDirs(t *testing.T) {
+	resolver := NewConfigResolver()
+	cfg := &config.Config{Partials: []string{"partials", "../shared/layouts", "/srv/partials"}}
+
+	require.NoError(t, resolver.ResolvePartialDirs(cfg, "/config/dir/config.yaml"))
+	assert.Equal(t, []string{"/config/dir/partials", "/config/shared/layouts", "/srv/partials"}, cfg.Partials)
+}
+
+func TestConfigResolver_ApplyFrontMatter(t *testing.T) {
+	resolver := NewConfigResolver()
+	templatePath := filepath.Join(t.TempDir(), "letter.tex")
+	require.NoError(t, os.WriteFile(templatePath, []byte("% ---\n% engine: xelatex\n% variables:\n%   required: [client]\n% ---\n"), 0644))
+
+	cfg := &config.Config{Template: config.Template(templatePath), Engine: "pdflatex", Variables: *config.NewVariables()}
+	err := resolver.ApplyFrontMatter(cfg)
+	assert.ErrorIs(t, err, configs.RequiredVariableError)
+	assert.ErrorContains(t, err, "client")
+	assert.Equal(t, config.Engine("xelatex"), cfg.Engine)
+
+	require.NoError(t, cfg.Variables.SetString("client", "ACME"))
+	assert.NoError(t, resolver.ApplyFrontMatter(cfg))
+
+	require.NoError(t, os.WriteFile(templatePath, []byte("% ---\n% passes: 20\n% ---\n"), 0644))
+	assert.ErrorIs(t, resolver.ApplyFrontMatter(cfg), configs.FrontMatterError)
+}
+
 func TestConfigResolver_ResolveConfigFile(t *testing.T) {
 	resolver := NewConfigResolver()
 
@@ -130,3 +158,132 @@ func TestConfigResolver_ResolveConfigFile(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigResolver_ResolveSchema(t *testing.T) {
+	resolver := NewConfigResolver()
+	templatePath := filepath.Join(t.TempDir(), "invoice.tex")
+	require.NoError(t, os.WriteFile(config.SchemaPath(templatePath), []byte(`{"type": "object", "properties": {"currency": {"type": "string", "default": "EUR"}}}`), 0644))
+
+	cfg := &config.Config{Template: config.Template(templatePath), Variables: *config.NewVariables()}
+	require.NoError(t, resolver.ResolveSchema(cfg))
+	require.NotNil(t, cfg.Schema)
+	currency, ok := cfg.Variables.GetString("currency")
+	assert.True(t, ok)
+	assert.Equal(t, "EUR", currency)
+
+	// A schema embedded in the config replaces the schema file
+	embedded := &config.Schema{Type: "object", Properties: map[string]*config.Schema{"vat": {Pattern: "("}}}
+	cfg = &config.Config{Template: config.Template(templatePath), Variables: *config.NewVariables(), Schema: embedded}
+	assert.ErrorIs(t, resolver.ResolveSchema(cfg), configs.SchemaError)
+	assert.Same(t, embedded, cfg.Schema)
+}
+
+func TestConfigResolver_ApplyVariableSources(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "autopdf.yaml")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.DotEnvFile), []byte("AUTOPDF_VAR_STAGE=draft\nAUTOPDF_VAR_VERSION=0.0.0\n"), 0644))
+
+	resolver := NewConfigResolver().WithVariableSources(config.VariableSources{
+		Environ: []string{"AUTOPDF_VAR_VERSION=1.4.2"},
+		Sets:    []config.Override{{Path: "client.name", Value: "ACME"}},
+	})
+	cfg := &config.Config{Variables: *config.NewVariables()}
+	require.NoError(t, resolver.ApplyVariableSources(cfg, configFile))
+	assert.Equal(t, map[string]string{"stage": "draft", "version": "1.4.2", "client.name": "ACME"}, cfg.Variables.Flatten())
+
+	resolver = NewConfigResolver().WithVariableSources(config.VariableSources{Files: []string{filepath.Join(dir, "missing.yaml")}})
+	assert.ErrorIs(t, resolver.ApplyVariableSources(cfg, configFile), configs.VariableSourceError)
+}
+
+func TestConfigResolver_LoadConfigExtends(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("engine: xelatex\nvariables: {company: ACME}\n"), 0644))
+	configFile := filepath.Join(dir, "autopdf.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("extends: base.yaml\nvariables: {title: Invoice}\n"), 0644))
+
+	resolver := NewConfigResolver()
+	cfg, err := resolver.LoadConfig(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, config.Engine("xelatex"), cfg.Engine)
+	assert.Equal(t, map[string]string{"company": "ACME", "title": "Invoice"}, cfg.Variables.Flatten())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("extends: autopdf.yaml\n"), 0644))
+	_, err = resolver.LoadConfig(configFile)
+	assert.ErrorIs(t, err, configs.ParseError)
+}
+
+func TestConfigResolver_ResolveProfile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "autopdf.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+passes: 2
+variables: {watermark: ""}
+profiles:
+  draft: {passes: 1, variables: {watermark: DRAFT}}
+  final: {passes: 3}
+`), 0644))
+
+	load := func(resolver *ConfigResolver) (*config.Config, error) {
+		cfg, err := resolver.LoadConfig(configFile)
+		require.NoError(t, err)
+		return cfg, resolver.ResolveProfile(cfg)
+	}
+
+	cfg, err := load(NewConfigResolver().WithProfile("final", "draft"))
+	require.NoError(t, err)
+	assert.Equal(t, "final", cfg.Profile)
+	assert.Equal(t, 3, cfg.Passes)
+
+	cfg, err = load(NewConfigResolver().WithProfile("", "draft"))
+	require.NoError(t, err)
+	assert.Equal(t, "draft", cfg.Profile)
+	assert.Equal(t, map[string]string{"watermark": "DRAFT"}, cfg.Variables.Flatten())
+
+	cfg, err = load(NewConfigResolver().WithProfile("", "print"))
+	require.NoError(t, err, "a default profile the config does not define is skipped")
+	assert.Empty(t, cfg.Profile)
+	assert.Equal(t, 2, cfg.Passes)
+
+	_, err = load(NewConfigResolver().WithProfile("print", ""))
+	assert.ErrorIs(t, err, configs.ProfileError)
+}
+
+func TestConfigResolver_ResolveConfig_ProfileOverFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "letter.tex")
+	require.NoError(t, os.WriteFile(templatePath, []byte("% ---\n% engine: xelatex\n% passes: 2\n% ---\n"), 0644))
+	configFile := filepath.Join(dir, "autopdf.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+template: letter.tex
+profiles:
+  final: {engine: lualatex}
+`), 0644))
+
+	resolver := NewConfigResolver().WithProfile("final", "")
+	cfg, err := resolver.LoadConfig(configFile)
+	require.NoError(t, err)
+	require.NoError(t, resolver.ResolveConfig(cfg, "", configFile))
+
+	assert.Equal(t, config.Engine("lualatex"), cfg.Engine, "the selected profile's engine wins")
+	assert.Equal(t, 2, cfg.Passes, "the front matter fills in what the profile leaves unset")
+}
+
+func TestConfigResolver_ResolveInterpolation(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "autopdf.yaml")
+	cfg, err := config.NewConfigFromYAML([]byte(`
+output: "${client.name}.pdf"
+variables:
+  client: {name: ACME}
+  subject: "Invoice for ${client.name}"
+`))
+	require.NoError(t, err)
+
+	resolver := NewConfigResolver()
+	require.NoError(t, resolver.ResolveInterpolation(cfg, configFile))
+	assert.Equal(t, config.Output("ACME.pdf"), cfg.Output)
+	subject, _ := cfg.Variables.GetString("subject")
+	assert.Equal(t, "Invoice for ACME", subject)
+
+	require.NoError(t, cfg.Variables.SetString("subject", "${subject}"))
+	assert.ErrorIs(t, resolver.ResolveInterpolation(cfg, configFile), configs.InterpolationError)
+}
@@ -5,23 +5,46 @@ package result
 
 import (
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	documentService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/document"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common/wiring"
 )
 
 // ResultHandler handles the output of build results
-type ResultHandler struct{}
+type ResultHandler struct {
+	debug bool // Also print the build workspace
+}
 
 // NewResultHandler creates a new result handler
 func NewResultHandler() *ResultHandler {
 	return &ResultHandler{}
 }
 
+// WithDebug enables debug output such as the build workspace path
+func (rh *ResultHandler) WithDebug(enabled bool) *ResultHandler {
+	rh.debug = enabled
+	return rh
+}
+
 // HandleBuildResult processes and displays the build result
 func (rh *ResultHandler) HandleBuildResult(result documentService.BuildResult) error {
 	// Create logger for user feedback
 	logger := logger.NewLoggerAdapter(logger.Detailed, "stdout")
-	logger.InfoWithFields("Successfully built PDF", "pdf_path", result.PDFPath)
+	if result.Passes > 0 {
+		logger.InfoWithFields("Successfully built PDF", "pdf_path", result.PDFPath, "passes", result.Passes)
+	} else {
+		logger.InfoWithFields("Successfully built PDF", "pdf_path", result.PDFPath)
+	}
+
+	if result.Profile != "" {
+		logger.InfoWithFields("Config profile", "profile", result.Profile)
+	}
+
+	if result.Cache != ports.CacheDisabled {
+		logger.InfoWithFields("Compile cache", "status", string(result.Cache), "key", result.CacheKey)
+	}
+
+	rh.logWorkspace(logger, result)
 
 	if len(result.ImagePaths) > 0 {
 		logger.Info("Generated image files:")
@@ -30,6 +53,8 @@ func (rh *ResultHandler) HandleBuildResult(result documentService.BuildResult) e
 		}
 	}
 
+	rh.logDiagnostics(logger, result.Diagnostics)
+
 	if result.Error != nil {
 		logger.WarnWithFields("Warning", "error", result.Error)
 	}
@@ -37,6 +62,49 @@ func (rh *ResultHandler) HandleBuildResult(result documentService.BuildResult) e
 	return nil
 }
 
+// HandleBuildFailure displays the diagnostics of a failed build
+func (rh *ResultHandler) HandleBuildFailure(result documentService.BuildResult) error {
+	logger := logger.NewLoggerAdapter(logger.Detailed, "stdout")
+	defer logger.Sync()
+
+	rh.logWorkspace(logger, result)
+	rh.logDiagnostics(logger, result.Diagnostics)
+
+	if result.Error != nil {
+		logger.ErrorWithFields("Build failed", "error", result.Error)
+	}
+
+	return nil
+}
+
+// logWorkspace prints the directory the engine ran in and the staged assets when debug output is enabled
+func (rh *ResultHandler) logWorkspace(logger *logger.LoggerAdapter, result documentService.BuildResult) {
+	if !rh.debug {
+		return
+	}
+	if result.Workspace != "" {
+		logger.InfoWithFields("Build workspace", "workspace", result.Workspace)
+	}
+	for _, asset := range result.Assets {
+		logger.InfoWithFields("  - Staged asset", "file", asset)
+	}
+}
+
+// logDiagnostics prints each compiler diagnostic as "file:line: message"
+func (rh *ResultHandler) logDiagnostics(logger *logger.LoggerAdapter, diagnostics []ports.Diagnostic) {
+	for _, d := range diagnostics {
+		fields := []interface{}{"kind", string(d.Kind)}
+		if d.Context != "" {
+			fields = append(fields, "context", d.Context)
+		}
+		if d.IsError() {
+			logger.ErrorWithFields(d.String(), fields...)
+		} else {
+			logger.WarnWithFields(d.String(), fields...)
+		}
+	}
+}
+
 // NewConvertResultHandler creates a new convert result handler
 func NewConvertResultHandler() *ResultHandler {
 	return &ResultHandler{}
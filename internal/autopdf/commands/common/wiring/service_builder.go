@@ -11,11 +11,15 @@ import (
 	"strings"
 
 	"github.com/BuddhiLW/AutoPDF/configs"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/assets"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/cache"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/cleaner"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/converter"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/latex"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/template"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/typst"
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	documentService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/document"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common/args"
 	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
@@ -39,15 +43,19 @@ func NewServiceBuilder() *ServiceBuilder {
 func (sb *ServiceBuilder) BuildDocumentService(cfg *config.Config) *documentService.DocumentService {
 	// Create infrastructure adapters (DIP: Application depends on abstractions)
 	fileSystem := infraadapters.NewOSFileSystem()
-	executor := infraadapters.NewOSCommandExecutor()
+	executor := sb.BuildCommandExecutor(cfg)
+	templateAdapter := template.NewTemplateProcessorAdapter(cfg)
 
 	return &documentService.DocumentService{
-		TemplateProcessor: template.NewTemplateProcessorAdapter(cfg),
-		LaTeXCompiler:     latex.NewLaTeXCompilerAdapter(cfg, fileSystem, executor),
+		TemplateProcessor: templateAdapter,
+		LaTeXCompiler:     sb.BuildCompileCache(cfg, sb.BuildEngineCompiler(cfg, fileSystem, executor, latex.NewLaTeXCompilerAdapter(cfg, fileSystem, executor))),
 		Converter:         converter.NewConverterAdapter(cfg),
 		Cleaner:           cleaner.NewCleanerAdapter(),
 		PathOps:           infraadapters.NewOSPathOperations(),
 		FileSystem:        infraadapters.NewOSFileSystem(),
+		Workspaces:        sb.BuildWorkspaceManager(cfg),
+		AssetStager:       sb.BuildAssetStager(cfg, fileSystem),
+		OutputPaths:       templateAdapter,
 		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
 	}
 }
@@ -57,30 +65,104 @@ func (sb *ServiceBuilder) BuildDocumentService(cfg *config.Config) *documentServ
 func (sb *ServiceBuilder) BuildDocumentServiceWithWorkingDir(cfg *config.Config, workingDir string) *documentService.DocumentService {
 	// Create infrastructure adapters (DIP: Application depends on abstractions)
 	fileSystem := infraadapters.NewOSFileSystem()
-	executor := infraadapters.NewOSCommandExecutor()
+	executor := sb.BuildCommandExecutor(cfg)
+	templateAdapter := template.NewTemplateProcessorAdapter(cfg)
 
 	return &documentService.DocumentService{
-		TemplateProcessor: template.NewTemplateProcessorAdapter(cfg),
-		LaTeXCompiler:     latex.NewLaTeXCompilerAdapterWithWorkingDir(cfg, fileSystem, executor, workingDir),
+		TemplateProcessor: templateAdapter,
+		LaTeXCompiler:     sb.BuildCompileCache(cfg, sb.BuildEngineCompiler(cfg, fileSystem, executor, latex.NewLaTeXCompilerAdapterWithWorkingDir(cfg, fileSystem, executor, workingDir))),
 		Converter:         converter.NewConverterAdapter(cfg),
 		Cleaner:           cleaner.NewCleanerAdapter(),
 		PathOps:           infraadapters.NewOSPathOperations(),
 		FileSystem:        infraadapters.NewOSFileSystem(),
+		Workspaces:        sb.BuildWorkspaceManager(cfg),
+		AssetStager:       sb.BuildAssetStager(cfg, fileSystem),
+		OutputPaths:       templateAdapter,
 		ErrorFactory:      apperrors.NewDomainErrorFactory(nil),
 	}
 }
 
+// BuildSecurityPolicy constructs the policy engine and tool runs are subject to
+// Invalid security settings fall back to the default sandbox rather than to no restrictions
+func (sb *ServiceBuilder) BuildSecurityPolicy(cfg *config.Config) ports.SecurityPolicy {
+	policy, err := infraadapters.NewSecurityPolicyFromConfig(cfg.Security)
+	if err != nil {
+		log.Printf("Invalid security settings, using the default sandbox: %v", err)
+		return ports.NewSandboxPolicy()
+	}
+	return policy
+}
+
+// BuildCommandExecutor constructs the executor running the engine and tools under the security policy
+func (sb *ServiceBuilder) BuildCommandExecutor(cfg *config.Config) ports.CommandExecutor {
+	executor, _ := infraadapters.NewSandboxedCommandExecutor(infraadapters.NewOSCommandExecutor(), sb.BuildSecurityPolicy(cfg))
+	return executor
+}
+
+// BuildEngineCompiler returns the tectonic or typst compiler when the config selects that engine, latexCompiler otherwise
+func (sb *ServiceBuilder) BuildEngineCompiler(cfg *config.Config, fileSystem ports.FileSystem, executor ports.CommandExecutor, latexCompiler ports.LaTeXCompiler) ports.LaTeXCompiler {
+	switch cfg.Engine.String() {
+	case ports.EngineTectonic:
+		return latex.NewTectonicCompilerAdapter(cfg, fileSystem, executor)
+	case ports.EngineTypst:
+		return typst.NewTypstCompilerAdapter(cfg, fileSystem, executor)
+	}
+	return latexCompiler
+}
+
+// BuildCompileCache wraps the compiler with the compile cache when the config enables it
+// Invalid cache settings fall back to compiling every build
+func (sb *ServiceBuilder) BuildCompileCache(cfg *config.Config, compiler ports.LaTeXCompiler) ports.LaTeXCompiler {
+	cached, err := cache.NewCompileCacheFromConfig(compiler, cfg)
+	if err != nil {
+		log.Printf("Invalid cache settings, compiling without a cache: %v", err)
+		return compiler
+	}
+	return cached
+}
+
+// BuildWorkspaceManager constructs the manager giving each build its own directory
+// Invalid workspace settings fall back to deleting each workspace after its build
+func (sb *ServiceBuilder) BuildWorkspaceManager(cfg *config.Config) ports.WorkspaceManager {
+	manager, err := infraadapters.NewWorkspaceManagerFromConfig(cfg.Workspace)
+	if err != nil {
+		log.Printf("Invalid workspace settings, deleting workspaces after each build: %v", err)
+		manager, _ = infraadapters.NewOSWorkspaceManager(cfg.Workspace.Root, ports.NewRetentionPolicy("", 0, 0))
+	}
+	return manager
+}
+
+// BuildAssetStager constructs the stager bringing referenced files into the workspace
+// Invalid asset settings fall back to linking only the referenced files
+func (sb *ServiceBuilder) BuildAssetStager(cfg *config.Config, fileSystem ports.FileSystem) ports.AssetStager {
+	stager, err := assets.NewAssetStagerAdapter(fileSystem, cfg.Engine.String(), cfg.Assets.Mode, cfg.Assets.Extra)
+	if err != nil {
+		log.Printf("Invalid asset settings, linking referenced files only: %v", err)
+		stager, _ = assets.NewAssetStagerAdapter(fileSystem, cfg.Engine.String(), ports.AssetModeSymlink, nil)
+	}
+	return stager
+}
+
 // BuildRequest constructs a BuildRequest from the parsed arguments and config
 func (sb *ServiceBuilder) BuildRequest(args *args.BuildArgs, cfg *config.Config) documentService.BuildRequest {
 	return documentService.BuildRequest{
 		TemplatePath: cfg.Template.String(),
 		ConfigPath:   args.ConfigFile,
+		Profile:      cfg.Profile,
 		Variables:    &cfg.Variables, // Use complex variables from pkg/
+		Schema:       cfg.Schema,
 		Engine:       cfg.Engine.String(),
 		OutputPath:   cfg.Output.String(),
+		OnCollision:  cfg.OutputCollision,
 		DoConvert:    cfg.Conversion.Enabled,
 		DoClean:      args.Options.Clean.Enabled,
 		DebugEnabled: args.Options.Debug.Enabled, // Pass debug option for persistent concrete files
+		NoCache:      args.Options.NoCache.Enabled,
+		Passes:       cfg.Passes,
+		AutoPasses:   cfg.AutoPasses,
+		UseLatexmk:   cfg.UseLatexmk,
+		Toolchain:    ports.NewToolchain(cfg.Bibliography, cfg.Index, cfg.Glossary),
+		Security:     sb.BuildSecurityPolicy(cfg),
 		Conversion: documentService.ConversionSettings{
 			Enabled: cfg.Conversion.Enabled,
 			Formats: cfg.Conversion.Formats,
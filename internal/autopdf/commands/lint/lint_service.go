@@ -0,0 +1,247 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BuddhiLW/AutoPDF/configs"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/template"
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	persistentService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/persistent"
+	argsPkg "github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common/args"
+	configPkg "github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/common/config"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/rwxrob/bonzai"
+	"github.com/rwxrob/bonzai/cmds/help"
+	"github.com/rwxrob/bonzai/comp"
+)
+
+// LintServiceCmd checks a template against its config without compiling it
+var LintServiceCmd = &bonzai.Cmd{
+	Name:    `lint`,
+	Alias:   `l`,
+	Short:   `check a template for mistakes without compiling it`,
+	Usage:   `TEMPLATE [CONFIG] [json] [OPTIONS...]`,
+	MinArgs: 1,
+	MaxArgs: 0, // Repeated --set flags are not limited
+	Long: `
+The lint command parses a template and the partials it includes and reports, as
+file:line diagnostics:
+- template syntax errors, and functions or templates that are not defined
+- variables the template uses that the config does not define
+- config variables the template never uses
+- range over values that are not lists
+- LaTeX environments and braces that are not balanced, outside comments and verbatim text
+
+If no configuration file is given, config.yaml in the current directory is used when it
+exists; otherwise the checks depending on the variables are skipped.
+
+The variables are the ones build would use: profile=NAME, --vars, --env-file, --set-file
+and --set work as they do for build, and so do the environment, the .env file, the
+template's front matter and the defaults of its schema.
+
+With json (or --json), the diagnostics are printed as JSON.
+The command fails when an error is found, so it can run as a pre-commit hook.
+
+Examples:
+  autopdf lint template.tex
+  autopdf lint template.tex config.yaml
+  autopdf lint template.tex config.yaml json
+  autopdf lint template.tex config.yaml profile=final --set version=1.4.2
+`,
+	Comp: comp.Cmds,
+	Cmds: []*bonzai.Cmd{
+		help.Cmd,
+	},
+	Do: func(cmd *bonzai.Cmd, args ...string) error {
+		return executeLint(os.Stdout, args)
+	},
+}
+
+// lintArgs are the parsed arguments of the lint command
+type lintArgs struct {
+	TemplateFile string
+	ConfigFile   string
+	JSON         bool
+	Profile      string
+	Sources      config.VariableSources
+}
+
+func parseLintArgs(args []string) (lintArgs, error) {
+	var parsed lintArgs
+	args, sources, err := argsPkg.NewArgsParser().ParseVariableSources(args)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Sources = sources
+	for _, arg := range args {
+		name, value, isValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		switch {
+		case arg == "json" || arg == "--json":
+			parsed.JSON = true
+		case isValue && name == "profile":
+			if value == "" {
+				return parsed, fmt.Errorf("profile requires a name")
+			}
+			parsed.Profile = value
+		case parsed.TemplateFile == "":
+			parsed.TemplateFile = arg
+		case parsed.ConfigFile == "":
+			parsed.ConfigFile = arg
+		default:
+			return parsed, fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+	if parsed.TemplateFile == "" {
+		return parsed, fmt.Errorf("template file is required")
+	}
+	return parsed, nil
+}
+
+// lintReport is the JSON output of the lint command
+type lintReport struct {
+	Diagnostics []lintDiagnostic `json:"diagnostics"`
+	Errors      int              `json:"errors"`
+	Warnings    int              `json:"warnings"`
+}
+
+type lintDiagnostic struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	Context  string `json:"context,omitempty"`
+}
+
+// executeLint lints the template of args and writes its diagnostics to w
+func executeLint(w io.Writer, args []string) error {
+	parsed, err := parseLintArgs(args)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(parsed.TemplateFile)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+	cfg, configFile, err := loadLintConfig(parsed)
+	if err != nil {
+		return err
+	}
+
+	dialect := template.DialectForTemplate(parsed.TemplateFile)
+	var vars *template.LintVariables
+	if cfg != nil {
+		if dialect, err = template.ConfigDialect(cfg); err != nil {
+			return err
+		}
+		vars = &template.LintVariables{
+			Data:  cfg.Variables.TemplateData(),
+			File:  configFile,
+			Lines: cfg.Variables.Lines(),
+		}
+	}
+	partials, err := template.LoadPartials(template.PartialDirs(cfg), dialect)
+	if err != nil {
+		return err
+	}
+
+	diagnostics := template.Lint(parsed.TemplateFile, string(content), dialect, partials, vars)
+	report := newLintReport(diagnostics)
+	if parsed.JSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		writeLintReport(w, report)
+	}
+
+	if report.Errors > 0 {
+		return configs.LintError
+	}
+	return nil
+}
+
+// loadLintConfig loads the given config, or config.yaml when it exists, and resolves it as build does
+// Without either, it returns a nil config
+func loadLintConfig(parsed lintArgs) (*config.Config, string, error) {
+	configFile := parsed.ConfigFile
+	if configFile == "" {
+		if _, err := os.Stat(configs.DefaultConfigName); err != nil {
+			return nil, "", nil
+		}
+		configFile = configs.DefaultConfigName
+	}
+
+	resolver := configPkg.NewConfigResolver().
+		WithVariableSources(parsed.Sources).
+		WithProfile(parsed.Profile, persistentService.NewPersistentService().GetDefaultProfile())
+	cfg, err := resolver.LoadConfig(configFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", err, configFile)
+	}
+	if err := resolver.ResolveConfig(cfg, parsed.TemplateFile, configFile); err != nil {
+		return nil, "", err
+	}
+	return cfg, configFile, nil
+}
+
+func newLintReport(diagnostics []ports.Diagnostic) lintReport {
+	report := lintReport{Diagnostics: make([]lintDiagnostic, 0, len(diagnostics))}
+	for _, d := range diagnostics {
+		if d.IsError() {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+		report.Diagnostics = append(report.Diagnostics, lintDiagnostic{
+			File:     relativePath(d.File),
+			Line:     d.Line,
+			Severity: string(d.Severity),
+			Kind:     string(d.Kind),
+			Message:  d.Message,
+			Context:  d.Context,
+		})
+	}
+	return report
+}
+
+// writeLintReport prints one "file:line: severity: message (kind)" line per diagnostic
+func writeLintReport(w io.Writer, report lintReport) {
+	for _, d := range report.Diagnostics {
+		location := d.File
+		if d.Line > 0 {
+			location = fmt.Sprintf("%s:%d", d.File, d.Line)
+		}
+		fmt.Fprintf(w, "%s: %s: %s (%s)\n", location, d.Severity, d.Message, d.Kind)
+	}
+	if len(report.Diagnostics) > 0 {
+		fmt.Fprintf(w, "%d error(s), %d warning(s)\n", report.Errors, report.Warnings)
+	}
+}
+
+// relativePath shortens paths below the working directory, such as resolved partial directories
+func relativePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
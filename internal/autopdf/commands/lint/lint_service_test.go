@@ -0,0 +1,102 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BuddhiLW/AutoPDF/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLintFixture(t *testing.T) (templatePath, configPath string) {
+	dir := t.TempDir()
+	templatePath = filepath.Join(dir, "letter.tex")
+	configPath = filepath.Join(dir, "autopdf.yaml")
+	require.NoError(t, os.WriteFile(templatePath, []byte(`\begin{document}
+Dear delim[[.name]], delim[[.greeting]]
+\end{document}
+`), 0644))
+	require.NoError(t, os.WriteFile(configPath, []byte(`template: letter.tex
+variables:
+  name: Ann
+  signature: Bob
+`), 0644))
+	return templatePath, configPath
+}
+
+func TestExecuteLint_Human(t *testing.T) {
+	templatePath, configPath := writeLintFixture(t)
+
+	var out bytes.Buffer
+	require.NoError(t, executeLint(&out, []string{templatePath, configPath}))
+	assert.Equal(t, configPath+`:4: warning: variable "signature" is not used by the template (unused_variable)
+`+templatePath+`:2: warning: variable "greeting" is not defined (undefined_variable)
+0 error(s), 2 warning(s)
+`, out.String())
+}
+
+func TestExecuteLint_JSONAndErrors(t *testing.T) {
+	templatePath, configPath := writeLintFixture(t)
+	require.NoError(t, os.WriteFile(templatePath, []byte("\\begin{document}\ndelim[[.name | shout]]\n"), 0644))
+
+	var out bytes.Buffer
+	err := executeLint(&out, []string{templatePath, configPath, "--json"})
+	assert.ErrorIs(t, err, configs.LintError)
+
+	var report lintReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 2, report.Errors)
+	assert.Equal(t, 1, report.Warnings)
+	require.Len(t, report.Diagnostics, 3)
+	assert.Equal(t, "unused_variable", report.Diagnostics[0].Kind)
+	assert.Equal(t, lintDiagnostic{
+		File:     templatePath,
+		Line:     1,
+		Severity: "error",
+		Kind:     "unbalanced_environment",
+		Message:  `\begin{document} is never ended`,
+		Context:  `\begin{document}`,
+	}, report.Diagnostics[1])
+	assert.Equal(t, "unknown_function", report.Diagnostics[2].Kind)
+}
+
+func TestExecuteLint_SeesBuildVariables(t *testing.T) {
+	templatePath, configPath := writeLintFixture(t)
+	require.NoError(t, os.WriteFile(templatePath, []byte(`\begin{document}
+Dear delim[[.name]], delim[[.greeting]] delim[[.closing]]
+\end{document}
+`), 0644))
+	require.NoError(t, os.WriteFile(configPath, []byte(`template: letter.tex
+variables:
+  name: Ann
+profiles:
+  final:
+    variables:
+      greeting: Hello
+`), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, executeLint(&out, []string{templatePath, configPath, "profile=final", "--set", "closing=Regards"}))
+	assert.Empty(t, out.String(), "the profile and --set define every variable")
+}
+
+func TestParseLintArgs(t *testing.T) {
+	parsed, err := parseLintArgs([]string{"a.tex", "json", "b.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, lintArgs{TemplateFile: "a.tex", ConfigFile: "b.yaml", JSON: true}, parsed)
+
+	parsed, err = parseLintArgs([]string{"a.tex", "profile=final", "--set", "n=1"})
+	require.NoError(t, err)
+	assert.Equal(t, "final", parsed.Profile)
+	assert.Len(t, parsed.Sources.Sets, 1)
+
+	_, err = parseLintArgs([]string{"json"})
+	assert.Error(t, err)
+}
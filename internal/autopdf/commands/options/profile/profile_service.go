@@ -0,0 +1,96 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package profile
+
+import (
+	"fmt"
+
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
+	persistentService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/persistent"
+	"github.com/rwxrob/bonzai"
+	"github.com/rwxrob/bonzai/cmds/help"
+	"github.com/rwxrob/bonzai/comp"
+)
+
+// ProfileServiceCmd shows or sets the persistent default config profile
+var ProfileServiceCmd = &bonzai.Cmd{
+	Name:    `profile`,
+	Alias:   `p`,
+	Short:   `show or set the default config profile`,
+	Usage:   `[NAME]`,
+	MinArgs: 0,
+	MaxArgs: 1,
+	Long: `
+The profile command manages the persistent default profile, applied to every
+build whose config defines a profile of that name. Configs without it are
+built as written. A profile selected with profile=NAME on the build command
+line takes precedence.
+
+Subcommands:
+  clear - Remove the default profile (persistent)
+
+Without a name, it shows the current default profile.
+
+Examples:
+  autopdf profile                  # Show the default profile
+  autopdf profile draft            # Apply the draft profile by default
+  autopdf profile clear            # Build configs as written by default
+`,
+	Comp: comp.Cmds,
+	Cmds: []*bonzai.Cmd{
+		help.Cmd,
+		ProfileClearCmd,
+	},
+	Do: func(cmd *bonzai.Cmd, args ...string) error {
+		persistentSvc := persistentService.NewPersistentService()
+
+		// Create logger for user feedback
+		logger := logger.NewLoggerAdapter(logger.Detailed, "stdout")
+
+		if len(args) == 0 {
+			if name := persistentSvc.GetDefaultProfile(); name != "" {
+				logger.InfoWithFields("📋 Default profile", "profile", name)
+			} else {
+				logger.Info("📋 No default profile: configs are built as written")
+			}
+			logger.InfoWithFields("📁 Config file", "path", persistentSvc.GetConfigPath())
+			return nil
+		}
+
+		if err := persistentSvc.SetDefaultProfile(args[0]); err != nil {
+			return fmt.Errorf("failed to set the default profile: %w", err)
+		}
+		logger.InfoWithFields("✅ Default profile set (persistent)", "profile", args[0])
+		logger.Info("Configs defining this profile will be built with it.")
+		return nil
+	},
+}
+
+// ProfileClearCmd removes the persistent default profile
+var ProfileClearCmd = &bonzai.Cmd{
+	Name:    `clear`,
+	Short:   `remove the default config profile`,
+	Usage:   ``,
+	MinArgs: 0,
+	MaxArgs: 0,
+	Long: `
+Remove the persistent default profile, so configs are built as written unless
+a profile is selected with profile=NAME.
+
+Examples:
+  autopdf profile clear
+`,
+	Do: func(cmd *bonzai.Cmd, args ...string) error {
+		persistentSvc := persistentService.NewPersistentService()
+
+		if err := persistentSvc.SetDefaultProfile(""); err != nil {
+			return fmt.Errorf("failed to clear the default profile: %w", err)
+		}
+
+		// Create logger for user feedback
+		logger := logger.NewLoggerAdapter(logger.Detailed, "stdout")
+		logger.Info("❌ Default profile cleared (persistent)")
+		return nil
+	},
+}
@@ -66,9 +66,10 @@ func (d *DocumentRebuildAdapter) Rebuild(ctx context.Context, templatePath, conf
 	req := documentService.BuildRequest{
 		TemplatePath: cfg.Template.String(),
 		ConfigPath:   configPath,
+		Profile:      cfg.Profile,
 		Variables:    &cfg.Variables,
 		Engine:       cfg.Engine.String(),
-		OutputPath:   cfg.Output.String(),
+		OutputPath:   cfg.Output.String(), // Each rebuild replaces the last PDF, whatever output_collision says
 		DoConvert:    cfg.Conversion.Enabled,
 		DoClean:      false, // Don't clean in watch mode by default
 		DebugEnabled: false,
@@ -96,6 +97,7 @@ func (d *DocumentRebuildAdapter) Rebuild(ctx context.Context, templatePath, conf
 	d.logger.InfoWithFields("Rebuild completed successfully",
 		"template", templatePath,
 		"pdf_path", result.PDFPath,
+		"profile", result.Profile,
 	)
 
 	return ports.RebuildResult{
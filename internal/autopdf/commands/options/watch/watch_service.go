@@ -14,6 +14,7 @@ import (
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/debounce"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/pattern_matcher"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/template"
 	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	persistentService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/persistent"
 	watchService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/watch"
@@ -25,6 +26,7 @@ import (
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/commands/options/watch/interval"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/domain/options"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/domain/watch"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 	"github.com/rwxrob/bonzai"
 	"github.com/rwxrob/bonzai/cmds/help"
 	"github.com/rwxrob/bonzai/comp"
@@ -37,7 +39,7 @@ var WatchServiceCmd = &bonzai.Cmd{
 	Short:   `watch files and auto-rebuild on changes`,
 	Usage:   `TEMPLATE [CONFIG] [OPTIONS...]`,
 	MinArgs: 1,
-	MaxArgs: 10, // Allow up to 10 arguments for multiple options
+	MaxArgs: 0, // Options and repeated --set flags are not limited
 	Long: `
 The watch command monitors template and configuration files for changes and automatically
 rebuilds the PDF when modifications are detected.
@@ -51,12 +53,17 @@ Features:
 - Automatic PDF regeneration on file changes
 - Debounced file system events (prevents multiple rebuilds)
 - Configurable watch patterns and exclusions via subcommands
+- The variable sources of build: --vars, --env-file, --set-file and --set,
+  read again on every rebuild
+- The profile=NAME option of build, or the default profile
 
 Examples:
   autopdf watch template.tex
   autopdf watch template.tex config.yaml
   autopdf watch template.tex exclude "*.aux" "*.log"
   autopdf watch template.tex interval 1s
+  autopdf watch template.tex config.yaml --set draft=true
+  autopdf watch template.tex config.yaml profile=draft
 `,
 	Comp: comp.Cmds,
 	Cmds: []*bonzai.Cmd{
@@ -86,12 +93,17 @@ type WatchConfig struct {
 // ExecuteWatchProcess orchestrates file watching and automatic rebuilding
 // This is the public API that maintains backward compatibility
 func ExecuteWatchProcess(ctx context.Context, args []string) error {
-	return ExecuteWatchProcessWithOptions(ctx, args, options.NewBuildOptions())
+	args, sources, err := argsPkg.NewArgsParser().ParseVariableSources(args)
+	if err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	return ExecuteWatchProcessWithOptions(ctx, args, options.NewBuildOptions(), sources)
 }
 
 // ExecuteWatchProcessWithOptions orchestrates file watching with explicit BuildOptions
+// and the variable sources applied over the config on every rebuild
 // Following CLARITY: explicit dependency injection of options for better control
-func ExecuteWatchProcessWithOptions(ctx context.Context, args []string, buildOpts options.BuildOptions) error {
+func ExecuteWatchProcessWithOptions(ctx context.Context, args []string, buildOpts options.BuildOptions, sources config.VariableSources) error {
 	// Create logger with options from BuildOptions
 	// Following CLARITY: use options explicitly rather than relying on context
 	logger := createLoggerFromOptions(buildOpts)
@@ -101,10 +113,14 @@ func ExecuteWatchProcessWithOptions(ctx context.Context, args []string, buildOpt
 
 	// Filter out options first (they're ignored by watch but prevent errors)
 	argsParser := argsPkg.NewArgsParser()
-	cleanArgs, _, err := argsParser.ParseArgsWithOptions(args)
+	cleanArgs, parsedOpts, err := argsParser.ParseArgsWithOptions(args)
 	if err != nil {
 		return fmt.Errorf("failed to parse arguments: %w", err)
 	}
+	profile := buildOpts.Profile.Name
+	if profile == "" {
+		profile = parsedOpts.Profile.Name
+	}
 
 	// Parse watch arguments from cleaned args
 	watchConfig, err := parseWatchArgs(cleanArgs)
@@ -122,7 +138,9 @@ func ExecuteWatchProcessWithOptions(ctx context.Context, args []string, buildOpt
 
 	// Create rebuild service adapter following DIP
 	// Following CLARITY: compose services via dependency injection
-	configResolver := configPkg.NewConfigResolver()
+	configResolver := configPkg.NewConfigResolver().
+		WithVariableSources(sources).
+		WithProfile(profile, persistentService.NewPersistentService().GetDefaultProfile())
 	serviceBuilder := wiringPkg.NewServiceBuilder()
 	rebuildService := NewDocumentRebuildAdapter(configResolver, serviceBuilder, logger)
 
@@ -138,6 +156,9 @@ func ExecuteWatchProcessWithOptions(ctx context.Context, args []string, buildOpt
 
 	changeProcessor := createFileChangeProcessor(ctx, logger, rebuildService, absTemplatePath, absConfigPath)
 
+	// Partials are rendered into the template, so their directories are watched too
+	partialDirs := resolvePartialDirs(configResolver, absConfigPath, logger)
+
 	// Create watch application service
 	watchSvc := watchService.NewWatchApplicationService(
 		patternMatcher,
@@ -154,6 +175,7 @@ func ExecuteWatchProcessWithOptions(ctx context.Context, args []string, buildOpt
 		DebounceInterval:  watchConfig.Interval,
 		ExclusionPatterns: watchConfig.Exclude,
 		InclusionPatterns: watchConfig.Include,
+		PartialDirs:       partialDirs,
 	}
 
 	// Start watching
@@ -172,6 +194,20 @@ func ExecuteWatchProcessWithOptions(ctx context.Context, args []string, buildOpt
 	select {}
 }
 
+// resolvePartialDirs returns the partial directories templates are rendered with
+// A config that cannot be loaded yet leaves only the global directory to watch
+func resolvePartialDirs(configResolver *configPkg.ConfigResolver, configFile string, logger *logger.LoggerAdapter) []string {
+	cfg, err := configResolver.LoadConfig(configFile)
+	if err == nil {
+		err = configResolver.ResolvePartialDirs(cfg, configFile)
+	}
+	if err != nil {
+		logger.WarnWithFields("Configured partials directories are not watched", "config", configFile, "error", err)
+		return template.PartialDirs(nil)
+	}
+	return template.PartialDirs(cfg)
+}
+
 // createFileChangeProcessor creates a file change processor
 // Following CLARITY: explicit dependencies via constructor (Dependency Injection)
 // Following DIP: depends on RebuildService port (abstraction)
@@ -280,7 +316,7 @@ func parseWatchArgs(args []string) (*WatchConfig, error) {
 		ConfigFile:   "autopdf.yaml", // Default config
 		Interval:     500 * time.Millisecond,
 		Exclude:      []string{"*.aux", "*.log", "*.out", "*.toc", "*.fdb_latexmk", "*.fls", "*.synctex.gz"},
-		Include:      []string{"*.tex", "*.yaml", "*.yml", "*.cls", "*.png", "*.jpg", "*.jpeg", "*.pdf"},
+		Include:      []string{"*.tex", "*.typ", "*.yaml", "*.yml", "*.cls", "*.png", "*.jpg", "*.jpeg", "*.pdf"},
 	}
 
 	// Parse config file if provided
@@ -12,6 +12,8 @@ type BuildOptions struct {
 	Debug   DebugOption
 	Force   ForceOption
 	Watch   WatchOption
+	NoCache NoCacheOption
+	Profile ProfileOption
 }
 
 // CleanOption represents the clean auxiliary files option
@@ -44,6 +46,16 @@ type WatchOption struct {
 	Interval time.Duration // Watch interval, defaults to 500ms
 }
 
+// NoCacheOption represents bypassing the compile cache for a build
+type NoCacheOption struct {
+	Enabled bool
+}
+
+// ProfileOption represents the config profile a build applies
+type ProfileOption struct {
+	Name string // Profile name, empty for the config as written
+}
+
 // NewBuildOptions creates a new BuildOptions with default values
 func NewBuildOptions() BuildOptions {
 	return BuildOptions{
@@ -52,6 +64,7 @@ func NewBuildOptions() BuildOptions {
 		Debug:   DebugOption{Enabled: false, Output: "stdout"},
 		Force:   ForceOption{Enabled: false, Overwrite: false},
 		Watch:   WatchOption{Enabled: false, Interval: 500 * time.Millisecond},
+		NoCache: NoCacheOption{Enabled: false},
 	}
 }
 
@@ -85,6 +98,16 @@ func (bo *BuildOptions) EnableWatch(interval time.Duration) {
 	bo.Watch.Interval = interval
 }
 
+// EnableNoCache makes the build compile even when a cached PDF exists
+func (bo *BuildOptions) EnableNoCache() {
+	bo.NoCache.Enabled = true
+}
+
+// SelectProfile makes the build apply the named profile of its config
+func (bo *BuildOptions) SelectProfile(name string) {
+	bo.Profile.Name = name
+}
+
 // HasAnyEnabled returns true if any option is enabled
 func (bo *BuildOptions) HasAnyEnabled() bool {
 	for _, option := range bo.GetEnabledOptions() {
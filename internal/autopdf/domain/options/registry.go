@@ -3,21 +3,28 @@
 
 package options
 
+import "strings"
+
 // OptionRegistry provides a centralized registry for all available options
 type OptionRegistry struct {
-	knownOptions     map[string]bool
-	knownSubcommands map[string]bool
+	knownOptions      map[string]bool
+	knownValueOptions map[string]bool // Options written as name=value
+	knownSubcommands  map[string]bool
 }
 
 // NewGlobalRegistry creates a new registry with all known options and subcommands
 func NewGlobalRegistry() *OptionRegistry {
 	return &OptionRegistry{
 		knownOptions: map[string]bool{
-			"clean":   true,
-			"verbose": true,
-			"debug":   true,
-			"force":   true,
-			"watch":   true,
+			"clean":    true,
+			"verbose":  true,
+			"debug":    true,
+			"force":    true,
+			"watch":    true,
+			"no-cache": true,
+		},
+		knownValueOptions: map[string]bool{
+			"profile": true,
 		},
 		knownSubcommands: map[string]bool{
 			"convert": true,
@@ -29,6 +36,9 @@ func NewGlobalRegistry() *OptionRegistry {
 
 // IsOption checks if the given argument is a known option
 func (r *OptionRegistry) IsOption(arg string) bool {
+	if name, value, ok := strings.Cut(arg, "="); ok && value != "" {
+		return r.knownValueOptions[name]
+	}
 	return r.knownOptions[arg]
 }
 
@@ -39,10 +49,13 @@ func (r *OptionRegistry) IsSubcommand(arg string) bool {
 
 // GetKnownOptions returns a list of all known option names
 func (r *OptionRegistry) GetKnownOptions() []string {
-	options := make([]string, 0, len(r.knownOptions))
+	options := make([]string, 0, len(r.knownOptions)+len(r.knownValueOptions))
 	for option := range r.knownOptions {
 		options = append(options, option)
 	}
+	for option := range r.knownValueOptions {
+		options = append(options, option+"=")
+	}
 	return options
 }
 
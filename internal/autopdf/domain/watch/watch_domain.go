@@ -42,6 +42,7 @@ type WatchConfiguration struct {
 	DebounceInterval  time.Duration
 	ExclusionPatterns []string
 	InclusionPatterns []string
+	PartialDirs       []string // Watched with their subdirectories, as partials are included by name
 }
 
 // FilePatternMatcher defines the contract for pattern matching
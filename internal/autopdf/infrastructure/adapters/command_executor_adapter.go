@@ -36,17 +36,13 @@ func (e *OSCommandExecutor) Execute(ctx context.Context, cmd application.Command
 	cmdCtx, cancel := context.WithTimeout(ctx, cmd.Timeout)
 	defer cancel()
 
-	// Parse the command (handle shell execution for complex commands)
-	var execCmd *exec.Cmd
-	if len(cmd.Args) > 0 && cmd.Args[0] == "sh" {
-		// Shell command execution
-		execCmd = exec.CommandContext(cmdCtx, cmd.Executable, cmd.Args...)
-	} else {
-		// Direct command execution
-		execCmd = exec.CommandContext(cmdCtx, cmd.Executable, cmd.Args...)
-	}
+	// Direct argv execution: arguments are never interpreted by a shell
+	execCmd := exec.CommandContext(cmdCtx, cmd.Executable, cmd.Args...)
 
 	execCmd.Dir = cmd.Dir
+	if cmd.Security.KillProcessGroup {
+		killProcessGroupOnCancel(execCmd)
+	}
 
 	// Set environment if provided
 	if len(cmd.Env) > 0 {
@@ -49,6 +49,20 @@ func (f *OSFileSystem) IsNotExist(err error) bool {
 	return os.IsNotExist(err)
 }
 
+// CreateExclusive creates an empty file, failing when the path exists
+func (f *OSFileSystem) CreateExclusive(ctx context.Context, path string, perm fs.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// IsExist checks if an error indicates a file already exists
+func (f *OSFileSystem) IsExist(err error) bool {
+	return os.IsExist(err)
+}
+
 // MkdirAll creates a directory and all parent directories
 func (f *OSFileSystem) MkdirAll(ctx context.Context, path string, perm fs.FileMode) error {
 	return os.MkdirAll(path, perm)
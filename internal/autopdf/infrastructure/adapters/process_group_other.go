@@ -0,0 +1,11 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !unix
+
+package adapters
+
+import "os/exec"
+
+// killProcessGroupOnCancel is a no-op without process groups; cancellation kills the command only
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,26 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unix
+
+package adapters
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// processGroupWaitDelay bounds how long Run waits for output pipes held open
+// by processes that outlived the kill
+const processGroupWaitDelay = 5 * time.Second
+
+// killProcessGroupOnCancel starts the command in its own process group and kills
+// the whole group on cancellation, so engines started by latexmk or a shell escape die too
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = processGroupWaitDelay
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	application "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+)
+
+// shellEscapeFlags are the engine flags for each shell escape mode
+// Flags take precedence over texmf.cnf, so a local configuration cannot re-enable \write18
+var shellEscapeFlags = map[string]string{
+	application.ShellEscapeNone:       "-no-shell-escape",
+	application.ShellEscapeRestricted: "-shell-restricted",
+}
+
+// shellEscapeEnv are the kpathsea values for each shell escape mode; they also
+// reach engines started indirectly, e.g. by latexmk
+var shellEscapeEnv = map[string]string{
+	application.ShellEscapeNone:       "f",
+	application.ShellEscapeRestricted: "p",
+}
+
+// shellEscapeExecutables accept -no-shell-escape and -shell-restricted
+var shellEscapeExecutables = map[string]bool{
+	"latex":    true,
+	"pdflatex": true,
+	"xelatex":  true,
+	"lualatex": true,
+	"latexmk":  true,
+}
+
+// cleanEnvKeys are the variables kept from the caller's environment under CleanEnv
+var cleanEnvKeys = map[string]bool{
+	"PATH":              true,
+	"LANG":              true,
+	"LC_ALL":            true,
+	"LC_CTYPE":          true,
+	"TZ":                true,
+	"SOURCE_DATE_EPOCH": true,
+	"SYSTEMROOT":        true, // Needed by any process on Windows
+}
+
+// TeX search paths kept under CleanEnv, e.g. TEXMFHOME, TEXINPUTS, BIBINPUTS, TFMFONTS and TECTONIC_CACHE_DIR
+var (
+	cleanEnvPrefixes = []string{"TEXMF", "TECTONIC", "TYPST"}
+	cleanEnvSuffixes = []string{"INPUTS", "FONTS"}
+)
+
+// SandboxedCommandExecutor is a CommandExecutor decorator enforcing a SecurityPolicy
+// Commands carrying a policy run under it; the others run under the executor's default policy
+//
+// The policy is applied by rewriting the command before it reaches the wrapped executor:
+// shell escape flags for TeX engines, kpathsea variables and a filtered environment,
+// and resource limits set by /bin/sh's ulimit before it execs the real program
+type SandboxedCommandExecutor struct {
+	inner  application.CommandExecutor
+	policy application.SecurityPolicy
+}
+
+// NewSandboxedCommandExecutor wraps inner; policy applies to commands without their own
+func NewSandboxedCommandExecutor(inner application.CommandExecutor, policy application.SecurityPolicy) (*SandboxedCommandExecutor, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &SandboxedCommandExecutor{
+		inner:  inner,
+		policy: policy,
+	}, nil
+}
+
+// Execute implements CommandExecutor interface
+func (e *SandboxedCommandExecutor) Execute(ctx context.Context, cmd application.Command) (application.CommandResult, error) {
+	policy := cmd.Security
+	if !policy.Enabled() {
+		policy = e.policy
+	}
+	if err := policy.Validate(); err != nil {
+		return application.CommandResult{ExitCode: -1}, err
+	}
+	return e.inner.Execute(ctx, Sandbox(cmd, policy))
+}
+
+// Sandbox rewrites cmd so that running it enforces the policy
+func Sandbox(cmd application.Command, policy application.SecurityPolicy) application.Command {
+	cmd.Security = policy
+	if !policy.Enabled() {
+		return cmd
+	}
+
+	if flag, ok := shellEscapeFlags[policy.ShellEscape]; ok && shellEscapeExecutables[executableName(cmd.Executable)] {
+		cmd.Args = append([]string{flag}, cmd.Args...)
+	}
+
+	env := cmd.Env
+	if len(env) == 0 {
+		env = os.Environ()
+	}
+	if policy.CleanEnv {
+		env = cleanEnv(env, cmd.Dir)
+	} else {
+		env = append([]string(nil), env...)
+	}
+	if value, ok := shellEscapeEnv[policy.ShellEscape]; ok {
+		env = append(env, "shell_escape="+value)
+	}
+	if policy.ParanoidFiles {
+		env = append(env, "openin_any=p", "openout_any=p")
+	}
+	cmd.Env = env
+
+	if policy.HasLimits() && runtime.GOOS != "windows" {
+		cmd.Args = append([]string{"-c", ulimitScript(policy), cmd.Executable}, cmd.Args...)
+		cmd.Executable = "/bin/sh"
+	}
+	return cmd
+}
+
+// ulimitScript sets the limits and replaces the shell with the command, which is
+// passed as positional parameters: only the numbers below are ever parsed by the shell
+func ulimitScript(policy application.SecurityPolicy) string {
+	var limits []string
+	if policy.CPUTime > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -t %d", int64(policy.CPUTime.Seconds())))
+	}
+	if policy.MemoryBytes > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -v %d", max(policy.MemoryBytes/1024, 1)))
+	}
+	if policy.FileSizeBytes > 0 {
+		// POSIX sh counts file sizes in 512-byte blocks
+		limits = append(limits, fmt.Sprintf("ulimit -f %d", max(policy.FileSizeBytes/512, 1)))
+	}
+	return strings.Join(append(limits, `exec "$0" "$@"`), " && ")
+}
+
+// cleanEnv keeps the locale and TeX search paths from env and points HOME at dir,
+// so no credentials or tokens of the calling process reach the engine
+func cleanEnv(env []string, dir string) []string {
+	home := dir
+	if home == "" {
+		home = os.TempDir()
+	}
+	clean := []string{"HOME=" + home, "TMPDIR=" + os.TempDir()}
+	for _, entry := range env {
+		key, _, ok := strings.Cut(entry, "=")
+		if ok && keepEnvKey(key) {
+			clean = append(clean, entry)
+		}
+	}
+	return clean
+}
+
+// keepEnvKey reports whether a variable survives CleanEnv
+func keepEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	if cleanEnvKeys[upper] {
+		return true
+	}
+	for _, prefix := range cleanEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	for _, suffix := range cleanEnvSuffixes {
+		if strings.HasSuffix(upper, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// executableName strips the directory and, on Windows, the extension
+func executableName(executable string) string {
+	name := filepath.Base(executable)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// NewSecurityPolicyFromConfig creates the policy described by a config's security section
+func NewSecurityPolicyFromConfig(cfg config.Security) (application.SecurityPolicy, error) {
+	if !cfg.Sandbox {
+		return application.SecurityPolicy{}, nil
+	}
+
+	policy := application.NewSandboxPolicy()
+	if cfg.ShellEscape != "" {
+		policy.ShellEscape = cfg.ShellEscape
+	}
+	cpuTime, err := cfg.CPUTimeDuration()
+	if err != nil {
+		return application.SecurityPolicy{}, err
+	}
+	if cpuTime > 0 {
+		policy.CPUTime = cpuTime
+	}
+	if cfg.MemoryMB > 0 {
+		policy.MemoryBytes = int64(cfg.MemoryMB) << 20
+	}
+	if cfg.FileSizeMB > 0 {
+		policy.FileSizeBytes = int64(cfg.FileSizeMB) << 20
+	}
+	policy.CleanEnv = !cfg.InheritEnv
+
+	return policy, policy.Validate()
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package adapters
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	application "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingExecutor captures the command it would have run
+type recordingExecutor struct {
+	cmd application.Command
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, cmd application.Command) (application.CommandResult, error) {
+	e.cmd = cmd
+	return application.NewCommandResult("", "", 0, time.Millisecond), nil
+}
+
+func sandboxed(t *testing.T, policy application.SecurityPolicy, cmd application.Command) application.Command {
+	t.Helper()
+	inner := &recordingExecutor{}
+	executor, err := NewSandboxedCommandExecutor(inner, policy)
+	require.NoError(t, err)
+	_, err = executor.Execute(context.Background(), cmd)
+	require.NoError(t, err)
+	return inner.cmd
+}
+
+func TestSandboxedCommandExecutor_ShellEscape(t *testing.T) {
+	policy := application.SecurityPolicy{ShellEscape: application.ShellEscapeNone}
+
+	engine := sandboxed(t, policy, application.NewCommand("pdflatex", []string{"-interaction=nonstopmode", "doc.tex"}, "/work"))
+	assert.Equal(t, []string{"-no-shell-escape", "-interaction=nonstopmode", "doc.tex"}, engine.Args)
+	assert.Contains(t, engine.Env, "shell_escape=f")
+
+	tool := sandboxed(t, policy, application.NewCommand("bibtex", []string{"doc"}, "/work"))
+	assert.Equal(t, []string{"doc"}, tool.Args, "tools do not take engine flags")
+	assert.Contains(t, tool.Env, "shell_escape=f")
+
+	restricted := sandboxed(t, application.SecurityPolicy{ShellEscape: application.ShellEscapeRestricted},
+		application.NewCommand("/usr/bin/lualatex", []string{"doc.tex"}, "/work"))
+	assert.Equal(t, "-shell-restricted", restricted.Args[0])
+	assert.Contains(t, restricted.Env, "shell_escape=p")
+}
+
+func TestSandboxedCommandExecutor_CleanEnv(t *testing.T) {
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "hunter2")
+	t.Setenv("TEXMFHOME", "/opt/texmf")
+
+	cmd := sandboxed(t, application.SecurityPolicy{CleanEnv: true, ParanoidFiles: true},
+		application.NewCommand("xelatex", []string{"doc.tex"}, "/work").
+			WithEnv([]string{"PATH=/usr/bin", "GITHUB_TOKEN=secret", "BIBINPUTS=/templates:"}))
+
+	env := strings.Join(cmd.Env, "\n")
+	assert.NotContains(t, env, "hunter2")
+	assert.NotContains(t, env, "GITHUB_TOKEN")
+	assert.NotContains(t, env, "TEXMFHOME", "an explicit environment replaces the inherited one")
+	assert.Contains(t, cmd.Env, "PATH=/usr/bin")
+	assert.Contains(t, cmd.Env, "BIBINPUTS=/templates:")
+	assert.Contains(t, cmd.Env, "HOME=/work")
+	assert.Contains(t, cmd.Env, "openin_any=p")
+	assert.Contains(t, cmd.Env, "openout_any=p")
+
+	inherited := sandboxed(t, application.SecurityPolicy{CleanEnv: true}, application.NewCommand("xelatex", nil, "/work"))
+	assert.Contains(t, inherited.Env, "TEXMFHOME=/opt/texmf")
+	assert.NotContains(t, strings.Join(inherited.Env, "\n"), "hunter2")
+}
+
+func TestSandboxedCommandExecutor_CommandPolicyTakesPrecedence(t *testing.T) {
+	inner := &recordingExecutor{}
+	executor, err := NewSandboxedCommandExecutor(inner, application.NewSandboxPolicy())
+	require.NoError(t, err)
+
+	own := application.SecurityPolicy{ShellEscape: application.ShellEscapeRestricted}
+	_, err = executor.Execute(context.Background(), application.NewCommand("pdflatex", nil, "").WithSecurity(own))
+	require.NoError(t, err)
+	assert.Equal(t, own, inner.cmd.Security)
+
+	_, err = executor.Execute(context.Background(), application.NewCommand("pdflatex", nil, ""))
+	require.NoError(t, err)
+	assert.Equal(t, application.NewSandboxPolicy(), inner.cmd.Security)
+}
+
+func TestSandboxedCommandExecutor_NoPolicy(t *testing.T) {
+	cmd := application.NewCommand("pdflatex", []string{"doc.tex"}, "/work")
+	assert.Equal(t, cmd, sandboxed(t, application.SecurityPolicy{}, cmd))
+}
+
+func TestSandboxedCommandExecutor_ResourceLimits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("limits are set with ulimit")
+	}
+
+	policy := application.SecurityPolicy{
+		CPUTime:       7 * time.Second,
+		MemoryBytes:   512 << 20,
+		FileSizeBytes: 1 << 20,
+	}
+	executor, err := NewSandboxedCommandExecutor(NewOSCommandExecutor(), policy)
+	require.NoError(t, err)
+
+	result, err := executor.Execute(context.Background(),
+		application.NewCommand("sh", []string{"-c", "ulimit -t; ulimit -v; ulimit -f"}, t.TempDir()))
+	require.NoError(t, err, result.Stderr)
+	assert.Equal(t, []string{"7", "524288", "2048"}, strings.Fields(result.Stdout))
+
+	// Arguments reach the program verbatim, never through the limiting shell
+	result, err = executor.Execute(context.Background(),
+		application.NewCommand("printf", []string{"%s|", "$(echo pwned)", `"; exit 1`}, t.TempDir()))
+	require.NoError(t, err, result.Stderr)
+	assert.Equal(t, `$(echo pwned)|"; exit 1|`, result.Stdout)
+}
+
+func TestOSCommandExecutor_KillsProcessGroupOnCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are POSIX")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The background sleep holds the output pipe open; only killing the group ends it promptly
+	cmd := application.NewCommand("sh", []string{"-c", "sleep 30 & sleep 30"}, t.TempDir()).
+		WithSecurity(application.SecurityPolicy{KillProcessGroup: true})
+
+	start := time.Now()
+	_, err := NewOSCommandExecutor().Execute(ctx, cmd)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestNewSandboxedCommandExecutor_InvalidPolicy(t *testing.T) {
+	_, err := NewSandboxedCommandExecutor(NewOSCommandExecutor(), application.SecurityPolicy{ShellEscape: "enabled"})
+	assert.Error(t, err)
+
+	_, err = NewSandboxedCommandExecutor(NewOSCommandExecutor(), application.SecurityPolicy{MemoryBytes: -1})
+	assert.Error(t, err)
+}
+
+func TestNewSecurityPolicyFromConfig(t *testing.T) {
+	policy, err := NewSecurityPolicyFromConfig(config.Security{})
+	require.NoError(t, err)
+	assert.False(t, policy.Enabled(), "no sandbox unless configured")
+
+	policy, err = NewSecurityPolicyFromConfig(config.Security{Sandbox: true, CPUTime: "30s", MemoryMB: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, application.ShellEscapeNone, policy.ShellEscape)
+	assert.True(t, policy.ParanoidFiles)
+	assert.True(t, policy.CleanEnv)
+	assert.True(t, policy.KillProcessGroup)
+	assert.Equal(t, 30*time.Second, policy.CPUTime)
+	assert.Equal(t, int64(1<<30), policy.MemoryBytes)
+	assert.Equal(t, application.NewSandboxPolicy().FileSizeBytes, policy.FileSizeBytes)
+
+	_, err = NewSecurityPolicyFromConfig(config.Security{Sandbox: true, CPUTime: "soon"})
+	assert.Error(t, err)
+
+	_, err = NewSecurityPolicyFromConfig(config.Security{Sandbox: true, ShellEscape: "yes"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+)
+
+// releaseMarker is written into a workspace when its build finishes
+// Only released workspaces are ever pruned, so builds still running are never touched
+const releaseMarker = ".autopdf-released"
+
+// Release marker contents
+const (
+	releasedSucceeded = "succeeded"
+	releasedFailed    = "failed"
+)
+
+var unsafeWorkspaceChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DefaultWorkspaceRoot returns the directory holding build workspaces when none is configured
+func DefaultWorkspaceRoot() string {
+	return filepath.Join(os.TempDir(), "autopdf", "workspaces")
+}
+
+// OSWorkspaceManager implements WorkspaceManager with one directory per build under root
+// Directories are created with os.MkdirTemp, so names never collide between processes
+type OSWorkspaceManager struct {
+	root   string
+	policy ports.RetentionPolicy
+}
+
+// NewOSWorkspaceManager creates a workspace manager; an empty root uses DefaultWorkspaceRoot
+func NewOSWorkspaceManager(root string, policy ports.RetentionPolicy) (*OSWorkspaceManager, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if root == "" {
+		root = DefaultWorkspaceRoot()
+	}
+	return &OSWorkspaceManager{
+		root:   root,
+		policy: policy,
+	}, nil
+}
+
+// Root returns the directory holding the workspaces
+func (m *OSWorkspaceManager) Root() string {
+	return m.root
+}
+
+// Create allocates a new, empty workspace for the job
+func (m *OSWorkspaceManager) Create(ctx context.Context, jobName string) (ports.Workspace, error) {
+	if err := os.MkdirAll(m.root, 0755); err != nil {
+		return ports.Workspace{}, fmt.Errorf("failed to create workspace root: %w", err)
+	}
+
+	prefix := unsafeWorkspaceChars.ReplaceAllString(jobName, "_")
+	if prefix == "" {
+		prefix = "build"
+	}
+	path, err := os.MkdirTemp(m.root, prefix+"-*")
+	if err != nil {
+		return ports.Workspace{}, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return ports.Workspace{
+		ID:        filepath.Base(path),
+		Path:      path,
+		JobName:   jobName,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Stage symlinks every entry of sourceDir into the workspace
+// It is the fallback of builds wired without an AssetStager, which stages only referenced files.
+// Hidden entries and files named after the job are skipped: the engine writes
+// <job>.tex, <job>.aux, ... in the workspace and must not write through a link
+func (m *OSWorkspaceManager) Stage(ctx context.Context, ws ports.Workspace, sourceDir string) error {
+	if sourceDir == "" {
+		return nil
+	}
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve source directory: %w", err)
+	}
+	entries, err := os.ReadDir(absSource)
+	if err != nil {
+		return fmt.Errorf("failed to read source directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if strings.TrimSuffix(name, filepath.Ext(name)) == ws.JobName {
+			continue
+		}
+		link := filepath.Join(ws.Path, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(absSource, name), link); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Release marks the workspace as finished and applies the retention policy
+func (m *OSWorkspaceManager) Release(ctx context.Context, ws ports.Workspace, failed bool) error {
+	status := releasedSucceeded
+	if failed {
+		status = releasedFailed
+	}
+
+	switch {
+	case m.policy.Mode == ports.RetentionDelete,
+		m.policy.Mode == ports.RetentionKeepFailed && !failed:
+		if err := os.RemoveAll(ws.Path); err != nil {
+			return fmt.Errorf("failed to remove workspace: %w", err)
+		}
+	default:
+		if err := os.WriteFile(filepath.Join(ws.Path, releaseMarker), []byte(status), 0644); err != nil {
+			return fmt.Errorf("failed to mark workspace as released: %w", err)
+		}
+	}
+
+	return m.prune()
+}
+
+// releasedWorkspace is a workspace found on disk with a release marker
+type releasedWorkspace struct {
+	path       string
+	releasedAt time.Time
+}
+
+// prune removes the released workspaces the policy no longer keeps
+func (m *OSWorkspaceManager) prune() error {
+	released, err := m.released()
+	if err != nil {
+		return err
+	}
+
+	// Newest first, so everything past KeepLast is removed
+	sort.Slice(released, func(i, j int) bool {
+		return released[i].releasedAt.After(released[j].releasedAt)
+	})
+
+	now := time.Now()
+	for i, ws := range released {
+		expired := m.policy.TTL > 0 && now.Sub(ws.releasedAt) > m.policy.TTL
+		overflow := m.policy.KeepLast > 0 && i >= m.policy.KeepLast
+		if !expired && !overflow {
+			continue
+		}
+		if err := os.RemoveAll(ws.path); err != nil {
+			return fmt.Errorf("failed to prune workspace %s: %w", ws.path, err)
+		}
+	}
+	return nil
+}
+
+// released lists the workspaces under root that carry a release marker
+func (m *OSWorkspaceManager) released() ([]releasedWorkspace, error) {
+	entries, err := os.ReadDir(m.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	var released []releasedWorkspace
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(m.root, entry.Name())
+		info, err := os.Stat(filepath.Join(path, releaseMarker))
+		if err != nil {
+			continue
+		}
+		released = append(released, releasedWorkspace{path: path, releasedAt: info.ModTime()})
+	}
+	return released, nil
+}
+
+// NewWorkspaceManagerFromConfig creates the workspace manager described by a config's workspace section
+func NewWorkspaceManagerFromConfig(cfg config.Workspace) (*OSWorkspaceManager, error) {
+	ttl, err := cfg.TTLDuration()
+	if err != nil {
+		return nil, err
+	}
+	return NewOSWorkspaceManager(cfg.Root, ports.NewRetentionPolicy(cfg.Retention, cfg.KeepLast, ttl))
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package adapters
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWorkspaceManager(t *testing.T, policy ports.RetentionPolicy) *OSWorkspaceManager {
+	t.Helper()
+	manager, err := NewOSWorkspaceManager(t.TempDir(), policy)
+	require.NoError(t, err)
+	return manager
+}
+
+// releaseBuild creates a workspace, releases it and backdates its release by age
+func releaseBuild(t *testing.T, manager *OSWorkspaceManager, failed bool, age time.Duration) ports.Workspace {
+	t.Helper()
+	ctx := context.Background()
+	ws, err := manager.Create(ctx, "invoice")
+	require.NoError(t, err)
+	require.NoError(t, manager.Release(ctx, ws, failed))
+
+	marker := filepath.Join(ws.Path, releaseMarker)
+	if _, err := os.Stat(marker); err == nil {
+		released := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(marker, released, released))
+	}
+	return ws
+}
+
+func TestOSWorkspaceManager_Create(t *testing.T) {
+	manager := newTestWorkspaceManager(t, ports.NewRetentionPolicy("", 0, 0))
+
+	first, err := manager.Create(context.Background(), "invoice")
+	require.NoError(t, err)
+	second, err := manager.Create(context.Background(), "invoice")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.Path, second.Path)
+	assert.Equal(t, manager.Root(), filepath.Dir(first.Path))
+	assert.DirExists(t, first.Path)
+	assert.Equal(t, "invoice", first.JobName)
+}
+
+func TestOSWorkspaceManager_Stage(t *testing.T) {
+	ctx := context.Background()
+	source := t.TempDir()
+	for _, name := range []string{"invoice.tex", "invoice.aux", "logo.png", ".git"} {
+		require.NoError(t, os.WriteFile(filepath.Join(source, name), []byte(name), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(source, "fonts"), 0755))
+
+	manager := newTestWorkspaceManager(t, ports.NewRetentionPolicy("", 0, 0))
+	ws, err := manager.Create(ctx, "invoice")
+	require.NoError(t, err)
+	require.NoError(t, manager.Stage(ctx, ws, source))
+
+	target, err := os.Readlink(filepath.Join(ws.Path, "logo.png"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(source, "logo.png"), target)
+	info, err := os.Stat(filepath.Join(ws.Path, "fonts"))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	// The job's own files and hidden entries are never linked
+	for _, name := range []string{"invoice.tex", "invoice.aux", ".git"} {
+		_, err := os.Lstat(filepath.Join(ws.Path, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+}
+
+func TestOSWorkspaceManager_Retention(t *testing.T) {
+	t.Run("delete removes every workspace", func(t *testing.T) {
+		manager := newTestWorkspaceManager(t, ports.NewRetentionPolicy("delete", 0, 0))
+
+		succeeded := releaseBuild(t, manager, false, 0)
+		failed := releaseBuild(t, manager, true, 0)
+
+		assert.NoDirExists(t, succeeded.Path)
+		assert.NoDirExists(t, failed.Path)
+	})
+
+	t.Run("keep_last keeps the most recent releases", func(t *testing.T) {
+		manager := newTestWorkspaceManager(t, ports.NewRetentionPolicy("keep_last", 2, 0))
+
+		oldest := releaseBuild(t, manager, false, 3*time.Minute)
+		middle := releaseBuild(t, manager, true, 2*time.Minute)
+		newest := releaseBuild(t, manager, false, time.Minute)
+		require.NoError(t, manager.prune())
+
+		assert.NoDirExists(t, oldest.Path)
+		assert.DirExists(t, middle.Path)
+		assert.DirExists(t, newest.Path)
+	})
+
+	t.Run("keep_failed removes successful builds only", func(t *testing.T) {
+		manager := newTestWorkspaceManager(t, ports.NewRetentionPolicy("keep_failed", 0, 0))
+
+		succeeded := releaseBuild(t, manager, false, 0)
+		failed := releaseBuild(t, manager, true, 0)
+
+		assert.NoDirExists(t, succeeded.Path)
+		assert.DirExists(t, failed.Path)
+	})
+
+	t.Run("ttl removes workspaces released too long ago", func(t *testing.T) {
+		manager := newTestWorkspaceManager(t, ports.NewRetentionPolicy("ttl", 0, time.Hour))
+
+		expired := releaseBuild(t, manager, false, 2*time.Hour)
+		fresh := releaseBuild(t, manager, false, time.Minute)
+		require.NoError(t, manager.prune())
+
+		assert.NoDirExists(t, expired.Path)
+		assert.DirExists(t, fresh.Path)
+	})
+
+	t.Run("builds still running are never pruned", func(t *testing.T) {
+		manager := newTestWorkspaceManager(t, ports.NewRetentionPolicy("keep_last", 1, 0))
+
+		running, err := manager.Create(context.Background(), "invoice")
+		require.NoError(t, err)
+		releaseBuild(t, manager, false, time.Minute)
+		releaseBuild(t, manager, false, 0)
+
+		assert.DirExists(t, running.Path)
+	})
+}
+
+func TestNewOSWorkspaceManager_InvalidPolicy(t *testing.T) {
+	for _, policy := range []ports.RetentionPolicy{
+		ports.NewRetentionPolicy("forever", 0, 0),
+		ports.NewRetentionPolicy("keep_last", 0, 0),
+		ports.NewRetentionPolicy("ttl", 0, 0),
+	} {
+		_, err := NewOSWorkspaceManager(t.TempDir(), policy)
+		assert.Error(t, err, string(policy.Mode))
+	}
+}
@@ -54,15 +54,23 @@ func (epsa *ExternalPDFServiceAdapter) Generate(ctx context.Context, req generat
 
 	// Create internal application adapter with logger
 	internalAdapter := adapters.NewInternalApplicationAdapterWithLogger(cfg, epsa.logger)
+	if req.Options.Rendered {
+		internalAdapter = internalAdapter.WithRenderedTemplate()
+	}
 
 	// Extract working directory from request options
 	workingDir := req.Options.WorkingDir
 
 	// Generate PDF using the internal adapter with working directory
-	pdfBytes, paths, err := internalAdapter.GeneratePDF(cfg, config.Template(req.TemplatePath), debugEnabled, workingDir)
+	output, err := internalAdapter.Generate(cfg, config.Template(req.TemplatePath), debugEnabled, workingDir)
+	diagnostics := toDomainDiagnostics(output.Diagnostics)
 	if err != nil {
+		if len(diagnostics) > 0 {
+			err = &generation.CompilationFailedError{Diagnostics: diagnostics, Err: err}
+		}
 		return generation.PDFGenerationResult{
-			Success: false,
+			Diagnostics: diagnostics,
+			Success:     false,
 			Error: domain.PDFGenerationError{
 				Code:    domain.ErrCodePDFGenerationFailed,
 				Message: "PDF generation failed",
@@ -73,17 +81,18 @@ func (epsa *ExternalPDFServiceAdapter) Generate(ctx context.Context, req generat
 	}
 
 	// Convert result to domain result
-	imagePaths := make([]string, 0, len(paths))
-	for _, path := range paths {
+	imagePaths := make([]string, 0, len(output.ImagePaths))
+	for _, path := range output.ImagePaths {
 		imagePaths = append(imagePaths, path)
 	}
 
 	return generation.PDFGenerationResult{
-		PDFPath:    req.OutputPath,
-		ImagePaths: imagePaths,
-		Success:    true,
+		PDFPath:     output.OutputPath, // The output path rendered and suffixed as the build resolved it
+		ImagePaths:  imagePaths,
+		Diagnostics: diagnostics,
+		Success:     true,
 		Metadata: generation.PDFMetadata{
-			FileSize:    int64(len(pdfBytes)),
+			FileSize:    int64(len(output.PDFBytes)),
 			PageCount:   1, // Would need proper PDF parsing
 			GeneratedAt: time.Now(),
 			Engine:      req.Engine,
@@ -92,52 +101,10 @@ func (epsa *ExternalPDFServiceAdapter) Generate(ctx context.Context, req generat
 	}, nil
 }
 
-// GenerateWithWorkingDir generates a PDF using the internal application layer with custom working directory
+// GenerateWithWorkingDir generates a PDF as Generate does, in the given working directory
 func (epsa *ExternalPDFServiceAdapter) GenerateWithWorkingDir(ctx context.Context, req generation.PDFGenerationRequest, workingDir string) (generation.PDFGenerationResult, error) {
-	// Merge debug: request overrides config default
-	debugEnabled := epsa.debugEnabled
-	if req.Options.Debug.Enabled {
-		debugEnabled = true
-	}
-
-	// Convert domain request to config
-	cfg := epsa.createConfigFromRequest(req)
-
-	// Create internal application adapter with logger
-	internalAdapter := adapters.NewInternalApplicationAdapterWithLogger(cfg, epsa.logger)
-
-	// Generate PDF using the internal adapter with custom working directory
-	pdfBytes, paths, err := internalAdapter.GeneratePDFWithWorkingDir(cfg, config.Template(req.TemplatePath), debugEnabled, workingDir)
-	if err != nil {
-		return generation.PDFGenerationResult{
-			Success: false,
-			Error: domain.PDFGenerationError{
-				Code:    domain.ErrCodePDFGenerationFailed,
-				Message: "PDF generation failed",
-				Details: api.NewErrorDetails(api.ErrorCategoryGeneration, api.ErrorSeverityHigh).
-					WithError(err),
-			},
-		}, err
-	}
-
-	// Convert result to domain result
-	imagePaths := make([]string, 0, len(paths))
-	for _, path := range paths {
-		imagePaths = append(imagePaths, path)
-	}
-
-	return generation.PDFGenerationResult{
-		PDFPath:    req.OutputPath,
-		ImagePaths: imagePaths,
-		Success:    true,
-		Metadata: generation.PDFMetadata{
-			FileSize:    int64(len(pdfBytes)),
-			PageCount:   1, // Would need proper PDF parsing
-			GeneratedAt: time.Now(),
-			Engine:      req.Engine,
-			Template:    req.TemplatePath,
-		},
-	}, nil
+	req.Options.WorkingDir = workingDir
+	return epsa.Generate(ctx, req)
 }
 
 // ValidateRequest validates a PDF generation request
@@ -158,6 +125,16 @@ func (epsa *ExternalPDFServiceAdapter) ValidateRequest(req generation.PDFGenerat
 		}
 	}
 
+	if err := autopdfports.ValidateEngine(req.Engine); err != nil {
+		return domain.PDFGenerationError{
+			Code:    domain.ErrCodeEngineNotFound,
+			Message: fmt.Sprintf(api.ErrEngineNotSupported, req.Engine),
+			Details: api.NewErrorDetails(api.ErrorCategoryGeneration, api.ErrorSeverityHigh).
+				WithEngine(req.Engine).
+				WithValidation("supported_engines", autopdfports.SupportedEngines(), req.Engine),
+		}
+	}
+
 	if req.OutputPath == "" {
 		return domain.PDFGenerationError{
 			Code:    domain.ErrCodeOutputPathInvalid,
@@ -197,12 +174,7 @@ func (epsa *ExternalPDFServiceAdapter) ValidateRequest(req generation.PDFGenerat
 
 // GetSupportedEngines returns supported LaTeX engines
 func (epsa *ExternalPDFServiceAdapter) GetSupportedEngines() []string {
-	return []string{
-		"pdflatex",
-		"xelatex",
-		"lualatex",
-		"latex",
-	}
+	return autopdfports.SupportedEngines()
 }
 
 // GetSupportedFormats returns supported output formats
@@ -229,7 +201,22 @@ func (epsa *ExternalPDFServiceAdapter) createConfigFromRequest(req generation.PD
 		},
 		Variables:  *config.NewVariables(),
 		Passes:     req.Options.Passes,
+		AutoPasses: req.Options.AutoPasses,
 		UseLatexmk: req.Options.UseLatexmk,
+
+		OutputCollision: req.Options.OutputCollision,
+	}
+	if epsa.config != nil {
+		cfg.Workspace = epsa.config.Workspace // Retention is a server-wide setting
+		cfg.Assets = epsa.config.Assets
+		cfg.Security = epsa.config.Security // Untrusted requests cannot relax the sandbox
+		cfg.Cache = epsa.config.Cache
+		cfg.Tectonic = epsa.config.Tectonic
+		cfg.Typst = epsa.config.Typst
+		cfg.Partials = epsa.config.Partials
+	}
+	if len(req.Options.Assets) > 0 {
+		cfg.Assets.Extra = append(append([]string(nil), cfg.Assets.Extra...), req.Options.Assets...)
 	}
 
 	// DEBUG: Log extracted config values (if logger is available)
@@ -254,3 +241,22 @@ func (epsa *ExternalPDFServiceAdapter) createConfigFromRequest(req generation.PD
 
 	return cfg
 }
+
+// toDomainDiagnostics converts compiler diagnostics to API domain diagnostics
+func toDomainDiagnostics(diagnostics []autopdfports.Diagnostic) []generation.Diagnostic {
+	if len(diagnostics) == 0 {
+		return nil
+	}
+	result := make([]generation.Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		result = append(result, generation.Diagnostic{
+			Kind:     string(d.Kind),
+			Severity: string(d.Severity),
+			File:     d.File,
+			Line:     d.Line,
+			Message:  d.Message,
+			Context:  d.Context,
+		})
+	}
+	return result
+}
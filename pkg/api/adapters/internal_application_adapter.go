@@ -9,10 +9,13 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/assets"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/cache"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/cleaner"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/converter"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/latex"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/template"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/typst"
 	autopdfports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	documentService "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/services/document"
 	infraadapters "github.com/BuddhiLW/AutoPDF/internal/autopdf/infrastructure/adapters"
@@ -24,8 +27,9 @@ import (
 // InternalApplicationAdapter bridges the API layer with the internal application layer
 // This adapter follows the Adapter pattern from GoF and maintains separation of concerns
 type InternalApplicationAdapter struct {
-	config *config.Config
-	logger autopdfports.Logger // Optional logger for transparency
+	config   *config.Config
+	logger   autopdfports.Logger // Optional logger for transparency
+	rendered bool                // The template is already rendered and compiled as it is
 }
 
 // NewInternalApplicationAdapter creates a new adapter
@@ -44,29 +48,78 @@ func NewInternalApplicationAdapterWithLogger(cfg *config.Config, logger autopdfp
 	}
 }
 
+// WithRenderedTemplate compiles templates as they are, for callers that rendered them already
+func (iaa *InternalApplicationAdapter) WithRenderedTemplate() *InternalApplicationAdapter {
+	iaa.rendered = true
+	return iaa
+}
+
+// GenerationOutput holds everything produced by a single generation
+type GenerationOutput struct {
+	PDFBytes    []byte
+	ImagePaths  map[string]string         // Converted images that outlive the call; empty when they were converted next to a defaulted output
+	Images      map[string][]byte         // Contents of the converted images, keyed like ImagePaths
+	Diagnostics []autopdfports.Diagnostic // Parsed engine log; also set when generation fails
+	Workspace   string                    // Directory the engine ran in
+	Cache       autopdfports.CacheStatus  // hit, miss or bypassed; empty without a compile cache
+	OutputPath  string                    // Where the PDF was written, its pattern rendered; empty when none was requested
+}
+
 // GeneratePDF generates a PDF using the internal application layer
 // This method maintains the same signature as the original GeneratePDF function
-// workingDir is optional - if empty, uses default behavior
+// workingDir is optional - if empty, the template's directory provides the assets
 func (iaa *InternalApplicationAdapter) GeneratePDF(cfg *config.Config, template config.Template, debugEnabled bool, workingDir string) ([]byte, map[string]string, error) {
+	output, err := iaa.Generate(cfg, template, debugEnabled, workingDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return output.PDFBytes, output.ImagePaths, nil
+}
+
+// Generate generates a PDF and returns it together with image paths and compiler diagnostics
+// Every call compiles in its own workspace; workingDir only names the directory whose
+// assets are staged into it, and defaults to the template's directory
+func (iaa *InternalApplicationAdapter) Generate(cfg *config.Config, template config.Template, debugEnabled bool, workingDir string) (GenerationOutput, error) {
 	// Merge configuration
 	mergedCfg := iaa.mergeConfig(cfg, template)
 
-	// Create temporary config file
-	tmpDir := os.TempDir()
-	configPath := filepath.Join(tmpDir, "config.yaml")
-	if err := iaa.createConfigFile(mergedCfg, configPath); err != nil {
-		return nil, nil, err
+	workspaces, err := infraadapters.NewWorkspaceManagerFromConfig(mergedCfg.Workspace)
+	if err != nil {
+		return GenerationOutput{}, fmt.Errorf("invalid workspace settings: %w", err)
+	}
+	stager, err := assets.NewAssetStagerAdapter(infraadapters.NewOSFileSystem(), mergedCfg.Engine.String(), mergedCfg.Assets.Mode, mergedCfg.Assets.Extra)
+	if err != nil {
+		return GenerationOutput{}, fmt.Errorf("invalid asset settings: %w", err)
+	}
+	security, err := infraadapters.NewSecurityPolicyFromConfig(mergedCfg.Security)
+	if err != nil {
+		return GenerationOutput{}, fmt.Errorf("invalid security settings: %w", err)
+	}
+	if _, err := mergedCfg.Cache.MaxAgeDuration(); err != nil {
+		return GenerationOutput{}, fmt.Errorf("invalid cache settings: %w", err)
 	}
-	defer os.Remove(configPath)
 
-	// Create document service using the internal application layer
-	// Use working directory if provided, otherwise use default
-	var docService *documentService.DocumentService
-	if workingDir != "" {
-		docService = iaa.createDocumentServiceWithWorkingDir(mergedCfg, workingDir, iaa.logger)
-	} else {
-		docService = iaa.createDocumentService(mergedCfg)
+	// Per-request directory for the config file and, when none was requested, the output
+	requestDir, err := os.MkdirTemp("", "autopdf-request-*")
+	if err != nil {
+		return GenerationOutput{}, fmt.Errorf("failed to create request directory: %w", err)
 	}
+	defer func() {
+		if !debugEnabled {
+			os.RemoveAll(requestDir)
+		}
+	}()
+	outputRequested := mergedCfg.Output != ""
+	if !outputRequested {
+		mergedCfg.Output = config.Output(filepath.Join(requestDir, "output.pdf"))
+	}
+
+	configPath := filepath.Join(requestDir, "config.yaml")
+	if err := iaa.createConfigFile(mergedCfg, configPath); err != nil {
+		return GenerationOutput{}, err
+	}
+
+	docService := iaa.createDocumentService(mergedCfg, workspaces, stager)
 
 	// Create build request
 	req := documentService.BuildRequest{
@@ -75,12 +128,16 @@ func (iaa *InternalApplicationAdapter) GeneratePDF(cfg *config.Config, template
 		Variables:    &mergedCfg.Variables,
 		Engine:       mergedCfg.Engine.String(),
 		OutputPath:   mergedCfg.Output.String(),
-		WorkingDir:   workingDir, // Pass working directory to BuildRequest
+		OnCollision:  mergedCfg.OutputCollision,
+		WorkingDir:   workingDir, // Assets staged into the workspace
 		DoConvert:    mergedCfg.Conversion.Enabled,
 		DoClean:      false, // Don't clean for API usage
 		DebugEnabled: debugEnabled,
 		Passes:       mergedCfg.Passes,
+		AutoPasses:   mergedCfg.AutoPasses,
 		UseLatexmk:   mergedCfg.UseLatexmk,
+		Toolchain:    autopdfports.NewToolchain(mergedCfg.Bibliography, mergedCfg.Index, mergedCfg.Glossary),
+		Security:     security,
 		Conversion: documentService.ConversionSettings{
 			Enabled: mergedCfg.Conversion.Enabled,
 			Formats: mergedCfg.Conversion.Formats,
@@ -90,10 +147,17 @@ func (iaa *InternalApplicationAdapter) GeneratePDF(cfg *config.Config, template
 	// Build the document
 	ctx := context.Background()
 	result, err := docService.Build(ctx, req)
+	if debugEnabled && iaa.logger != nil {
+		iaa.logger.Info(ctx, "AutoPDF build workspace",
+			autopdfports.NewLogField("workspace", result.Workspace),
+			autopdfports.NewLogField("assets", result.Assets),
+			autopdfports.NewLogField("cache", string(result.Cache)),
+			autopdfports.NewLogField("request_dir", requestDir))
+	}
 	if err != nil {
 		// Check if output file exists despite errors
 		if _, statErr := os.Stat(mergedCfg.Output.String()); os.IsNotExist(statErr) {
-			return nil, nil, err
+			return GenerationOutput{Diagnostics: result.Diagnostics, Workspace: result.Workspace}, err
 		}
 		// If file exists, continue with reading it
 	}
@@ -103,99 +167,62 @@ func (iaa *InternalApplicationAdapter) GeneratePDF(cfg *config.Config, template
 		mergedCfg.Output = config.Output(result.PDFPath)
 	}
 
+	failed := GenerationOutput{Diagnostics: result.Diagnostics, Workspace: result.Workspace}
+
 	// Read the generated PDF
 	pdfBytes, err := os.ReadFile(mergedCfg.Output.String())
 	if err != nil {
-		return nil, nil, err
+		return failed, err
 	}
 
 	// Verify PDF content
 	if len(pdfBytes) == 0 {
-		return nil, nil, fmt.Errorf("generated PDF is empty")
+		return failed, fmt.Errorf("generated PDF is empty")
 	}
 
 	// Basic PDF header validation
 	if len(pdfBytes) < 5 || string(pdfBytes[0:5]) != "%PDF-" {
-		return nil, nil, fmt.Errorf("generated file is not a valid PDF")
+		return failed, fmt.Errorf("generated file is not a valid PDF")
 	}
 
-	// Handle conversion results
+	// Handle conversion results: images converted next to a defaulted output live in the
+	// request directory, so their contents are read before it is removed
 	paths := make(map[string]string)
-	if result.ImagePaths != nil {
-		for i, imagePath := range result.ImagePaths {
-			paths[fmt.Sprintf("image_%d", i)] = imagePath
+	images := make(map[string][]byte)
+	for i, imagePath := range result.ImagePaths {
+		key := fmt.Sprintf("image_%d", i)
+		image, err := os.ReadFile(imagePath)
+		if err != nil {
+			return failed, fmt.Errorf("failed to read converted image: %w", err)
+		}
+		images[key] = image
+		if outputRequested || debugEnabled {
+			paths[key] = imagePath
 		}
 	}
 
-	return pdfBytes, paths, nil
+	output := GenerationOutput{
+		PDFBytes:    pdfBytes,
+		ImagePaths:  paths,
+		Images:      images,
+		Diagnostics: result.Diagnostics,
+		Workspace:   result.Workspace,
+		Cache:       result.Cache,
+	}
+	// A defaulted output lives in the request directory, which is removed
+	if outputRequested {
+		output.OutputPath = mergedCfg.Output.String()
+	}
+	return output, nil
 }
 
 // GeneratePDFWithWorkingDir generates a PDF using the internal application layer with custom working directory
 func (iaa *InternalApplicationAdapter) GeneratePDFWithWorkingDir(cfg *config.Config, template config.Template, debugEnabled bool, workingDir string) ([]byte, map[string]string, error) {
-	// Merge configuration
-	mergedCfg := iaa.mergeConfig(cfg, template)
-
-	// Create temporary config file
-	tmpDir := os.TempDir()
-	configPath := filepath.Join(tmpDir, "config.yaml")
-	if err := iaa.createConfigFile(mergedCfg, configPath); err != nil {
-		return nil, nil, err
-	}
-	defer os.Remove(configPath)
-
-	// Create document service using the internal application layer with custom working directory
-	docService := iaa.createDocumentServiceWithWorkingDir(mergedCfg, workingDir, iaa.logger)
-
-	// Create build request
-	req := documentService.BuildRequest{
-		TemplatePath: mergedCfg.Template.String(),
-		ConfigPath:   configPath,
-		Variables:    &mergedCfg.Variables,
-		Engine:       mergedCfg.Engine.String(),
-		OutputPath:   mergedCfg.Output.String(),
-		WorkingDir:   workingDir, // Pass working directory to BuildRequest
-		DoConvert:    mergedCfg.Conversion.Enabled,
-		DoClean:      false, // Don't clean for API usage
-		DebugEnabled: debugEnabled,
-		Passes:       mergedCfg.Passes,
-		UseLatexmk:   mergedCfg.UseLatexmk,
-		Conversion: documentService.ConversionSettings{
-			Enabled: mergedCfg.Conversion.Enabled,
-			Formats: mergedCfg.Conversion.Formats,
-		},
-	}
-
-	// Build the document
-	ctx := context.Background()
-	result, err := docService.Build(ctx, req)
+	output, err := iaa.Generate(cfg, template, debugEnabled, workingDir)
 	if err != nil {
-		// Check if output file exists despite errors
-		if _, statErr := os.Stat(mergedCfg.Output.String()); os.IsNotExist(statErr) {
-			return nil, nil, err
-		}
-		// If file exists, continue with reading it
-	}
-
-	// Update output path if it was changed by the service
-	if result.PDFPath != "" {
-		mergedCfg.Output = config.Output(result.PDFPath)
-	}
-
-	// Read the generated PDF
-	pdfBytes, err := os.ReadFile(mergedCfg.Output.String())
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read generated PDF: %w", err)
-	}
-
-	// Handle conversion results
-	paths := make(map[string]string)
-	if result.ImagePaths != nil {
-		for i, imagePath := range result.ImagePaths {
-			paths[fmt.Sprintf("image_%d", i)] = imagePath
-		}
+		return nil, nil, fmt.Errorf("failed to generate PDF: %w", err)
 	}
-
-	return pdfBytes, paths, nil
+	return output.PDFBytes, output.ImagePaths, nil
 }
 
 // mergeConfig merges the provided config with defaults and template
@@ -212,13 +239,27 @@ func (iaa *InternalApplicationAdapter) mergeConfig(cfg *config.Config, template
 	}
 
 	merged := &config.Config{
-		Template:   cfg.Template,
-		Output:     cfg.Output,
-		Variables:  cfg.Variables,
-		Engine:     cfg.Engine,
-		Conversion: cfg.Conversion,
-		Passes:     cfg.Passes,     // Preserve Passes from template config
-		UseLatexmk: cfg.UseLatexmk, // Preserve UseLatexmk from template config
+		Template:        cfg.Template,
+		Output:          cfg.Output,
+		Variables:       cfg.Variables,
+		OutputCollision: cfg.OutputCollision,
+		Engine:          cfg.Engine,
+		Conversion:      cfg.Conversion,
+		Passes:          cfg.Passes,     // Preserve Passes from template config
+		AutoPasses:      cfg.AutoPasses, // Preserve AutoPasses from template config
+		UseLatexmk:      cfg.UseLatexmk, // Preserve UseLatexmk from template config
+
+		Bibliography: cfg.Bibliography,
+		Index:        cfg.Index,
+		Glossary:     cfg.Glossary,
+		Partials:     cfg.Partials,
+
+		Workspace: cfg.Workspace,
+		Assets:    cfg.Assets,
+		Security:  cfg.Security,
+		Cache:     cfg.Cache,
+		Tectonic:  cfg.Tectonic,
+		Typst:     cfg.Typst,
 	}
 
 	// Apply template if not set
@@ -233,15 +274,33 @@ func (iaa *InternalApplicationAdapter) mergeConfig(cfg *config.Config, template
 	if merged.Engine == "" {
 		merged.Engine = defaultCfg.Engine
 	}
-	if merged.Output == "" {
-		tmpDir := os.TempDir()
-		tmpOutDir := filepath.Join(tmpDir, "out")
-		os.MkdirAll(tmpOutDir, 0755)
-		merged.Output = config.Output(filepath.Join(tmpOutDir, "output.pdf"))
+	// Workspace and asset settings are server-wide unless the request carries its own
+	if merged.Workspace == (config.Workspace{}) && iaa.config != nil {
+		merged.Workspace = iaa.config.Workspace
+	}
+	if merged.Assets.Mode == "" && len(merged.Assets.Extra) == 0 && iaa.config != nil {
+		merged.Assets = iaa.config.Assets
+	}
+	if merged.Cache == (config.Cache{}) && iaa.config != nil {
+		merged.Cache = iaa.config.Cache
+	}
+	if merged.Tectonic == (config.Tectonic{}) && iaa.config != nil {
+		merged.Tectonic = iaa.config.Tectonic
 	}
+	if len(merged.Typst.FontPaths) == 0 && merged.Typst.PackagePath == "" && merged.Typst.PackageCacheDir == "" && iaa.config != nil {
+		merged.Typst = iaa.config.Typst
+	}
+	if len(merged.Partials) == 0 && iaa.config != nil {
+		merged.Partials = iaa.config.Partials
+	}
+	// A sandboxing server applies its policy to every request, whatever the request says
+	if iaa.config != nil && iaa.config.Security.Sandbox {
+		merged.Security = iaa.config.Security
+	}
+	// An empty output is resolved per request in Generate, never to a shared path
 
 	// Apply defaults for Passes and UseLatexmk if not set (zero values)
-	if merged.Passes < 1 {
+	if merged.Passes < 1 && !merged.AutoPasses {
 		merged.Passes = defaultCfg.Passes
 	}
 	// UseLatexmk defaults to false if not explicitly set, which is fine
@@ -277,10 +336,12 @@ func (iaa *InternalApplicationAdapter) createConfigFile(cfg *config.Config, conf
 	return yaml.NewEncoder(file).Encode(cfg)
 }
 
-// createDocumentService creates the internal document service
-func (iaa *InternalApplicationAdapter) createDocumentService(cfg *config.Config) *documentService.DocumentService {
-	// Use default working directory for backward compatibility
-	return iaa.createDocumentServiceWithWorkingDir(cfg, "/tmp/autopdf", iaa.logger)
+// createDocumentService creates the internal document service building in the given workspaces
+func (iaa *InternalApplicationAdapter) createDocumentService(cfg *config.Config, workspaces autopdfports.WorkspaceManager, stager autopdfports.AssetStager) *documentService.DocumentService {
+	service := iaa.createDocumentServiceWithWorkingDir(cfg, "", iaa.logger)
+	service.Workspaces = workspaces
+	service.AssetStager = stager
+	return service
 }
 
 // createDocumentServiceWithWorkingDir creates the internal document service with custom working directory
@@ -288,14 +349,40 @@ func (iaa *InternalApplicationAdapter) createDocumentService(cfg *config.Config)
 func (iaa *InternalApplicationAdapter) createDocumentServiceWithWorkingDir(cfg *config.Config, workingDir string, logger autopdfports.Logger) *documentService.DocumentService {
 	// Create infrastructure adapters (DIP: Application depends on abstractions)
 	fileSystem := infraadapters.NewOSFileSystem()
-	executor := infraadapters.NewOSCommandExecutor()
+
+	// Every engine and tool run goes through the sandbox; invalid settings keep the strictest default
+	security, err := infraadapters.NewSecurityPolicyFromConfig(cfg.Security)
+	if err != nil {
+		security = autopdfports.NewSandboxPolicy()
+	}
+	executor, _ := infraadapters.NewSandboxedCommandExecutor(infraadapters.NewOSCommandExecutor(), security)
 
 	// Create adapters using the internal application layer
 	templateAdapter := template.NewTemplateProcessorAdapter(cfg)
+	var templateProcessor autopdfports.TemplateProcessor = templateAdapter
+	if iaa.rendered {
+		templateProcessor = template.NewPassThroughProcessor()
+	}
 
 	// Select LaTeX compiler based on UseLatexmk flag
 	var latexAdapter autopdfports.LaTeXCompiler
-	if cfg.UseLatexmk {
+	if cfg.Engine.String() == autopdfports.EngineTectonic {
+		// Tectonic reruns itself; neither latexmk nor manual passes apply
+		if logger != nil {
+			logger.Info(context.Background(), "Using tectonic adapter",
+				autopdfports.NewLogField("engine", string(cfg.Engine)),
+				autopdfports.NewLogField("working_dir", workingDir))
+		}
+		latexAdapter = latex.NewTectonicCompilerAdapter(cfg, fileSystem, executor)
+	} else if cfg.Engine.String() == autopdfports.EngineTypst {
+		// Typst is a different markup language with a single-run compiler
+		if logger != nil {
+			logger.Info(context.Background(), "Using typst adapter",
+				autopdfports.NewLogField("engine", string(cfg.Engine)),
+				autopdfports.NewLogField("working_dir", workingDir))
+		}
+		latexAdapter = typst.NewTypstCompilerAdapter(cfg, fileSystem, executor)
+	} else if cfg.UseLatexmk {
 		// Use latexmk adapter with logger for transparency
 		// Log selection for debugging
 		if logger != nil {
@@ -317,17 +404,24 @@ func (iaa *InternalApplicationAdapter) createDocumentServiceWithWorkingDir(cfg *
 		latexAdapter = latex.NewLaTeXCompilerAdapterWithWorkingDir(cfg, fileSystem, executor, workingDir)
 	}
 
+	// Identical requests reuse the cached PDF; Generate has already rejected invalid cache settings
+	compiler, err := cache.NewCompileCacheFromConfig(latexAdapter, cfg)
+	if err != nil {
+		compiler = latexAdapter
+	}
+
 	converterAdapter := converter.NewConverterAdapter(cfg)
 	cleanerAdapter := cleaner.NewCleanerAdapter()
 
 	// Create document service
 	return &documentService.DocumentService{
-		TemplateProcessor: templateAdapter,
-		LaTeXCompiler:     latexAdapter,
+		TemplateProcessor: templateProcessor,
+		LaTeXCompiler:     compiler,
 		Converter:         converterAdapter,
 		Cleaner:           cleanerAdapter,
 		PathOps:           infraadapters.NewOSPathOperations(),
 		FileSystem:        infraadapters.NewOSFileSystem(),
+		OutputPaths:       templateAdapter,
 		ErrorFactory:      errors.NewDomainErrorFactory(nil),
 	}
 }
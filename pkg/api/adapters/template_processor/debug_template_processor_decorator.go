@@ -9,6 +9,7 @@ import (
 
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain/generation"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // DebugTemplateProcessorDecorator adds debug capabilities to template processing
@@ -38,10 +39,11 @@ func NewDebugTemplateProcessorDecorator(
 func (d *DebugTemplateProcessorDecorator) Process(
 	ctx context.Context,
 	templatePath string,
-	variables map[string]string,
+	variables *generation.TemplateVariables,
+	options generation.TemplateOptions,
 ) (string, error) {
 	// Delegate to wrapped processor
-	content, err := d.wrapped.Process(ctx, templatePath, variables)
+	content, err := d.wrapped.Process(ctx, templatePath, variables, options)
 	if err != nil {
 		return "", err
 	}
@@ -68,6 +70,11 @@ func (d *DebugTemplateProcessorDecorator) GetTemplateVariables(templatePath stri
 	return d.wrapped.GetTemplateVariables(templatePath)
 }
 
+// Lint delegates to wrapped service
+func (d *DebugTemplateProcessorDecorator) Lint(templatePath string, variables *generation.TemplateVariables, delimiters config.Delimiters) ([]generation.Diagnostic, error) {
+	return d.wrapped.Lint(templatePath, variables, delimiters)
+}
+
 // createConcreteFile creates a persistent concrete file with variable substitution
 func (d *DebugTemplateProcessorDecorator) createConcreteFile(
 	templatePath, content string,
@@ -83,8 +90,13 @@ func (d *DebugTemplateProcessorDecorator) createConcreteFile(
 	}
 
 	// Generate concrete file name
-	baseName := strings.TrimSuffix(filepath.Base(templatePath), ".tex")
-	concreteFileName := fmt.Sprintf("autopdf-concrete-%s-%s.tex", baseName, d.requestID)
+	// The concrete file keeps the template's extension: .tex, or .typ for Typst
+	ext := filepath.Ext(templatePath)
+	if ext == "" {
+		ext = ".tex"
+	}
+	baseName := strings.TrimSuffix(filepath.Base(templatePath), ext)
+	concreteFileName := fmt.Sprintf("autopdf-concrete-%s-%s%s", baseName, d.requestID, ext)
 	concreteFile := filepath.Join(d.concreteFileDir, concreteFileName)
 
 	// Write content to concrete file
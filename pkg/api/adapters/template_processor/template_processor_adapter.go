@@ -5,15 +5,18 @@ package template_processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
 	"strings"
-	"text/template"
 
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/template"
+	autopdfports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	"github.com/BuddhiLW/AutoPDF/pkg/api"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain"
+	"github.com/BuddhiLW/AutoPDF/pkg/api/domain/generation"
 	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
@@ -32,11 +35,16 @@ func NewTemplateProcessorAdapter(cfg *config.Config, logger *logger.LoggerAdapte
 }
 
 // Process processes a template with variables
-func (tpa *TemplateProcessorAdapter) Process(ctx context.Context, templatePath string, variables map[string]string) (string, error) {
+// Delimiters, when set, replace the server configuration's for this template
+// Rendering is strict when either the options or the server configuration ask for it
+func (tpa *TemplateProcessorAdapter) Process(ctx context.Context, templatePath string, variables *generation.TemplateVariables, options generation.TemplateOptions) (string, error) {
+	if variables == nil {
+		variables = generation.NewTemplateVariables(nil)
+	}
 	tpa.logger.DebugWithFields("Starting template processing",
 		"template_path", templatePath,
-		"variable_count", len(variables),
-		"variable_keys", getStringMapKeys(variables),
+		"variable_count", variables.Len(),
+		"variable_keys", variables.Keys(),
 	)
 
 	if templatePath == "" {
@@ -71,12 +79,23 @@ func (tpa *TemplateProcessorAdapter) Process(ctx context.Context, templatePath s
 	)
 
 	// Process template with variables
-	processedContent, err := tpa.processTemplate(string(content), variables)
+	processedContent, err := tpa.processTemplate(templatePath, string(content), variables, options)
 	if err != nil {
 		tpa.logger.ErrorWithFields("Failed to process template",
 			"template_path", templatePath,
 			"error", err,
 		)
+		var missing *autopdfports.MissingVariableError
+		if errors.As(err, &missing) {
+			return "", domain.TemplateProcessingError{
+				Code:    domain.ErrCodeVariableMissing,
+				Message: fmt.Sprintf(api.ErrTemplateVariableMissing, missing.Variable, missing.File, missing.Line, missing.Column, strings.TrimSpace(missing.Snippet)),
+				Details: api.NewErrorDetails(api.ErrorCategoryTemplate, api.ErrorSeverityHigh).
+					WithTemplatePath(templatePath).
+					WithTemplateLocation(missing.File, missing.Line, missing.Column, missing.Snippet).
+					AddContext(api.ContextKeyVariable, missing.Variable),
+			}
+		}
 		// Format the error message properly to avoid literal %s
 		errorMessage := fmt.Sprintf(api.ErrTemplateProcessingFailed, err.Error())
 		return "", domain.TemplateProcessingError{
@@ -133,7 +152,10 @@ func (tpa *TemplateProcessorAdapter) ValidateTemplate(templatePath string) error
 		}
 	}
 
-	// Basic LaTeX validation
+	// Basic LaTeX validation; Typst documents have no required structure
+	if template.DialectForTemplate(templatePath).Name != template.LaTeXDialect.Name {
+		return nil
+	}
 	if err := tpa.validateLaTeXContent(string(content)); err != nil {
 		// Format the error message properly to avoid literal %s
 		errorMessage := fmt.Sprintf(api.ErrTemplateSyntaxInvalid, err.Error())
@@ -172,108 +194,130 @@ func (tpa *TemplateProcessorAdapter) GetTemplateVariables(templatePath string) (
 		}
 	}
 
-	variables := tpa.extractVariables(string(content))
-	return variables, nil
-}
-
-// processTemplate processes template content with variables using Go's text/template
-func (tpa *TemplateProcessorAdapter) processTemplate(content string, variables map[string]string) (string, error) {
-	// Import text/template at the top of the file
-	// We need to use Go's text/template to support conditionals, loops, and dot notation
-
-	// Convert flattened variables back to nested structure for template execution
-	templateData := tpa.reconstructNestedStructure(variables)
-
-	// Create template with custom delimiters
-	tmpl, err := tpa.createTemplate(content)
+	dialect, err := tpa.dialect(templatePath, config.Delimiters{})
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return nil, invalidTemplateError(templatePath, err)
 	}
-
-	// Execute template
-	var buf strings.Builder
-	if err := tmpl.Execute(&buf, templateData); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+	partials, err := tpa.loadPartials(dialect)
+	if err != nil {
+		return nil, invalidTemplateError(templatePath, err)
 	}
 
-	return buf.String(), nil
+	// The parsed template is walked through the partials it includes, so range
+	// and with blocks and template calls are told apart from variables
+	variables, err := template.ReferencedVariables(templatePath, string(content), dialect, partials)
+	if err != nil {
+		// An unparsable template still lists the actions found in its text
+		tpa.logger.WarnWithFields("Failed to parse template",
+			"template_path", templatePath,
+			"error", err,
+		)
+		return tpa.extractVariables(string(content), dialect), nil
+	}
+	return variables, nil
 }
 
-// createTemplate creates a Go template with custom delimiters
-func (tpa *TemplateProcessorAdapter) createTemplate(content string) (*template.Template, error) {
-	// Create function map for template functions
-	funcMap := template.FuncMap{
-		"eq": func(a, b interface{}) bool {
-			return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
-		},
+// Lint checks a template without rendering it, against the variables when given
+// Delimiters, when set, replace the server configuration's for this template
+func (tpa *TemplateProcessorAdapter) Lint(templatePath string, variables *generation.TemplateVariables, delimiters config.Delimiters) ([]generation.Diagnostic, error) {
+	if templatePath == "" {
+		return nil, domain.TemplateProcessingError{
+			Code:    domain.ErrCodeTemplateNotFound,
+			Message: api.ErrTemplatePathRequired,
+			Details: api.NewErrorDetails(api.ErrorCategoryTemplate, api.ErrorSeverityHigh),
+		}
 	}
 
-	// Create template with custom delimiters to avoid conflicts with LaTeX
-	tmpl, err := template.New("latex").
-		Funcs(funcMap).
-		Delims("delim[[", "]]").
-		Parse(content)
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, domain.TemplateProcessingError{
+			Code:    domain.ErrCodeTemplateNotFound,
+			Message: fmt.Sprintf(api.ErrTemplateFileNotReadable, templatePath),
+			Details: api.NewErrorDetails(api.ErrorCategoryTemplate, api.ErrorSeverityHigh).
+				WithTemplatePath(templatePath).
+				WithError(err),
+		}
+	}
 
+	dialect, err := tpa.dialect(templatePath, delimiters)
+	if err != nil {
+		return nil, invalidTemplateError(templatePath, err)
+	}
+	partials, err := tpa.loadPartials(dialect)
 	if err != nil {
-		return nil, err
+		return nil, invalidTemplateError(templatePath, err)
 	}
 
-	return tmpl, nil
+	var vars *template.LintVariables
+	if variables != nil {
+		vars = &template.LintVariables{Data: variables.TemplateData()}
+	}
+	return toDomainDiagnostics(template.Lint(templatePath, string(content), dialect, partials, vars)), nil
 }
 
-// reconstructNestedStructure converts flattened variables back to nested structure
-// Handles flattened dot-notation keys from Flatten() method
-// Variables are placed at root level for direct template access (e.g., .logo_path)
-// Templates should use delim[[.logo_path]] NOT delim[[.vars.logo_path]]
-func (tpa *TemplateProcessorAdapter) reconstructNestedStructure(variables map[string]string) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	for key, value := range variables {
-		// Handle flattened dot-notation keys (e.g., "velorio.dia", "velorio.periodo.inicio")
-		parts := splitKey(key)
-		tpa.setNestedValue(result, parts, value)
+// invalidTemplateError reports a template the delimiters or partials it is used with prevent processing
+func invalidTemplateError(templatePath string, err error) error {
+	return domain.TemplateProcessingError{
+		Code:    domain.ErrCodeTemplateInvalid,
+		Message: fmt.Sprintf(api.ErrTemplateProcessingFailed, err.Error()),
+		Details: api.NewErrorDetails(api.ErrorCategoryTemplate, api.ErrorSeverityHigh).
+			WithTemplatePath(templatePath).
+			WithError(err),
 	}
+}
 
-	// Return variables at root level (NOT wrapped under "vars" key)
-	// This allows templates to use direct access like delim[[.logo_path]]
-	// instead of requiring delim[[.vars.logo_path]]
+// toDomainDiagnostics converts lint diagnostics to API domain diagnostics
+func toDomainDiagnostics(diagnostics []autopdfports.Diagnostic) []generation.Diagnostic {
+	result := make([]generation.Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		result = append(result, generation.Diagnostic{
+			Kind:     string(d.Kind),
+			Severity: string(d.Severity),
+			File:     d.File,
+			Line:     d.Line,
+			Message:  d.Message,
+			Context:  d.Context,
+		})
+	}
 	return result
 }
 
-// splitKey splits a dot-notation key into parts
-func splitKey(key string) []string {
-	return strings.Split(key, ".")
+// loadPartials loads the partials of the server configuration's directories
+func (tpa *TemplateProcessorAdapter) loadPartials(dialect template.Dialect) ([]template.Partial, error) {
+	return template.LoadPartials(template.PartialDirs(tpa.config), dialect)
 }
 
-// setNestedValue sets a value in a nested map structure
-func (tpa *TemplateProcessorAdapter) setNestedValue(data map[string]interface{}, parts []string, value string) {
-	if len(parts) == 0 {
-		return
+// dialect returns the template's dialect, told apart by its extension, with the request's
+// delimiters or else the server configuration's
+func (tpa *TemplateProcessorAdapter) dialect(templatePath string, delimiters config.Delimiters) (template.Dialect, error) {
+	if !delimiters.IsSet() && tpa.config != nil {
+		delimiters = tpa.config.Delimiters
 	}
-
-	if len(parts) == 1 {
-		data[parts[0]] = value
-		return
+	if err := delimiters.Validate(); err != nil {
+		return template.Dialect{}, err
 	}
+	return template.DialectForTemplate(templatePath).WithDelimiters(delimiters.Left, delimiters.Right), nil
+}
 
-	// Create nested map if it doesn't exist
-	if _, exists := data[parts[0]]; !exists {
-		data[parts[0]] = make(map[string]interface{})
+// processTemplate processes template content with the variables' typed template data
+func (tpa *TemplateProcessorAdapter) processTemplate(templatePath, content string, variables *generation.TemplateVariables, options generation.TemplateOptions) (string, error) {
+	dialect, err := tpa.dialect(templatePath, options.Delimiters)
+	if err != nil {
+		return "", err
 	}
-
-	// Ensure it's a map
-	if nested, ok := data[parts[0]].(map[string]interface{}); ok {
-		tpa.setNestedValue(nested, parts[1:], value)
+	partials, err := tpa.loadPartials(dialect)
+	if err != nil {
+		return "", err
 	}
-}
-
-// getStringMapKeys returns the keys of a string map for debugging
-func getStringMapKeys(m map[string]string) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
+	execute := template.Execute
+	if options.Strict || (tpa.config != nil && tpa.config.Strict) {
+		execute = template.ExecuteStrict
+	}
+	processed, err := execute(templatePath, content, variables.TemplateData(), dialect, partials...)
+	if err != nil {
+		return "", fmt.Errorf("failed to process template: %w", err)
 	}
-	return keys
+	return processed, nil
 }
 
 // validateLaTeXContent performs basic LaTeX validation
@@ -302,9 +346,11 @@ func (tpa *TemplateProcessorAdapter) validateLaTeXContent(content string) error
 }
 
 // extractVariables extracts variable names from template content
-func (tpa *TemplateProcessorAdapter) extractVariables(content string) []string {
-	delimStart := "delim[["
-	delimEnd := "]]"
+// The content's magic comment, if any, overrides the dialect's delimiters
+func (tpa *TemplateProcessorAdapter) extractVariables(content string, dialect template.Dialect) []string {
+	dialect, content = dialect.ForContent(content)
+	delimStart := dialect.LeftDelim
+	delimEnd := dialect.RightDelim
 
 	pattern := regexp.MustCompile(regexp.QuoteMeta(delimStart) + `([^` + regexp.QuoteMeta(delimEnd) + `]+)` + regexp.QuoteMeta(delimEnd))
 	matches := pattern.FindAllStringSubmatch(content, -1)
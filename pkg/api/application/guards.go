@@ -6,10 +6,13 @@ package application
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	autopdfports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 	"github.com/BuddhiLW/AutoPDF/pkg/api"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain/generation"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // Constants for content preview and validation
@@ -50,6 +53,7 @@ func (g *RequestValidationGuard) Validate(ctx context.Context, req generation.PD
 		func() error { return g.validateOutputPath(req.OutputPath) },
 		func() error { return g.validateTemplateFile(req.TemplatePath) },
 		func() error { return g.validateVariables(req.Variables) },
+		func() error { return g.validateSchema(req) },
 	}
 
 	for _, validator := range validators {
@@ -72,7 +76,7 @@ func (g *RequestValidationGuard) validateTemplatePath(templatePath string) error
 	return nil
 }
 
-// validateEngine guards against empty engine names
+// validateEngine guards against empty and unsupported engine names
 func (g *RequestValidationGuard) validateEngine(engine string) error {
 	if len(engine) < MinEngineNameLength {
 		return domain.PDFGenerationError{
@@ -80,6 +84,12 @@ func (g *RequestValidationGuard) validateEngine(engine string) error {
 			Message: "LaTeX engine is required",
 		}
 	}
+	if err := autopdfports.ValidateEngine(engine); err != nil {
+		return domain.PDFGenerationError{
+			Code:    domain.ErrCodeEngineNotFound,
+			Message: fmt.Sprintf(api.ErrEngineNotSupported, engine),
+		}
+	}
 	return nil
 }
 
@@ -125,6 +135,38 @@ func (g *RequestValidationGuard) validateVariables(variables *generation.Templat
 	return nil
 }
 
+// validateSchema guards against variables the template's schema rejects, before any LaTeX runs
+// The schema is the request's, or else the schema file next to the template
+func (g *RequestValidationGuard) validateSchema(req generation.PDFGenerationRequest) error {
+	schema := req.Options.Schema
+	if schema == nil {
+		var err error
+		if schema, err = config.ReadSchema(req.TemplatePath); err != nil {
+			return domain.VariableResolutionError{
+				Code:    domain.ErrCodeVariableInvalid,
+				Message: fmt.Sprintf(api.ErrVariableSchemaInvalid, err.Error()),
+				Details: api.NewErrorDetails(api.ErrorCategoryVariable, api.ErrorSeverityHigh).
+					WithTemplatePath(req.TemplatePath).
+					WithError(err),
+			}
+		}
+	}
+
+	fields := schema.Validate(req.Variables.TemplateData())
+	if len(fields) == 0 {
+		return nil
+	}
+	schemaErr := &config.SchemaError{Fields: fields}
+	return domain.VariableResolutionError{
+		Code:    domain.ErrCodeVariableSchemaMismatch,
+		Message: fmt.Sprintf(api.ErrVariableSchemaMismatch, strings.Join(schemaErr.Messages(), "; ")),
+		Details: api.NewErrorDetails(api.ErrorCategoryVariable, api.ErrorSeverityHigh).
+			WithTemplatePath(req.TemplatePath).
+			WithError(schemaErr),
+		Fields: fields,
+	}
+}
+
 // WatchModeGuard guards watch mode operations
 type WatchModeGuard struct {
 	watchManager generation.WatchModeManager
@@ -9,6 +9,7 @@ import (
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/domain/watch"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain/generation"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // PDFGenerationApplicationService provides a clean interface for PDF generation
@@ -58,6 +59,11 @@ func (s *PDFGenerationApplicationService) GetTemplateVariables(templatePath stri
 	return s.orchestrationService.GetTemplateVariables(templatePath)
 }
 
+// LintTemplate checks a template without rendering it
+func (s *PDFGenerationApplicationService) LintTemplate(templatePath string, variables *generation.TemplateVariables, delimiters config.Delimiters) ([]generation.Diagnostic, error) {
+	return s.orchestrationService.LintTemplate(templatePath, variables, delimiters)
+}
+
 // GetSupportedEngines returns supported LaTeX engines
 func (s *PDFGenerationApplicationService) GetSupportedEngines() []string {
 	return s.orchestrationService.GetSupportedEngines()
@@ -5,8 +5,10 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
@@ -14,6 +16,7 @@ import (
 	"github.com/BuddhiLW/AutoPDF/pkg/api"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain/generation"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // PDFOrchestrationService encapsulates all orchestration concerns
@@ -92,7 +95,8 @@ func (s *PDFOrchestrationService) GeneratePDF(ctx context.Context, req generatio
 		}, err
 	}
 
-	// Step 2: Resolve complex variables to simple key-value pairs
+	// Step 2: Resolve the variables; the flattened key-value pairs are only logged,
+	// the template is executed with the typed variables
 	s.logger.DebugWithFields("Starting variable resolution",
 		"input_variable_count", variableCount,
 	)
@@ -123,8 +127,17 @@ func (s *PDFOrchestrationService) GeneratePDF(ctx context.Context, req generatio
 		"variable_count", len(simpleVariables),
 	)
 
-	processedContent, err := s.templateService.Process(ctx, req.TemplatePath, simpleVariables)
+	templateOptions := generation.TemplateOptions{
+		Delimiters: req.Options.Delimiters,
+		Strict:     req.Options.Strict,
+	}
+	processedContent, err := s.templateService.Process(ctx, req.TemplatePath, req.Variables, templateOptions)
 	if err != nil {
+		// A missing variable's error already locates it in the template
+		var templateErr domain.TemplateProcessingError
+		if errors.As(err, &templateErr) && templateErr.Code == domain.ErrCodeVariableMissing {
+			return generation.PDFGenerationResult{Success: false, Error: templateErr}, err
+		}
 		// Format the error message properly to avoid literal %s
 		errorMessage := fmt.Sprintf(api.ErrTemplateProcessingFailed, err.Error())
 		return generation.PDFGenerationResult{
@@ -139,9 +152,25 @@ func (s *PDFOrchestrationService) GeneratePDF(ctx context.Context, req generatio
 		}, err
 	}
 
-	// Write processed template to a temporary file
-	// This ensures the LaTeX engine uses the processed content with variables replaced
-	tempFile, err := os.CreateTemp("", "autopdf-processed-*.tex")
+	// Write processed template to a private temporary directory
+	// This ensures the LaTeX engine uses the processed content with variables replaced,
+	// and that concurrent requests never see each other's processed templates
+	tempDir, err := os.MkdirTemp("", "autopdf-processed-*")
+	if err != nil {
+		return generation.PDFGenerationResult{
+			Success: false,
+			Error: domain.TemplateProcessingError{
+				Code:    domain.ErrCodeTemplateInvalid,
+				Message: "Failed to create temporary file for processed template",
+				Details: api.NewErrorDetails(api.ErrorCategoryTemplate, api.ErrorSeverityHigh).
+					WithTemplatePath(req.TemplatePath).
+					WithError(err),
+			},
+		}, err
+	}
+	defer os.RemoveAll(tempDir) // Clean up temporary file after generation
+
+	tempFile, err := os.Create(filepath.Join(tempDir, filepath.Base(req.TemplatePath)))
 	if err != nil {
 		return generation.PDFGenerationResult{
 			Success: false,
@@ -154,7 +183,6 @@ func (s *PDFOrchestrationService) GeneratePDF(ctx context.Context, req generatio
 			},
 		}, err
 	}
-	defer os.Remove(tempFile.Name()) // Clean up temporary file after generation
 
 	// Log processed content using guard
 	contentLen := len(processedContent)
@@ -186,12 +214,19 @@ func (s *PDFOrchestrationService) GeneratePDF(ctx context.Context, req generatio
 	)
 
 	// Step 4: Generate PDF using external service with processed template
+	// Assets are still staged from the original template's directory, and the processed
+	// template is compiled as it is: its values were escaped by the one rendering above
+	options := req.Options
+	if options.WorkingDir == "" {
+		options.WorkingDir = filepath.Dir(req.TemplatePath)
+	}
+	options.Rendered = true
 	generationReq := generation.PDFGenerationRequest{
 		TemplatePath: tempFile.Name(), // Use processed template file
 		Variables:    req.Variables,   // Keep original variables for metadata
 		Engine:       req.Engine,
 		OutputPath:   req.OutputPath,
-		Options:      req.Options,
+		Options:      options,
 	}
 
 	result, err := s.externalService.Generate(ctx, generationReq)
@@ -199,7 +234,8 @@ func (s *PDFOrchestrationService) GeneratePDF(ctx context.Context, req generatio
 		// Format the error message properly to avoid literal %s
 		errorMessage := fmt.Sprintf(api.ErrPDFGenerationFailed, err.Error())
 		return generation.PDFGenerationResult{
-			Success: false,
+			Diagnostics: result.Diagnostics,
+			Success:     false,
 			Error: domain.PDFGenerationError{
 				Code:    domain.ErrCodePDFGenerationFailed,
 				Message: errorMessage,
@@ -271,6 +307,11 @@ func (s *PDFOrchestrationService) GetTemplateVariables(templatePath string) ([]s
 	return s.templateService.GetTemplateVariables(templatePath)
 }
 
+// LintTemplate checks a template without rendering it
+func (s *PDFOrchestrationService) LintTemplate(templatePath string, variables *generation.TemplateVariables, delimiters config.Delimiters) ([]generation.Diagnostic, error) {
+	return s.templateService.Lint(templatePath, variables, delimiters)
+}
+
 // GetSupportedEngines returns supported LaTeX engines
 func (s *PDFOrchestrationService) GetSupportedEngines() []string {
 	return s.externalService.GetSupportedEngines()
@@ -57,6 +57,12 @@ func (b *PDFGenerationRequestBuilder) WithOutput(outputPath string) *PDFGenerati
 	return b
 }
 
+// WithOutputCollision sets what happens when the output exists: overwrite, fail or suffix
+func (b *PDFGenerationRequestBuilder) WithOutputCollision(policy string) *PDFGenerationRequestBuilder {
+	b.request.Options.OutputCollision = policy
+	return b
+}
+
 // WithVariable sets a simple variable
 func (b *PDFGenerationRequestBuilder) WithVariable(key string, value interface{}) *PDFGenerationRequestBuilder {
 	if b.request.Variables == nil {
@@ -203,12 +209,42 @@ func (b *PDFGenerationRequestBuilder) WithPasses(passes int) *PDFGenerationReque
 	return b
 }
 
+// WithAutoPasses enables rerun detection, using passes as the cap
+func (b *PDFGenerationRequestBuilder) WithAutoPasses(enabled bool) *PDFGenerationRequestBuilder {
+	b.request.Options.AutoPasses = enabled
+	return b
+}
+
 // WithLatexmk enables latexmk compilation
 func (b *PDFGenerationRequestBuilder) WithLatexmk(enabled bool) *PDFGenerationRequestBuilder {
 	b.request.Options.UseLatexmk = enabled
 	return b
 }
 
+// WithStrict makes variables the template uses and the request does not define fail the generation
+func (b *PDFGenerationRequestBuilder) WithStrict(strict bool) *PDFGenerationRequestBuilder {
+	b.request.Options.Strict = strict
+	return b
+}
+
+// WithAssets adds globs of files to stage beside the template, relative to it
+func (b *PDFGenerationRequestBuilder) WithAssets(globs ...string) *PDFGenerationRequestBuilder {
+	b.request.Options.Assets = append(b.request.Options.Assets, globs...)
+	return b
+}
+
+// WithSchema sets the schema the variables are validated against, replacing the template's schema file
+func (b *PDFGenerationRequestBuilder) WithSchema(schema *config.Schema) *PDFGenerationRequestBuilder {
+	b.request.Options.Schema = schema
+	return b
+}
+
+// WithDelimiters sets the template action delimiters, replacing the configured ones
+func (b *PDFGenerationRequestBuilder) WithDelimiters(left, right string) *PDFGenerationRequestBuilder {
+	b.request.Options.Delimiters = config.Delimiters{Left: left, Right: right}
+	return b
+}
+
 // Build constructs the final PDF generation request
 func (b *PDFGenerationRequestBuilder) Build() generation.PDFGenerationRequest {
 	return b.request
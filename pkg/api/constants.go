@@ -45,7 +45,7 @@ const (
 	ErrTemplateFileNotFound    = "Template file does not exist: %s"
 	ErrTemplateFileNotReadable = "Cannot read template file: %s"
 	ErrTemplateFileEmpty       = "Template file is empty: %s"
-	ErrTemplateFileInvalid     = "Template file is not a valid LaTeX file: %s"
+	ErrTemplateFileInvalid     = "Template file is not a valid LaTeX or Typst file: %s"
 
 	// Template Content validation
 	ErrTemplateContentInvalid    = "Template content validation failed: %s"
@@ -58,6 +58,7 @@ const (
 	ErrTemplateRenderingFailed   = "Template rendering failed: %s"
 	ErrTemplateCompilationFailed = "Template compilation failed: %s"
 	ErrTemplateValidationFailed  = "Template validation failed: %s"
+	ErrTemplateVariableMissing   = "Template variable %q is missing at %s:%d:%d: %s"
 )
 
 // Variable Resolution Error Messages
@@ -73,6 +74,8 @@ const (
 	ErrVariableResolutionFailed = "Variable resolution failed: %s"
 	ErrVariableFlatteningFailed = "Variable flattening failed: %s"
 	ErrVariableValidationFailed = "Variable validation failed: %s"
+	ErrVariableSchemaInvalid    = "Invalid variable schema: %s"
+	ErrVariableSchemaMismatch   = "Variables do not match the template's schema: %s"
 )
 
 // PDF Generation Error Messages
@@ -149,6 +152,10 @@ const (
 	ContextKeyDuration     = "duration"
 	ContextKeyFileSize     = "file_size"
 	ContextKeyPageCount    = "page_count"
+	ContextKeyVariable     = "variable"
+	ContextKeyLine         = "line"
+	ContextKeyColumn       = "column"
+	ContextKeySnippet      = "snippet"
 )
 
 // Validation Rules
@@ -0,0 +1,40 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package generation
+
+import "errors"
+
+// Diagnostic is a single finding from the LaTeX engine log or the template linter
+// Kind is one of: error, warning, overfull_box, underfull_box,
+// undefined_reference, undefined_citation, missing_file, and for the linter
+// template_syntax, undefined_variable, unused_variable, range_non_list,
+// unknown_function, unbalanced_environment, unbalanced_brace
+type Diagnostic struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Context  string `json:"context,omitempty"`
+}
+
+// CompilationFailedError carries the diagnostics of a failed generation
+// so transport layers can report them alongside the error
+type CompilationFailedError struct {
+	Diagnostics []Diagnostic
+	Err         error
+}
+
+func (e *CompilationFailedError) Error() string { return e.Err.Error() }
+
+func (e *CompilationFailedError) Unwrap() error { return e.Err }
+
+// DiagnosticsFromError returns the diagnostics attached to err, if any
+func DiagnosticsFromError(err error) []Diagnostic {
+	var compErr *CompilationFailedError
+	if errors.As(err, &compErr) {
+		return compErr.Diagnostics
+	}
+	return nil
+}
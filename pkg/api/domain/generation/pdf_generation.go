@@ -6,6 +6,8 @@ package generation
 import (
 	"context"
 	"time"
+
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // PDFGenerationRequest represents a request to generate a PDF
@@ -29,8 +31,25 @@ type PDFGenerationOptions struct {
 	RequestID  string // For unique file naming
 	WatchMode  bool   // Enable file watching for automatic rebuilds
 	WorkingDir string // Working directory for LaTeX compilation (isolates template builds)
-	Passes     int    // Number of compilation passes
+	Passes     int    // Number of compilation passes (the cap when AutoPasses is set)
+	AutoPasses bool   // Stop rerunning once cross-references have converged
 	UseLatexmk bool   // Whether to use latexmk
+	// What to do when the output exists: overwrite (default), fail or suffix
+	OutputCollision string
+	// Template action delimiters; unset keeps the server's or the template dialect's
+	Delimiters config.Delimiters
+	Strict     bool     // Fail on variables the template uses and the request does not define
+	Assets     []string // Globs of files to stage beside the template, relative to it
+	// Validates the variables; unset uses the schema file next to the template
+	Schema *config.Schema
+	// TemplatePath holds an already rendered source, compiled without another template pass
+	Rendered bool
+}
+
+// TemplateOptions are the per-request settings a template is rendered with
+type TemplateOptions struct {
+	Delimiters config.Delimiters // Unset keeps the server's or the template dialect's
+	Strict     bool              // Missing variables fail the rendering; the server's config can require it too
 }
 
 // DebugOptions contains debug-specific settings
@@ -50,11 +69,12 @@ type ConversionOptions struct {
 
 // PDFGenerationResult represents the result of PDF generation
 type PDFGenerationResult struct {
-	PDFPath    string
-	ImagePaths []string
-	Success    bool
-	Error      error
-	Metadata   PDFMetadata
+	PDFPath     string
+	ImagePaths  []string
+	Diagnostics []Diagnostic
+	Success     bool
+	Error       error
+	Metadata    PDFMetadata
 }
 
 // PDFMetadata contains metadata about a PDF file
@@ -76,9 +96,11 @@ type PDFGenerationService interface {
 
 // TemplateProcessingService defines the interface for template processing
 type TemplateProcessingService interface {
-	Process(ctx context.Context, templatePath string, variables map[string]string) (string, error)
+	Process(ctx context.Context, templatePath string, variables *TemplateVariables, options TemplateOptions) (string, error)
 	ValidateTemplate(templatePath string) error
 	GetTemplateVariables(templatePath string) ([]string, error)
+	// Lint checks a template without rendering it; nil variables skip the checks depending on them
+	Lint(templatePath string, variables *TemplateVariables, delimiters config.Delimiters) ([]Diagnostic, error)
 }
 
 // VariableResolver defines the interface for resolving complex variables
@@ -112,8 +112,17 @@ func (tv *TemplateVariables) ToMap() map[string]interface{} {
 	return result
 }
 
-// Flatten converts TemplateVariables to map[string]string for template processing
-// This is the main method used by the template processor
+// TemplateData converts TemplateVariables to the data templates are executed with
+// Unlike Flatten, values keep their types and nesting: bools, numbers, slices and maps
+func (tv *TemplateVariables) TemplateData() map[string]interface{} {
+	if tv == nil || tv.variables == nil {
+		return make(map[string]interface{})
+	}
+	return tv.variables.TemplateData()
+}
+
+// Flatten converts TemplateVariables to map[string]string with dot-notation keys
+// Used for logging and validation; templates are executed with TemplateData
 func (tv *TemplateVariables) Flatten() map[string]string {
 	if tv.variables == nil {
 		return make(map[string]string)
@@ -229,6 +238,39 @@ func (tv *TemplateVariables) Merge(other *TemplateVariables) {
 	})
 }
 
+// ApplyFrontMatter defines the optional variables of a template's front matter the request
+// does not, and returns the required ones it still lacks
+func (tv *TemplateVariables) ApplyFrontMatter(frontMatter *config.FrontMatter) []string {
+	if tv.variables == nil {
+		tv.variables = config.NewVariables()
+	}
+	frontMatter.ApplyDefaults(tv.variables)
+	return frontMatter.MissingVariables(tv.variables)
+}
+
+// ApplyProfile sets the request's variables over those of a config profile, so the profile
+// defines the ones the request does not; maps are merged key by key
+func (tv *TemplateVariables) ApplyProfile(profile *config.Config) {
+	if profile == nil {
+		return
+	}
+	vars := config.NewVariables()
+	vars.Merge(profile.Variables.VariableSet)
+	if tv.variables != nil {
+		vars.Merge(tv.variables.VariableSet)
+	}
+	tv.variables = vars
+}
+
+// ApplySchemaDefaults defines the variables with a default in a template's schema
+// the request does not
+func (tv *TemplateVariables) ApplySchemaDefaults(schema *config.Schema) {
+	if tv.variables == nil {
+		tv.variables = config.NewVariables()
+	}
+	schema.ApplyDefaults(tv.variables)
+}
+
 // Helper functions
 
 // convertInterfaceToVariable converts an interface{} to config.Variable
@@ -235,6 +235,26 @@ func TestTemplateVariables_Flatten(t *testing.T) {
 	})
 }
 
+func TestTemplateVariables_TemplateData(t *testing.T) {
+	input := map[string]interface{}{
+		"user":   map[string]interface{}{"name": "John", "admin": true},
+		"tags":   []interface{}{"tag1", "tag2"},
+		"visits": 3,
+	}
+
+	tv, err := generation.NewTemplateVariablesFromMap(input)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"user":   map[string]interface{}{"name": "John", "admin": true},
+		"tags":   []interface{}{"tag1", "tag2"},
+		"visits": 3,
+	}, tv.TemplateData())
+
+	var empty *generation.TemplateVariables
+	assert.Empty(t, empty.TemplateData())
+}
+
 func TestTemplateVariables_GetAndSet(t *testing.T) {
 	t.Run("get existing variable", func(t *testing.T) {
 		vars := config.NewVariables()
@@ -542,3 +562,47 @@ func TestTemplateVariables_EdgeCases(t *testing.T) {
 		assert.Equal(t, "true", flattened["bool_val"])
 	})
 }
+
+func TestTemplateVariables_ApplySchemaDefaults(t *testing.T) {
+	tv, err := generation.NewTemplateVariablesFromMap(map[string]interface{}{
+		"client": map[string]interface{}{"name": "ACME"},
+	})
+	require.NoError(t, err)
+
+	schema := &config.Schema{
+		Type: "object",
+		Properties: map[string]*config.Schema{
+			"currency": {Type: "string", Default: "EUR"},
+			"client": {Type: "object", Properties: map[string]*config.Schema{
+				"name":    {Type: "string", Default: "Unknown"},
+				"country": {Type: "string", Default: "BR"},
+			}},
+		},
+	}
+	tv.ApplySchemaDefaults(schema)
+
+	flattened := tv.Flatten()
+	assert.Equal(t, "EUR", flattened["currency"])
+	assert.Equal(t, "ACME", flattened["client.name"])
+	assert.Equal(t, "BR", flattened["client.country"])
+	assert.Empty(t, schema.Validate(tv.TemplateData()))
+}
+
+func TestTemplateVariables_ApplyProfile(t *testing.T) {
+	tv, err := generation.NewTemplateVariablesFromMap(map[string]interface{}{
+		"client": map[string]interface{}{"name": "ACME"},
+		"stage":  "review",
+	})
+	require.NoError(t, err)
+
+	profile, err := config.NewConfigFromYAML([]byte("variables: {watermark: DRAFT, stage: draft, client: {name: Unknown, country: BR}}"))
+	require.NoError(t, err)
+	tv.ApplyProfile(profile)
+
+	assert.Equal(t, map[string]string{
+		"watermark":      "DRAFT",
+		"stage":          "review",
+		"client.name":    "ACME",
+		"client.country": "BR",
+	}, tv.Flatten())
+}
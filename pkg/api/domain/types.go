@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/BuddhiLW/AutoPDF/pkg/api"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // PDFMetadata is now defined in domain/generation package
@@ -36,6 +37,7 @@ type VariableResolutionError struct {
 	Code    string
 	Message string
 	Details *api.ErrorDetails
+	Fields  []config.FieldError // The variables the template's schema rejects
 }
 
 func (e VariableResolutionError) Error() string {
@@ -49,6 +51,8 @@ const (
 	ErrCodeEngineNotFound          = "ENGINE_NOT_FOUND"
 	ErrCodeOutputPathInvalid       = "OUTPUT_PATH_INVALID"
 	ErrCodeVariableInvalid         = "VARIABLE_INVALID"
+	ErrCodeVariableMissing         = "VARIABLE_MISSING"
+	ErrCodeVariableSchemaMismatch  = "VARIABLE_SCHEMA_MISMATCH"
 	ErrCodePDFGenerationFailed     = "PDF_GENERATION_FAILED"
 	ErrCodePDFValidationFailed     = "PDF_VALIDATION_FAILED"
 	ErrCodeTimeoutExceeded         = "TIMEOUT_EXCEEDED"
@@ -4,6 +4,7 @@
 package api
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
@@ -83,6 +84,15 @@ func (ed *ErrorDetails) WithTemplatePath(path string) *ErrorDetails {
 	return ed
 }
 
+// WithTemplateLocation adds the file, line, column and text of the template line an error is in
+func (ed *ErrorDetails) WithTemplateLocation(path string, line, column int, snippet string) *ErrorDetails {
+	ed.FilePath = path
+	ed.Context[ContextKeyLine] = strconv.Itoa(line)
+	ed.Context[ContextKeyColumn] = strconv.Itoa(column)
+	ed.Context[ContextKeySnippet] = snippet
+	return ed
+}
+
 // WithOutputPath adds output path information to the error details
 func (ed *ErrorDetails) WithOutputPath(path string) *ErrorDetails {
 	ed.Context[ContextKeyOutputPath] = path
@@ -14,6 +14,7 @@ import (
 	"github.com/BuddhiLW/AutoPDF/pkg/api/builders"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain/generation"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/services"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
 )
 
 // WatchModeExample demonstrates how to use watch mode with PDF generation
@@ -116,8 +117,10 @@ func WatchModeExample() {
 // Mock services for demonstration
 type MockTemplateService struct{}
 
-func (m *MockTemplateService) Process(ctx context.Context, templatePath string, variables map[string]string) (string, error) {
-	return "\\documentclass{article}\n\\begin{document}\n\\title{" + variables["title"] + "}\n\\author{" + variables["author"] + "}\n\\maketitle\n" + variables["content"] + "\n\\end{document}", nil
+func (m *MockTemplateService) Process(ctx context.Context, templatePath string, variables *generation.TemplateVariables, options generation.TemplateOptions) (string, error) {
+	data := variables.TemplateData()
+	return fmt.Sprintf("\\documentclass{article}\n\\begin{document}\n\\title{%v}\n\\author{%v}\n\\maketitle\n%v\n\\end{document}",
+		data["title"], data["author"], data["content"]), nil
 }
 
 func (m *MockTemplateService) ValidateTemplate(templatePath string) error {
@@ -128,6 +131,10 @@ func (m *MockTemplateService) GetTemplateVariables(templatePath string) ([]strin
 	return []string{"title", "author", "content"}, nil
 }
 
+func (m *MockTemplateService) Lint(templatePath string, variables *generation.TemplateVariables, delimiters config.Delimiters) ([]generation.Diagnostic, error) {
+	return nil, nil
+}
+
 type MockVariableResolver struct{}
 
 func (m *MockVariableResolver) Resolve(variables *generation.TemplateVariables) (map[string]string, error) {
@@ -6,14 +6,17 @@ package rest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/BuddhiLW/AutoPDF/pkg/api/application"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/builders"
 	apiconfig "github.com/BuddhiLW/AutoPDF/pkg/api/config"
+	"github.com/BuddhiLW/AutoPDF/pkg/api/domain"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/domain/generation"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/factories"
 	"github.com/BuddhiLW/AutoPDF/pkg/api/middleware"
@@ -63,6 +66,7 @@ func (api *PDFGenerationAPI) Routes() chi.Router {
 	// Template endpoints
 	r.Post("/templates/validate", api.ValidateTemplate)
 	r.Get("/templates/variables", api.GetTemplateVariables)
+	r.Post("/templates/lint", api.LintTemplate)
 
 	// Health and info endpoints
 	r.Get("/health", api.HealthCheck)
@@ -93,15 +97,19 @@ type PDFGenerationStructRequest struct {
 
 // PDFGenerationOptions represents PDF generation options
 type PDFGenerationOptions struct {
-	Engine       string                `json:"engine,omitempty"`        // pdflatex, xelatex, lualatex
+	Engine       string                `json:"engine,omitempty"`        // pdflatex, xelatex, lualatex, tectonic, typst
 	OutputFormat string                `json:"output_format,omitempty"` // pdf, png, jpeg, svg
 	Conversion   RESTConversionOptions `json:"conversion,omitempty"`
 	Debug        bool                  `json:"debug,omitempty"`
 	Cleanup      bool                  `json:"cleanup,omitempty"`
 	Timeout      int                   `json:"timeout,omitempty"`     // seconds
 	WatchMode    bool                  `json:"watch_mode,omitempty"`  // Enable file watching
-	Passes       int                   `json:"passes,omitempty"`      // Number of compilation passes (1-10)
+	Passes       int                   `json:"passes,omitempty"`      // Number of compilation passes (1-10); with auto_passes, the cap
+	AutoPasses   bool                  `json:"auto_passes,omitempty"` // Rerun only until converged, up to passes
 	UseLatexmk   bool                  `json:"use_latexmk,omitempty"` // Whether to use latexmk
+	Delimiters   config.Delimiters     `json:"delimiters,omitempty"`  // Template action delimiters, e.g. {"left": "<<", "right": ">>"}
+	Strict       bool                  `json:"strict,omitempty"`      // Fail on variables the template uses and the request does not define
+	Profile      string                `json:"profile,omitempty"`     // Profile of the server's config filling in unset options and variables
 }
 
 // RESTConversionOptions represents conversion options for images in REST API
@@ -115,13 +123,16 @@ type RESTConversionOptions struct {
 
 // PDFGenerationResponse represents the response from PDF generation
 type PDFGenerationResponse struct {
-	Success     bool              `json:"success"`
-	RequestID   string            `json:"request_id"`
-	Message     string            `json:"message,omitempty"`
-	Files       []GeneratedFile   `json:"files,omitempty"`
-	Metadata    map[string]string `json:"metadata,omitempty"`
-	DownloadURL string            `json:"download_url,omitempty"`
-	WatchMode   bool              `json:"watch_mode,omitempty"` // Indicates if watch mode is active
+	Success     bool                    `json:"success"`
+	RequestID   string                  `json:"request_id"`
+	Message     string                  `json:"message,omitempty"`
+	Files       []GeneratedFile         `json:"files,omitempty"`
+	Metadata    map[string]string       `json:"metadata,omitempty"`
+	DownloadURL string                  `json:"download_url,omitempty"`
+	WatchMode   bool                    `json:"watch_mode,omitempty"`   // Indicates if watch mode is active
+	Diagnostics []generation.Diagnostic `json:"diagnostics,omitempty"`  // Parsed LaTeX log: errors, warnings, boxes, undefined refs
+	FieldErrors []config.FieldError     `json:"field_errors,omitempty"` // Variables the template's schema rejects, e.g. items[2].price
+	OutputPath  string                  `json:"output_path,omitempty"`  // Where the PDF was written, rendered from the config's output pattern
 }
 
 // GeneratedFile represents a generated file
@@ -163,6 +174,23 @@ type TemplateValidationResponse struct {
 	Warnings []string `json:"warnings,omitempty"`
 }
 
+// TemplateLintRequest represents a request to lint a template
+// Without variables, the checks depending on them are skipped
+type TemplateLintRequest struct {
+	TemplatePath string                 `json:"template_path"`
+	Variables    map[string]interface{} `json:"variables,omitempty"`
+	Delimiters   config.Delimiters      `json:"delimiters,omitempty"`
+}
+
+// TemplateLintResponse represents the diagnostics of a template
+type TemplateLintResponse struct {
+	Valid       bool                    `json:"valid"` // No error-level diagnostic was found
+	Errors      int                     `json:"errors"`
+	Warnings    int                     `json:"warnings"`
+	Diagnostics []generation.Diagnostic `json:"diagnostics"`
+	Message     string                  `json:"message,omitempty"`
+}
+
 // TemplateVariablesResponse represents template variables
 type TemplateVariablesResponse struct {
 	Variables []string `json:"variables"`
@@ -188,6 +216,198 @@ type FormatsResponse struct {
 	Default string   `json:"default"`
 }
 
+// schemaFieldErrors returns the variables the template's schema rejected, when that failed the generation
+func schemaFieldErrors(err error) []config.FieldError {
+	var variableErr domain.VariableResolutionError
+	if errors.As(err, &variableErr) {
+		return variableErr.Fields
+	}
+	return nil
+}
+
+// profileConfig returns the fields and variables of the profile of the server's config
+// a request selects, or nil when it selects none
+func (api *PDFGenerationAPI) profileConfig(options *PDFGenerationOptions) (*config.Config, error) {
+	if options == nil || options.Profile == "" {
+		return nil, nil
+	}
+	if api.config == nil {
+		return nil, fmt.Errorf("unknown profile %q: the server has no config", options.Profile)
+	}
+	profile, ok := api.config.Profiles[options.Profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q: use one of %s", options.Profile, strings.Join(api.config.ProfileNames(), ", "))
+	}
+	return profile.Config()
+}
+
+// withSettings fills in the options a request leaves unset from its profile and its template's
+// front matter, resolved as the CLI resolves them: the front matter's settings, then those the
+// selected profile sets over them, except for the delimiters the template is written with.
+// The engine, the passes (0), the delimiters and the first conversion format are filled in,
+// and auto_passes, use_latexmk and strict can be enabled
+// Passes nothing sets stay 0. Options are created for a request without any when there are settings
+func withSettings(options *PDFGenerationOptions, profile *config.Config, frontMatter *config.FrontMatter) *PDFGenerationOptions {
+	if profile == nil && frontMatter == nil {
+		return options
+	}
+
+	var settings PDFGenerationOptions
+	if frontMatter != nil {
+		settings.Engine = frontMatter.Engine.String()
+		settings.Passes = frontMatter.Passes
+		if frontMatter.UseLatexmk != nil {
+			settings.UseLatexmk = *frontMatter.UseLatexmk
+		}
+		settings.Delimiters = frontMatter.Delimiters
+	}
+	if profile != nil {
+		if profile.Engine != "" {
+			settings.Engine = profile.Engine.String()
+		}
+		if profile.Passes > 0 {
+			settings.Passes = profile.Passes
+		}
+		if profile.Sets("use_latexmk") {
+			settings.UseLatexmk = profile.UseLatexmk
+		}
+		settings.AutoPasses = profile.AutoPasses
+		settings.Strict = profile.Strict
+		if !settings.Delimiters.IsSet() {
+			settings.Delimiters = profile.Delimiters
+		}
+		if profile.Conversion.Enabled && len(profile.Conversion.Formats) > 0 {
+			settings.Conversion.DoConvert = true
+			settings.Conversion.Format = profile.Conversion.Formats[0]
+		}
+	}
+
+	if options == nil {
+		options = &PDFGenerationOptions{}
+	}
+	if options.Engine == "" {
+		options.Engine = settings.Engine
+	}
+	if options.Passes == 0 {
+		options.Passes = settings.Passes
+	}
+	options.AutoPasses = options.AutoPasses || settings.AutoPasses
+	options.UseLatexmk = options.UseLatexmk || settings.UseLatexmk
+	options.Strict = options.Strict || settings.Strict
+	if !options.Delimiters.IsSet() {
+		options.Delimiters = settings.Delimiters
+	}
+	if !options.Conversion.DoConvert && settings.Conversion.DoConvert {
+		options.Conversion.DoConvert = true
+		options.Conversion.Format = settings.Conversion.Format
+	}
+	return options
+}
+
+// outputPattern returns the output pattern of the server's config and its collision policy,
+// the profile's taking precedence. A fixed output is not used, as every request would write
+// the same file; requests then build into a private directory
+func (api *PDFGenerationAPI) outputPattern(profile *config.Config) (string, string) {
+	for _, cfg := range []*config.Config{profile, api.config} {
+		if cfg != nil && cfg.Output.IsPattern() {
+			collision := cfg.OutputCollision
+			if collision == "" && api.config != nil {
+				collision = api.config.OutputCollision
+			}
+			return cfg.Output.String(), collision
+		}
+	}
+	return "", ""
+}
+
+// preparedRequest is a generation request with the template's front matter, the selected
+// profile, the schema and the output pattern applied
+type preparedRequest struct {
+	request generation.PDFGenerationRequest
+	options *PDFGenerationOptions // The request's options, with the unset ones filled in
+	profile *config.Config        // The selected profile, nil without one
+}
+
+// prepareRequest applies the request's options to the builder, which holds its template and
+// variables, filling in the unset ones from the template's front matter and the selected profile,
+// and adds the template's assets, schema and the server's output pattern
+// The error is the message of a bad request
+func (api *PDFGenerationAPI) prepareRequest(requestID, templatePath string, options *PDFGenerationOptions, builder *builders.PDFGenerationRequestBuilder) (preparedRequest, error) {
+	frontMatter, err := config.ReadFrontMatter(templatePath)
+	if err != nil {
+		return preparedRequest{}, fmt.Errorf("Invalid template front matter: %v", err)
+	}
+	profile, err := api.profileConfig(options)
+	if err != nil {
+		return preparedRequest{}, fmt.Errorf("Invalid profile: %v", err)
+	}
+	options = withSettings(options, profile, frontMatter)
+
+	schema, err := config.ReadSchema(templatePath)
+	if err != nil {
+		return preparedRequest{}, fmt.Errorf("Invalid variable schema: %v", err)
+	}
+
+	// Apply options if provided
+	if options != nil {
+		// Passes default to 1; with auto_passes, 0 leaves the cap to the default
+		if options.Passes == 0 && !options.AutoPasses {
+			options.Passes = 1
+		}
+		if options.Passes < 0 || options.Passes > 10 {
+			return preparedRequest{}, errors.New("passes must be between 1 and 10")
+		}
+		if err := options.Delimiters.Validate(); err != nil {
+			return preparedRequest{}, err
+		}
+
+		if options.Engine != "" {
+			builder = builder.WithEngine(options.Engine)
+		}
+		if options.Delimiters.IsSet() {
+			builder = builder.WithDelimiters(options.Delimiters.Left, options.Delimiters.Right)
+		}
+		builder = builder.WithPasses(options.Passes).
+			WithAutoPasses(options.AutoPasses).
+			WithLatexmk(options.UseLatexmk).
+			WithStrict(options.Strict)
+		if options.Debug {
+			builder = builder.WithDebug(generation.DebugOptions{
+				Enabled:            true,
+				LogToFile:          true,
+				CreateConcreteFile: true,
+				RequestID:          requestID,
+			})
+		}
+		if options.Timeout > 0 {
+			builder = builder.WithTimeout(time.Duration(options.Timeout) * time.Second)
+		}
+		if options.Conversion.DoConvert {
+			builder = builder.WithConversion(true, options.Conversion.Format)
+		}
+		if options.WatchMode {
+			builder = builder.WithWatchMode(true)
+		}
+	}
+
+	if frontMatter != nil {
+		builder = builder.WithAssets(frontMatter.Assets...)
+	}
+	if schema != nil {
+		builder = builder.WithSchema(schema)
+	}
+	if pattern, collision := api.outputPattern(profile); pattern != "" {
+		builder = builder.WithOutput(pattern).WithOutputCollision(collision)
+	}
+	pdfRequest := builder.Build()
+	pdfRequest.Variables.ApplyProfile(profile)
+	pdfRequest.Variables.ApplySchemaDefaults(schema)
+	if missing := pdfRequest.Variables.ApplyFrontMatter(frontMatter); len(missing) > 0 {
+		return preparedRequest{}, fmt.Errorf("template requires variables the request does not define: %s", strings.Join(missing, ", "))
+	}
+	return preparedRequest{request: pdfRequest, options: options, profile: profile}, nil
+}
+
 // GeneratePDF generates a PDF synchronously
 // POST /api/v1/pdf/generate
 func (api *PDFGenerationAPI) GeneratePDF(w http.ResponseWriter, r *http.Request) {
@@ -218,42 +438,17 @@ func (api *PDFGenerationAPI) GeneratePDF(w http.ResponseWriter, r *http.Request)
 	builder := builders.NewPDFGenerationRequestBuilder().
 		WithTemplate(req.TemplatePath).
 		WithVariables(req.Variables)
-
-	// Apply options if provided
-	if req.Options != nil {
-		// Validate passes field
-		if req.Options.Passes < 1 || req.Options.Passes > 10 {
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, PDFGenerationResponse{
-				Success: false,
-				Message: "passes must be between 1 and 10",
-			})
-			return
-		}
-
-		if req.Options.Engine != "" {
-			builder = builder.WithEngine(req.Options.Engine)
-		}
-		if req.Options.Debug {
-			builder = builder.WithDebug(generation.DebugOptions{
-				Enabled:            true,
-				LogToFile:          true,
-				CreateConcreteFile: true,
-				RequestID:          requestID,
-			})
-		}
-		if req.Options.Timeout > 0 {
-			builder = builder.WithTimeout(time.Duration(req.Options.Timeout) * time.Second)
-		}
-		if req.Options.Conversion.DoConvert {
-			builder = builder.WithConversion(true, req.Options.Conversion.Format)
-		}
-		if req.Options.WatchMode {
-			builder = builder.WithWatchMode(true)
-		}
+	prepared, err := api.prepareRequest(requestID, req.TemplatePath, req.Options, builder)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, PDFGenerationResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
 	}
-
-	pdfRequest := builder.Build()
+	req.Options = prepared.options
+	profile, pdfRequest := prepared.profile, prepared.request
 
 	// Generate PDF
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
@@ -261,11 +456,18 @@ func (api *PDFGenerationAPI) GeneratePDF(w http.ResponseWriter, r *http.Request)
 
 	result, err := api.appService.GeneratePDF(ctx, pdfRequest)
 	if err != nil {
-		render.Status(r, http.StatusInternalServerError)
+		fieldErrors := schemaFieldErrors(err)
+		if len(fieldErrors) > 0 {
+			render.Status(r, http.StatusUnprocessableEntity)
+		} else {
+			render.Status(r, http.StatusInternalServerError)
+		}
 		render.JSON(w, r, PDFGenerationResponse{
-			Success:   false,
-			RequestID: requestID,
-			Message:   fmt.Sprintf("PDF generation failed: %v", err),
+			Success:     false,
+			RequestID:   requestID,
+			Message:     fmt.Sprintf("PDF generation failed: %v", err),
+			Diagnostics: result.Diagnostics,
+			FieldErrors: fieldErrors,
 		})
 		return
 	}
@@ -288,7 +490,13 @@ func (api *PDFGenerationAPI) GeneratePDF(w http.ResponseWriter, r *http.Request)
 			"generated_at": result.Metadata.GeneratedAt.Format(time.RFC3339),
 			"engine":       result.Metadata.Engine,
 		},
-		WatchMode: req.Options != nil && req.Options.WatchMode,
+		WatchMode:   req.Options != nil && req.Options.WatchMode,
+		Diagnostics: result.Diagnostics,
+		OutputPath:  result.PDFPath,
+	}
+
+	if profile != nil {
+		response.Metadata["profile"] = req.Options.Profile
 	}
 
 	// Add conversion files if requested
@@ -345,42 +553,17 @@ func (api *PDFGenerationAPI) GeneratePDFFromStruct(w http.ResponseWriter, r *htt
 	builder := builders.NewPDFGenerationRequestBuilder().
 		WithTemplate(req.TemplatePath).
 		WithVariablesFromStruct(req.Data) // Convert struct to TemplateVariables
-
-	// Apply options if provided
-	if req.Options != nil {
-		// Validate passes field
-		if req.Options.Passes < 1 || req.Options.Passes > 10 {
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, PDFGenerationResponse{
-				Success: false,
-				Message: "passes must be between 1 and 10",
-			})
-			return
-		}
-
-		if req.Options.Engine != "" {
-			builder = builder.WithEngine(req.Options.Engine)
-		}
-		if req.Options.Debug {
-			builder = builder.WithDebug(generation.DebugOptions{
-				Enabled:            true,
-				LogToFile:          true,
-				CreateConcreteFile: true,
-				RequestID:          requestID,
-			})
-		}
-		if req.Options.Timeout > 0 {
-			builder = builder.WithTimeout(time.Duration(req.Options.Timeout) * time.Second)
-		}
-		if req.Options.Conversion.DoConvert {
-			builder = builder.WithConversion(true, req.Options.Conversion.Format)
-		}
-		if req.Options.WatchMode {
-			builder = builder.WithWatchMode(true)
-		}
+	prepared, err := api.prepareRequest(requestID, req.TemplatePath, req.Options, builder)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, PDFGenerationResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
 	}
-
-	pdfRequest := builder.Build()
+	req.Options = prepared.options
+	profile, pdfRequest := prepared.profile, prepared.request
 
 	// Generate PDF
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
@@ -388,11 +571,18 @@ func (api *PDFGenerationAPI) GeneratePDFFromStruct(w http.ResponseWriter, r *htt
 
 	result, err := api.appService.GeneratePDF(ctx, pdfRequest)
 	if err != nil {
-		render.Status(r, http.StatusInternalServerError)
+		fieldErrors := schemaFieldErrors(err)
+		if len(fieldErrors) > 0 {
+			render.Status(r, http.StatusUnprocessableEntity)
+		} else {
+			render.Status(r, http.StatusInternalServerError)
+		}
 		render.JSON(w, r, PDFGenerationResponse{
-			Success:   false,
-			RequestID: requestID,
-			Message:   fmt.Sprintf("PDF generation failed: %v", err),
+			Success:     false,
+			RequestID:   requestID,
+			Message:     fmt.Sprintf("PDF generation failed: %v", err),
+			Diagnostics: result.Diagnostics,
+			FieldErrors: fieldErrors,
 		})
 		return
 	}
@@ -416,7 +606,13 @@ func (api *PDFGenerationAPI) GeneratePDFFromStruct(w http.ResponseWriter, r *htt
 			"generated_at": result.Metadata.GeneratedAt.Format(time.RFC3339),
 			"struct_type":  fmt.Sprintf("%T", req.Data),
 		},
-		WatchMode: pdfRequest.Options.WatchMode,
+		WatchMode:   pdfRequest.Options.WatchMode,
+		Diagnostics: result.Diagnostics,
+		OutputPath:  result.PDFPath,
+	}
+
+	if profile != nil {
+		response.Metadata["profile"] = req.Options.Profile
 	}
 
 	// Add image files if conversion was requested
@@ -605,6 +801,57 @@ func (api *PDFGenerationAPI) GetTemplateVariables(w http.ResponseWriter, r *http
 	render.JSON(w, r, response)
 }
 
+// LintTemplate checks a template for mistakes without compiling it
+// POST /api/v1/pdf/templates/lint
+func (api *PDFGenerationAPI) LintTemplate(w http.ResponseWriter, r *http.Request) {
+	var req TemplateLintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, TemplateLintResponse{Message: fmt.Sprintf("Invalid request body: %v", err)})
+		return
+	}
+
+	if req.TemplatePath == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, TemplateLintResponse{Message: "template_path is required"})
+		return
+	}
+	if err := req.Delimiters.Validate(); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, TemplateLintResponse{Message: err.Error()})
+		return
+	}
+
+	var variables *generation.TemplateVariables
+	if req.Variables != nil {
+		var err error
+		variables, err = generation.NewTemplateVariablesFromMap(req.Variables)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, TemplateLintResponse{Message: fmt.Sprintf("Invalid variables: %v", err)})
+			return
+		}
+	}
+
+	diagnostics, err := api.appService.LintTemplate(req.TemplatePath, variables, req.Delimiters)
+	if err != nil {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, TemplateLintResponse{Message: err.Error()})
+		return
+	}
+
+	response := TemplateLintResponse{Diagnostics: diagnostics}
+	for _, d := range diagnostics {
+		if d.Severity == "error" {
+			response.Errors++
+		} else {
+			response.Warnings++
+		}
+	}
+	response.Valid = response.Errors == 0
+	render.JSON(w, r, response)
+}
+
 // HealthCheck provides a health check endpoint
 // GET /api/v1/pdf/health
 func (api *PDFGenerationAPI) HealthCheck(w http.ResponseWriter, r *http.Request) {
@@ -621,7 +868,7 @@ func (api *PDFGenerationAPI) HealthCheck(w http.ResponseWriter, r *http.Request)
 // GET /api/v1/pdf/engines
 func (api *PDFGenerationAPI) GetSupportedEngines(w http.ResponseWriter, r *http.Request) {
 	response := EnginesResponse{
-		Engines: []string{"pdflatex", "xelatex", "lualatex"},
+		Engines: api.appService.GetSupportedEngines(),
 		Default: "pdflatex",
 	}
 
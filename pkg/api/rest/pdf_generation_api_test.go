@@ -0,0 +1,123 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/logger"
+	"github.com/BuddhiLW/AutoPDF/pkg/api/factories"
+	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngineScript stands in for pdflatex: the PDF it writes into -output-directory
+// holds the source it was given, so tests can read what was compiled
+const fakeEngineScript = `#!/bin/sh
+for arg in "$@"; do
+	case "$arg" in
+		-jobname=*) job="${arg#-jobname=}" ;;
+		-output-directory=*) out="${arg#-output-directory=}" ;;
+	esac
+	source="$arg"
+done
+{ printf '%%PDF-1.5\n'; cat "$source"; } > "${out:-.}/$job.pdf"
+`
+
+// generate posts a generation request for the template to the API and returns the compiled source
+func generate(t *testing.T, api *PDFGenerationAPI, body map[string]interface{}) (PDFGenerationResponse, string) {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	api.Routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/generate", strings.NewReader(string(payload))))
+
+	var response PDFGenerationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.True(t, response.Success, response.Message)
+	require.NotEmpty(t, response.OutputPath)
+
+	pdf, err := os.ReadFile(response.OutputPath)
+	require.NoError(t, err)
+	return response, strings.TrimPrefix(string(pdf), "%PDF-1.5\n")
+}
+
+// newTestAPI returns an API compiling with the fake engine
+func newTestAPI(t *testing.T, cfg *config.Config) *PDFGenerationAPI {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engine is a POSIX shell script")
+	}
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "pdflatex"), []byte(fakeEngineScript), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	factory := factories.NewPDFGenerationServiceFactory(cfg, logger.NewLoggerAdapter(logger.Silent, "stderr"), false)
+	return &PDFGenerationAPI{appService: factory.CreateApplicationService(), config: cfg}
+}
+
+func TestGeneratePDF_RendersTheTemplateOnce(t *testing.T) {
+	outDir := t.TempDir()
+	api := newTestAPI(t, &config.Config{Output: config.Output(filepath.Join(outDir, "letter-{{.id}}.pdf"))})
+
+	templatePath := filepath.Join(t.TempDir(), "letter.tex")
+	require.NoError(t, os.WriteFile(templatePath, []byte(`\documentclass{article}
+\begin{document}
+delim[[.greeting]]
+\end{document}
+`), 0644))
+
+	_, source := generate(t, api, map[string]interface{}{
+		"template_path": templatePath,
+		"options":       map[string]interface{}{"engine": "pdflatex"},
+		"variables": map[string]interface{}{
+			"id":       "1",
+			"greeting": "delim[[raw .payload]] & more",
+			"payload":  `\input{/etc/passwd}`,
+		},
+	})
+
+	// The value's action is printed, not evaluated by a second rendering
+	assert.Contains(t, source, `delim[[raw .payload]] \& more`)
+	assert.NotContains(t, source, `/etc/passwd`)
+}
+
+func TestGeneratePDF_ProfileWinsOverFrontMatter(t *testing.T) {
+	cfg, err := config.NewConfigFromYAML([]byte(`
+profiles:
+  final: {engine: pdflatex, passes: 1}
+`))
+	require.NoError(t, err)
+	cfg.Output = config.Output(filepath.Join(t.TempDir(), "{{.id}}.pdf"))
+	api := newTestAPI(t, cfg)
+
+	// Only pdflatex is faked, so the build fails with the front matter's engine
+	templatePath := filepath.Join(t.TempDir(), "letter.tex")
+	require.NoError(t, os.WriteFile(templatePath, []byte(`% ---
+% engine: xelatex
+% passes: 2
+% ---
+\documentclass{article}
+\begin{document}
+delim[[.id]]
+\end{document}
+`), 0644))
+
+	_, source := generate(t, api, map[string]interface{}{
+		"template_path": templatePath,
+		"options":       map[string]interface{}{"profile": "final"},
+		"variables":     map[string]interface{}{"id": "1"},
+	})
+
+	assert.Contains(t, source, "\n1\n")
+}
@@ -65,6 +65,7 @@ func (s *PDFGenerationAPIService) GeneratePDF(ctx context.Context, templatePath
 		WithCleanup(false). // Don't clean for API usage
 		WithTimeout(30 * time.Second).
 		WithPasses(s.config.Passes).
+		WithAutoPasses(s.config.AutoPasses).
 		WithLatexmk(s.config.UseLatexmk).
 		Build()
 
@@ -116,6 +117,7 @@ func (s *PDFGenerationAPIService) GeneratePDFWithOptions(ctx context.Context, op
 		WithVerbose(verboseLevel).
 		WithDebug(debugOptions).
 		WithPasses(options.Passes).
+		WithAutoPasses(options.AutoPasses).
 		WithLatexmk(options.UseLatexmk).
 		Build()
 
@@ -159,6 +161,7 @@ func (s *PDFGenerationAPIService) GeneratePDFFromStruct(ctx context.Context, tem
 			Enabled: s.debugEnabled,
 		}).
 		WithPasses(s.config.Passes).
+		WithAutoPasses(s.config.AutoPasses).
 		WithLatexmk(s.config.UseLatexmk).
 		Build()
 
@@ -213,6 +216,7 @@ func (s *PDFGenerationAPIService) GeneratePDFFromStructWithWorkingDir(ctx contex
 			Enabled: s.debugEnabled,
 		}).
 		WithPasses(s.config.Passes).
+		WithAutoPasses(s.config.AutoPasses).
 		WithLatexmk(s.config.UseLatexmk).
 		Build()
 
@@ -259,6 +263,12 @@ func (s *PDFGenerationAPIService) GetTemplateVariables(templatePath string) ([]s
 	return s.appService.GetTemplateVariables(templatePath)
 }
 
+// LintTemplate checks a template without rendering it
+// Nil variables skip the checks depending on them, such as undefined and unused variables
+func (s *PDFGenerationAPIService) LintTemplate(templatePath string, variables *generation.TemplateVariables, delimiters config.Delimiters) ([]generation.Diagnostic, error) {
+	return s.appService.LintTemplate(templatePath, variables, delimiters)
+}
+
 // GetSupportedEngines returns supported LaTeX engines
 func (s *PDFGenerationAPIService) GetSupportedEngines() []string {
 	return s.appService.GetSupportedEngines()
@@ -280,7 +290,8 @@ type PDFGenerationOptions struct {
 	Timeout      time.Duration
 	Verbose      bool
 	Debug        bool
-	Passes       int  // Number of compilation passes
+	Passes       int  // Number of compilation passes (the cap when AutoPasses is set)
+	AutoPasses   bool // Stop rerunning once cross-references have converged
 	UseLatexmk   bool // Whether to use latexmk
 }
 
@@ -374,6 +385,12 @@ func (o *PDFGenerationOptions) WithPasses(passes int) *PDFGenerationOptions {
 	return o
 }
 
+// WithAutoPasses enables rerun detection, using Passes as the cap
+func (o *PDFGenerationOptions) WithAutoPasses(enabled bool) *PDFGenerationOptions {
+	o.AutoPasses = enabled
+	return o
+}
+
 // WithLatexmk enables latexmk compilation
 func (o *PDFGenerationOptions) WithLatexmk(enabled bool) *PDFGenerationOptions {
 	o.UseLatexmk = enabled
@@ -66,7 +66,7 @@ func (m *WatchModeManager) StartWatchMode(ctx context.Context, req generation.PD
 		ConfigFile:        "autopdf.yaml", // Default config file
 		DebounceInterval:  500 * time.Millisecond,
 		ExclusionPatterns: []string{"*.aux", "*.log", "*.out", "*.toc", "*.fdb_latexmk", "*.fls", "*.synctex.gz"},
-		InclusionPatterns: []string{"*.tex", "*.yaml", "*.yml"},
+		InclusionPatterns: []string{"*.tex", "*.typ", "*.yaml", "*.yml"},
 	}
 
 	// Create context for this watch instance
@@ -0,0 +1,27 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"github.com/BuddhiLW/AutoPDF/internal/autopdf/application/adapters/template"
+)
+
+// RegisterTemplateFunc makes fn callable as name from every template AutoPDF renders,
+// through GeneratePDF, the REST service and the CLI alike
+// fn must return one value, or a value and an error failing the build when non-nil.
+// A function registered under the name of a library function replaces it;
+// escape, raw and latex are reserved. Register functions before rendering starts
+func RegisterTemplateFunc(name string, fn interface{}) error {
+	return template.RegisterFunc(name, fn)
+}
+
+// RegisterTemplateFuncs registers several functions, stopping at the first invalid one
+func RegisterTemplateFuncs(funcs map[string]interface{}) error {
+	for name, fn := range funcs {
+		if err := RegisterTemplateFunc(name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
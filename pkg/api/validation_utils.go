@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	autopdfports "github.com/BuddhiLW/AutoPDF/internal/autopdf/application/ports"
 )
 
 // ValidationUtils provides reusable validation functions
@@ -167,11 +169,12 @@ func (vu *ValidationUtils) ValidateTemplatePath(templatePath string) (*ErrorDeta
 			nil
 	}
 
-	// Check file extension
-	if !strings.HasSuffix(strings.ToLower(templatePath), ".tex") {
+	// Check file extension: LaTeX sources, or Typst sources for the typst engine
+	ext := strings.ToLower(filepath.Ext(templatePath))
+	if ext != autopdfports.SourceExtension(autopdfports.EnginePDFLaTeX) && ext != autopdfports.SourceExtension(autopdfports.EngineTypst) {
 		return NewErrorDetails(ErrorCategoryTemplate, ErrorSeverityHigh).
 				WithTemplatePath(templatePath).
-				WithValidation("file_extension", ".tex", filepath.Ext(templatePath)).
+				WithValidation("file_extension", ".tex or .typ", filepath.Ext(templatePath)).
 				AddContext(ContextKeyError, ErrTemplateFileInvalid),
 			nil
 	}
@@ -236,7 +239,7 @@ func (vu *ValidationUtils) ValidateEngine(engine string) (*ErrorDetails, error)
 	}
 
 	// Check if engine is supported
-	supportedEngines := []string{"pdflatex", "xelatex", "lualatex", "latex"}
+	supportedEngines := autopdfports.SupportedEngines()
 	isSupported := false
 	for _, supportedEngine := range supportedEngines {
 		if engine == supportedEngine {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rwxrob/bonzai/persisters/inyaml"
 	"gopkg.in/yaml.v3"
@@ -12,13 +14,64 @@ import (
 
 // Config defines the YAML configuration schema for AutoPDF
 type Config struct {
+	// Configs this config inherits from, see LoadConfigFile
+	Extends Extends `yaml:"extends,omitempty" json:"extends,omitempty"`
+
 	Template   Template   `yaml:"template" json:"template" default:""`
-	Output     Output     `yaml:"output" json:"output" default:""`
+	Output     Output     `yaml:"output" json:"output" default:""` // May be a template, such as out/{{.client.slug}}.pdf
 	Variables  Variables  `yaml:"variables" json:"variables" default:"{}"`
-	Engine     Engine     `yaml:"engine" json:"engine" default:"pdflatex"`
+	Engine     Engine     `yaml:"engine" json:"engine" default:"pdflatex"` // pdflatex, xelatex, lualatex, tectonic, typst
 	Conversion Conversion `yaml:"conversion" json:"conversion"`
 	Passes     int        `yaml:"passes" json:"passes" default:"1"`
+	AutoPasses bool       `yaml:"auto_passes" json:"auto_passes" default:"false"` // Rerun only until converged; Passes becomes the cap, 5 when unset
 	UseLatexmk bool       `yaml:"use_latexmk" json:"use_latexmk" default:"false"`
+
+	// What a build does when its output already exists: overwrite, fail or suffix (name-1.pdf, ...)
+	OutputCollision string `yaml:"output_collision,omitempty" json:"output_collision,omitempty" default:"overwrite"`
+
+	// Tools run between engine passes when not using latexmk: auto, none or a tool name
+	Bibliography string `yaml:"bibliography,omitempty" json:"bibliography,omitempty" default:"auto"` // bibtex, biber
+	Index        string `yaml:"index,omitempty" json:"index,omitempty" default:"auto"`               // makeindex
+	Glossary     string `yaml:"glossary,omitempty" json:"glossary,omitempty" default:"auto"`         // makeglossaries
+
+	// Directories of partial templates and layouts, relative to the config file
+	Partials []string `yaml:"partials,omitempty" json:"partials,omitempty"`
+	// Template action delimiters, replacing the engine's defaults (delim[[ ]] for LaTeX, {{ }} for Typst)
+	Delimiters Delimiters `yaml:"delimiters,omitempty" json:"delimiters,omitempty"`
+	// Fail on variables the template uses and the config does not define, instead of printing nothing
+	Strict bool `yaml:"strict,omitempty" json:"strict,omitempty" default:"false"`
+	// Types and constraints of the variables, replacing the schema file next to the template
+	Schema *Schema `yaml:"schema,omitempty" json:"schema,omitempty"`
+
+	Workspace Workspace `yaml:"workspace,omitempty" json:"workspace,omitempty"`
+	Assets    Assets    `yaml:"assets,omitempty" json:"assets,omitempty"`
+	Security  Security  `yaml:"security,omitempty" json:"security,omitempty"`
+	Cache     Cache     `yaml:"cache,omitempty" json:"cache,omitempty"`
+	Tectonic  Tectonic  `yaml:"tectonic,omitempty" json:"tectonic,omitempty"` // Used when engine is tectonic
+	Typst     Typst     `yaml:"typst,omitempty" json:"typst,omitempty"`       // Used when engine is typst
+
+	// Named overrides of any field and variable, such as draft and final, see ApplyProfile
+	Profiles map[string]Profile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	// Profile is the name of the profile applied to the config, if any
+	Profile string `yaml:"-" json:"-"`
+
+	node *yaml.Node // The YAML the config was read from, which profiles are merged over
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, keeping the YAML the config is read from
+// so a profile can be merged over it
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	c.node = value
+	return nil
+}
+
+// Sets reports whether the YAML the config was read from sets a top-level field, such as engine or passes
+func (c *Config) Sets(key string) bool {
+	return c != nil && c.node != nil && mappingIndex(c.node, key) >= 0
 }
 
 func (c *Config) String() string {
@@ -41,6 +94,11 @@ func (o Output) String() string {
 	return string(o)
 }
 
+// IsPattern reports whether the output is a template rendered with the variables
+func (o Output) IsPattern() bool {
+	return strings.Contains(string(o), "{{")
+}
+
 type Engine string
 
 func (e Engine) String() string {
@@ -50,6 +108,7 @@ func (e Engine) String() string {
 // Variables represents a collection of complex variables
 type Variables struct {
 	*VariableSet
+	lines map[string]int // Line each variable is defined on in the YAML it was read from
 }
 
 // NewVariables creates a new Variables collection
@@ -79,6 +138,15 @@ func (v Variables) String() string {
 	return s
 }
 
+// Lines returns the line each variable is defined on in the YAML the variables were read from
+func (v Variables) Lines() map[string]int {
+	lines := make(map[string]int, len(v.lines))
+	for name, line := range v.lines {
+		lines[name] = line
+	}
+	return lines
+}
+
 // GetString gets a string value by path (for backward compatibility)
 func (v Variables) GetString(path string) (string, bool) {
 	if v.VariableSet == nil {
@@ -163,6 +231,10 @@ func (v *Variables) unmarshalYAMLMap(node *yaml.Node) error {
 		value := v.convertYAMLNodeToVariable(valueNode)
 
 		v.VariableSet.Set(key, value)
+		if v.lines == nil {
+			v.lines = make(map[string]int)
+		}
+		v.lines[key] = keyNode.Line
 	}
 
 	return nil
@@ -252,6 +324,117 @@ type Conversion struct {
 	Formats []string `yaml:"formats" json:"formats" default:"[]"`
 }
 
+// Workspace configures the per-build directories and how long they are kept
+type Workspace struct {
+	Root      string `yaml:"root,omitempty" json:"root,omitempty" default:""`                 // Defaults to $TMPDIR/autopdf/workspaces
+	Retention string `yaml:"retention,omitempty" json:"retention,omitempty" default:"delete"` // delete, keep_last, keep_failed, ttl
+	KeepLast  int    `yaml:"keep_last,omitempty" json:"keep_last,omitempty" default:"0"`
+	TTL       string `yaml:"ttl,omitempty" json:"ttl,omitempty" default:""` // Go duration, e.g. "24h"
+}
+
+// Assets configures how the files a template references reach the compile directory
+type Assets struct {
+	Mode  string   `yaml:"mode,omitempty" json:"mode,omitempty" default:"symlink"` // symlink, copy
+	Extra []string `yaml:"extra,omitempty" json:"extra,omitempty"`                 // Globs relative to the template, e.g. "fonts/*.otf"
+}
+
+// Security restricts what a template can make the engine do on the host
+// Enable it wherever templates or variables come from untrusted sources
+type Security struct {
+	Sandbox     bool   `yaml:"sandbox,omitempty" json:"sandbox,omitempty" default:"false"`          // No shell escape, paranoid file access, clean environment, limits
+	ShellEscape string `yaml:"shell_escape,omitempty" json:"shell_escape,omitempty" default:"none"` // none, restricted
+	CPUTime     string `yaml:"cpu_time,omitempty" json:"cpu_time,omitempty" default:"2m"`           // Go duration, per process
+	MemoryMB    int    `yaml:"memory_mb,omitempty" json:"memory_mb,omitempty" default:"4096"`
+	FileSizeMB  int    `yaml:"file_size_mb,omitempty" json:"file_size_mb,omitempty" default:"512"`
+	InheritEnv  bool   `yaml:"inherit_env,omitempty" json:"inherit_env,omitempty" default:"false"` // Pass the caller's environment, secrets included
+}
+
+// CPUTimeDuration parses CPUTime; an empty CPUTime is zero
+func (s Security) CPUTimeDuration() (time.Duration, error) {
+	if s.CPUTime == "" {
+		return 0, nil
+	}
+	cpuTime, err := time.ParseDuration(s.CPUTime)
+	if err != nil {
+		return 0, fmt.Errorf("invalid security cpu_time %q: %w", s.CPUTime, err)
+	}
+	return cpuTime, nil
+}
+
+// Cache configures reusing the PDF of a compilation whose inputs are unchanged
+type Cache struct {
+	Enabled   bool   `yaml:"enabled,omitempty" json:"enabled,omitempty" default:"false"`
+	Dir       string `yaml:"dir,omitempty" json:"dir,omitempty" default:""` // Defaults to the user cache directory
+	MaxSizeMB int    `yaml:"max_size_mb,omitempty" json:"max_size_mb,omitempty" default:"512"`
+	MaxAge    string `yaml:"max_age,omitempty" json:"max_age,omitempty" default:"168h"` // Go duration since last use
+}
+
+// Delimiters are the strings template actions are written between, such as << and >>
+type Delimiters struct {
+	Left  string `yaml:"left,omitempty" json:"left,omitempty" default:""`
+	Right string `yaml:"right,omitempty" json:"right,omitempty" default:""`
+}
+
+// IsSet reports whether delimiters are configured
+func (d Delimiters) IsSet() bool {
+	return d.Left != "" || d.Right != ""
+}
+
+// Validate requires both delimiters, distinct and without white space, when either is set
+func (d Delimiters) Validate() error {
+	if !d.IsSet() {
+		return nil
+	}
+	if d.Left == "" || d.Right == "" {
+		return fmt.Errorf("delimiters need both left and right, got %q and %q", d.Left, d.Right)
+	}
+	if d.Left == d.Right {
+		return fmt.Errorf("left and right delimiters must differ, both are %q", d.Left)
+	}
+	if strings.ContainsAny(d.Left+d.Right, " \t\r\n") {
+		return fmt.Errorf("delimiters cannot contain white space: %q and %q", d.Left, d.Right)
+	}
+	return nil
+}
+
+// Tectonic configures the tectonic engine's support bundle
+type Tectonic struct {
+	CacheDir   string `yaml:"cache_dir,omitempty" json:"cache_dir,omitempty" default:""`          // Bundle cache; defaults to the user cache directory
+	Bundle     string `yaml:"bundle,omitempty" json:"bundle,omitempty" default:""`                // URL or path of the bundle; defaults to tectonic's
+	OnlyCached bool   `yaml:"only_cached,omitempty" json:"only_cached,omitempty" default:"false"` // Never download, e.g. on offline servers
+}
+
+// Typst configures where the typst compiler finds fonts and packages
+type Typst struct {
+	FontPaths       []string `yaml:"font_paths,omitempty" json:"font_paths,omitempty"`                          // Searched before the system fonts
+	PackagePath     string   `yaml:"package_path,omitempty" json:"package_path,omitempty" default:""`           // Local packages (@local namespace)
+	PackageCacheDir string   `yaml:"package_cache_dir,omitempty" json:"package_cache_dir,omitempty" default:""` // Downloaded packages; defaults to typst's
+}
+
+// MaxAgeDuration parses MaxAge; an empty MaxAge is zero
+func (c Cache) MaxAgeDuration() (time.Duration, error) {
+	if c.MaxAge == "" {
+		return 0, nil
+	}
+	maxAge, err := time.ParseDuration(c.MaxAge)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache max_age %q: %w", c.MaxAge, err)
+	}
+	return maxAge, nil
+}
+
+// TTLDuration parses TTL; an empty TTL is zero
+func (w Workspace) TTLDuration() (time.Duration, error) {
+	if w.TTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(w.TTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid workspace ttl %q: %w", w.TTL, err)
+	}
+	return ttl, nil
+}
+
 // GetConfig retrieves the configuration from the persister
 func GetConfig(persister *inyaml.Persister) (*Config, error) {
 	configStr := persister.Get("autopdf_config")
@@ -315,25 +498,32 @@ func NewConfigFromYAML(yamlData []byte) (*Config, error) {
 	if err := yaml.Unmarshal(yamlData, &config); err != nil {
 		return nil, err
 	}
+	config.setDefaults()
+	return &config, nil
+}
 
+// setDefaults fills in the fields a config read from YAML leaves out
+func (c *Config) setDefaults() {
 	// Set defaults for required fields
-	if config.Engine == "" {
-		config.Engine = "pdflatex"
+	if c.Engine == "" {
+		c.Engine = "pdflatex"
 	}
 
-	if config.Variables.VariableSet == nil {
-		config.Variables = *NewVariables()
+	if c.Variables.VariableSet == nil {
+		c.Variables = *NewVariables()
 	}
 
 	// Set defaults for new fields
-	if config.Passes < 1 {
-		config.Passes = 1
+	// With auto_passes, unset passes (0) leave the cap to the compiler's default
+	if c.Passes < 1 {
+		c.Passes = 1
+		if c.AutoPasses {
+			c.Passes = 0
+		}
 	}
-	if config.Passes > 10 {
-		config.Passes = 10
+	if c.Passes > 10 {
+		c.Passes = 10
 	}
-
-	return &config, nil
 }
 
 func (c *Config) Marshal() ([]byte, error) {
@@ -353,3 +353,58 @@ func TestConfig_ToJSON_Error(t *testing.T) {
 		t.Error("Config.ToJSON() should not return empty string")
 	}
 }
+
+func TestNewConfigFromYAML_Delimiters(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(`
+template: "letter.tex"
+delimiters:
+  left: "<<"
+  right: ">>"
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML config: %v", err)
+	}
+	if cfg.Delimiters.Left != "<<" || cfg.Delimiters.Right != ">>" {
+		t.Errorf("Expected delimiters << and >>, got %q and %q", cfg.Delimiters.Left, cfg.Delimiters.Right)
+	}
+}
+
+func TestDelimiters_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		delimiters Delimiters
+		wantErr    bool
+	}{
+		{"unset", Delimiters{}, false},
+		{"both", Delimiters{Left: "<<", Right: ">>"}, false},
+		{"left only", Delimiters{Left: "<<"}, true},
+		{"right only", Delimiters{Right: ">>"}, true},
+		{"same", Delimiters{Left: "@@", Right: "@@"}, true},
+		{"white space", Delimiters{Left: "< <", Right: ">>"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.delimiters.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVariables_Lines(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(`template: "letter.tex"
+variables:
+  title: "Letter"
+  client:
+    name: "ACME"
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML config: %v", err)
+	}
+	lines := cfg.Variables.Lines()
+	if lines["title"] != 3 || lines["client"] != 4 {
+		t.Errorf("Expected title on line 3 and client on line 4, got %v", lines)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AppendTag marks a list that is appended to the list a config inherits, instead of replacing it:
+//
+//	partials: !append [local]
+const AppendTag = "!append"
+
+// Extends names the configs a config inherits from: one path or a list of paths,
+// relative to the config that names them. Later parents override earlier ones
+type Extends []string
+
+// UnmarshalYAML implements yaml.Unmarshaler, reading a single path or a list
+func (e *Extends) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*e = Extends{value.Value}
+		return nil
+	}
+	var paths []string
+	if err := value.Decode(&paths); err != nil {
+		return fmt.Errorf("extends must be a path or a list of paths: %w", err)
+	}
+	*e = paths
+	return nil
+}
+
+// LoadConfigFile reads a config file and the configs it extends, which it is merged over:
+// maps are merged key by key, and any other value, lists included, replaces the inherited
+// one, unless the list is tagged !append. The template and partials of a parent are
+// relative to the parent's file
+func LoadConfigFile(path string) (*Config, error) {
+	node, err := loadConfigNode(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveAppendTags(node); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var config Config
+	if err := node.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	config.setDefaults()
+	return &config, nil
+}
+
+// loadConfigNode reads the mapping of a config file merged over those of its parents;
+// chain holds the files that extend it, so a config extending itself is caught
+func loadConfigNode(path string, chain []string) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range chain {
+		if child == absPath {
+			return nil, fmt.Errorf("config extends itself: %s", strings.Join(append(chain, absPath), " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", absPath, err)
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) > 0 {
+		node = doc.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: a config must be a map", absPath)
+	}
+
+	var parents Extends
+	if value := mappingValue(node, "extends"); value != nil {
+		if err := value.Decode(&parents); err != nil {
+			return nil, fmt.Errorf("%s: %w", absPath, err)
+		}
+	}
+
+	var merged *yaml.Node
+	for _, parent := range parents {
+		if !filepath.IsAbs(parent) {
+			parent = filepath.Join(filepath.Dir(absPath), parent)
+		}
+		parentNode, err := loadConfigNode(parent, append(chain, absPath))
+		if err != nil {
+			return nil, err
+		}
+		removeMappingKey(parentNode, "extends")
+		rebaseConfigPaths(parentNode, filepath.Dir(parent))
+		merged = mergeNodes(merged, parentNode)
+	}
+	return mergeNodes(merged, node), nil
+}
+
+// mergeNodes returns override merged over base, see LoadConfigFile
+func mergeNodes(base, override *yaml.Node) *yaml.Node {
+	switch {
+	case base == nil:
+		return override
+	case base.Kind == yaml.MappingNode && override.Kind == yaml.MappingNode:
+		merged := *base
+		merged.Content = append([]*yaml.Node(nil), base.Content...)
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			key, value := override.Content[i], override.Content[i+1]
+			if j := mappingIndex(&merged, key.Value); j >= 0 {
+				merged.Content[j+1] = mergeNodes(merged.Content[j+1], value)
+			} else {
+				merged.Content = append(merged.Content, key, value)
+			}
+		}
+		return &merged
+	case base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode && override.Tag == AppendTag:
+		merged := *override
+		merged.Content = append(append([]*yaml.Node(nil), base.Content...), override.Content...)
+		return &merged
+	default:
+		return override
+	}
+}
+
+// resolveAppendTags turns the lists tagged !append left after merging into plain lists
+func resolveAppendTags(node *yaml.Node) error {
+	if node.Tag == AppendTag {
+		if node.Kind != yaml.SequenceNode {
+			return fmt.Errorf("line %d: %s applies to lists only", node.Line, AppendTag)
+		}
+		node.Tag = "!!seq"
+	}
+	for _, child := range node.Content {
+		if err := resolveAppendTags(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configPathKeys locate the settings holding paths, key by key from the top of a config
+var configPathKeys = [][]string{
+	{"template"},
+	{"partials"},
+	{"typst", "font_paths"},
+	{"typst", "package_path"},
+	{"typst", "package_cache_dir"},
+	{"tectonic", "cache_dir"},
+	{"workspace", "root"},
+	{"cache", "dir"},
+}
+
+// rebaseConfigPaths makes the relative paths of a config in dir, and of its profiles, absolute,
+// so they stay relative to their own file once merged into the config extending it
+func rebaseConfigPaths(node *yaml.Node, dir string) {
+	rebase := func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode && n.Value != "" && !filepath.IsAbs(n.Value) {
+			n.Value = filepath.Join(dir, n.Value)
+		}
+	}
+	for _, keys := range configPathKeys {
+		value := node
+		for _, key := range keys {
+			if value.Kind != yaml.MappingNode {
+				value = nil
+				break
+			}
+			if value = mappingValue(value, key); value == nil {
+				break
+			}
+		}
+		switch {
+		case value == nil:
+		case value.Kind == yaml.SequenceNode:
+			for _, path := range value.Content {
+				rebase(path)
+			}
+		default:
+			rebase(value)
+		}
+	}
+	if profiles := mappingValue(node, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+		for i := 1; i < len(profiles.Content); i += 2 {
+			rebaseConfigPaths(profiles.Content[i], dir)
+		}
+	}
+}
+
+// mappingIndex returns the index of key's node in a mapping's content, or -1
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue returns the value of key in a mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(node, key); i >= 0 {
+		return node.Content[i+1]
+	}
+	return nil
+}
+
+func removeMappingKey(node *yaml.Node, key string) {
+	if i := mappingIndex(node, key); i >= 0 {
+		node.Content = append(node.Content[:i:i], node.Content[i+2:]...)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile_Extends(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	docs := filepath.Join(root, "docs")
+	for _, dir := range []string{shared, docs} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	writeFile(t, shared, "base.yaml", `
+template: letter.tex
+engine: xelatex
+passes: 3
+partials: [partials]
+conversion:
+  enabled: true
+  formats: [png]
+variables:
+  company:
+    name: AutoPDF
+    city: Lisbon
+  signers: [Ada]
+  footer: Confidential
+`)
+	writeFile(t, shared, "brand.yaml", `
+extends: base.yaml
+variables:
+  company:
+    logo: logo.png
+`)
+	child := writeFile(t, docs, "invoice.yaml", `
+extends: [../shared/brand.yaml]
+passes: 0
+partials: !append [partials]
+conversion:
+  enabled: false
+variables:
+  company:
+    city: Porto
+  signers: !append [Grace]
+  footer: Public
+`)
+
+	cfg, err := LoadConfigFile(child)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	if cfg.Template.String() != filepath.Join(shared, "letter.tex") {
+		t.Errorf("Expected the template relative to base.yaml, got %q", cfg.Template)
+	}
+	if cfg.Engine != "xelatex" {
+		t.Errorf("Expected the inherited engine, got %q", cfg.Engine)
+	}
+	if cfg.Passes != 1 {
+		t.Errorf("Expected passes: 0 to override the inherited passes and default to 1, got %d", cfg.Passes)
+	}
+	if cfg.Conversion.Enabled || !reflect.DeepEqual(cfg.Conversion.Formats, []string{"png"}) {
+		t.Errorf("Expected conversion to be merged key by key, got %+v", cfg.Conversion)
+	}
+	if expected := []string{filepath.Join(shared, "partials"), "partials"}; !reflect.DeepEqual(cfg.Partials, expected) {
+		t.Errorf("Partials = %v, want %v", cfg.Partials, expected)
+	}
+	if !reflect.DeepEqual(cfg.Extends, Extends{"../shared/brand.yaml"}) {
+		t.Errorf("Extends = %v", cfg.Extends)
+	}
+
+	expected := map[string]string{
+		"company.name": "AutoPDF",
+		"company.city": "Porto",
+		"company.logo": "logo.png",
+		"signers[0]":   "Ada",
+		"signers[1]":   "Grace",
+		"footer":       "Public",
+	}
+	if got := cfg.Variables.Flatten(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Flatten() = %v, want %v", got, expected)
+	}
+}
+
+func TestLoadConfigFile_ExtendsRebasesPaths(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	docs := filepath.Join(root, "docs")
+	for _, dir := range []string{shared, docs} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	writeFile(t, shared, "base.yaml", `
+typst:
+  font_paths: [fonts, /usr/share/fonts]
+  package_path: packages
+  package_cache_dir: cache/typst
+tectonic:
+  cache_dir: cache/tectonic
+workspace:
+  root: workspaces
+cache:
+  dir: cache/pdf
+profiles:
+  draft:
+    template: draft.tex
+    cache:
+      dir: cache/drafts
+`)
+	child := writeFile(t, docs, "invoice.yaml", `
+extends: ../shared/base.yaml
+typst:
+  package_path: local
+`)
+
+	cfg, err := LoadConfigFile(child)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	if expected := []string{filepath.Join(shared, "fonts"), "/usr/share/fonts"}; !reflect.DeepEqual(cfg.Typst.FontPaths, expected) {
+		t.Errorf("Typst.FontPaths = %v, want %v", cfg.Typst.FontPaths, expected)
+	}
+	checks := map[string][2]string{
+		"typst.package_path":      {cfg.Typst.PackagePath, "local"},
+		"typst.package_cache_dir": {cfg.Typst.PackageCacheDir, filepath.Join(shared, "cache", "typst")},
+		"tectonic.cache_dir":      {cfg.Tectonic.CacheDir, filepath.Join(shared, "cache", "tectonic")},
+		"workspace.root":          {cfg.Workspace.Root, filepath.Join(shared, "workspaces")},
+		"cache.dir":               {cfg.Cache.Dir, filepath.Join(shared, "cache", "pdf")},
+	}
+	for key, check := range checks {
+		if check[0] != check[1] {
+			t.Errorf("%s = %q, want %q", key, check[0], check[1])
+		}
+	}
+
+	if err := cfg.ApplyProfile("draft"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if cfg.Template.String() != filepath.Join(shared, "draft.tex") || cfg.Cache.Dir != filepath.Join(shared, "cache", "drafts") {
+		t.Errorf("Expected the inherited profile's paths relative to base.yaml, got %q and %q", cfg.Template, cfg.Cache.Dir)
+	}
+}
+
+func TestLoadConfigFile_ExtendsLaterParentsWin(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "engine: lualatex\nvariables: {tags: [a], note: a}\n")
+	writeFile(t, dir, "b.yaml", "engine: xelatex\nvariables: {tags: [b]}\n")
+	child := writeFile(t, dir, "child.yaml", "extends: [a.yaml, b.yaml]\n")
+
+	cfg, err := LoadConfigFile(child)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	if cfg.Engine != "xelatex" {
+		t.Errorf("Expected the last parent to win, got %q", cfg.Engine)
+	}
+	expected := map[string]string{"tags[0]": "b", "note": "a"}
+	if got := cfg.Variables.Flatten(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Flatten() = %v, want %v", got, expected)
+	}
+}
+
+func TestLoadConfigFile_ExtendsErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "extends: b.yaml\n")
+	writeFile(t, dir, "b.yaml", "extends: c.yaml\n")
+	writeFile(t, dir, "c.yaml", "extends: a.yaml\n")
+
+	_, err := LoadConfigFile(filepath.Join(dir, "a.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "config extends itself") {
+		t.Fatalf("Expected a cycle error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "a.yaml -> "+filepath.Join(dir, "b.yaml")) {
+		t.Errorf("Expected the error to show the cycle, got %v", err)
+	}
+
+	tests := map[string]string{
+		"missing parent":     "extends: missing.yaml\n",
+		"append to a map":    "variables: !append {a: 1}\n",
+		"invalid extends":    "extends: {a: b}\n",
+		"not a map":          "- a\n- b\n",
+		"parent is not yaml": "extends: broken.yaml\n",
+	}
+	writeFile(t, dir, "broken.yaml", "engine: [\n")
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadConfigFile(writeFile(t, dir, "config.yaml", content)); err == nil {
+				t.Error("Expected LoadConfigFile() to fail")
+			}
+		})
+	}
+}
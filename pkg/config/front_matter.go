@@ -0,0 +1,247 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterFence opens and closes a template's front matter
+const frontMatterFence = "---"
+
+// FrontMatter is what a LaTeX template declares about itself in %-prefixed YAML at its top:
+//
+//	% ---
+//	% engine: xelatex
+//	% passes: 2
+//	% variables:
+//	%   required: [title, client.name]
+//	%   optional:
+//	%     subtitle: ""
+//	% assets: [fonts/*.otf]
+//	% ---
+//
+// It takes precedence over the config file, and request options over it; variables
+// the config or request define take precedence over the optional defaults
+type FrontMatter struct {
+	Engine     Engine               `yaml:"engine,omitempty" json:"engine,omitempty"`
+	Passes     int                  `yaml:"passes,omitempty" json:"passes,omitempty"`
+	UseLatexmk *bool                `yaml:"use_latexmk,omitempty" json:"use_latexmk,omitempty"` // Unset keeps the config's
+	Delimiters Delimiters           `yaml:"delimiters,omitempty" json:"delimiters,omitempty"`
+	Variables  FrontMatterVariables `yaml:"variables,omitempty" json:"variables,omitempty"`
+	Assets     []string             `yaml:"assets,omitempty" json:"assets,omitempty"` // Globs relative to the template, like Assets.Extra
+}
+
+// FrontMatterVariables are the variables a template needs
+type FrontMatterVariables struct {
+	Required []string  `yaml:"required,omitempty" json:"required,omitempty"` // Paths, such as client.name
+	Optional Variables `yaml:"optional,omitempty" json:"optional,omitempty"` // With their default values
+}
+
+// ParseFrontMatter returns the front matter at the top of a template, nil without one,
+// and the template with the front matter's lines blanked, so line numbers are kept
+func ParseFrontMatter(content string) (*FrontMatter, string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if text, ok := frontMatterLine(line); ok && strings.TrimSpace(text) == frontMatterFence {
+			start = i
+		}
+		break
+	}
+	if start < 0 {
+		return nil, content, nil
+	}
+
+	var yamlLines []string
+	end := -1
+	for i := start + 1; i < len(lines) && end < 0; i++ {
+		text, ok := frontMatterLine(lines[i])
+		switch {
+		case !ok:
+			return nil, content, fmt.Errorf("front matter line %d is not a %% comment", i+1)
+		case strings.TrimSpace(text) == frontMatterFence:
+			end = i
+		default:
+			yamlLines = append(yamlLines, text)
+		}
+	}
+	if end < 0 {
+		return nil, content, fmt.Errorf("front matter opened on line %d is not closed", start+1)
+	}
+
+	var frontMatter FrontMatter
+	if err := yaml.Unmarshal([]byte(strings.Join(yamlLines, "\n")), &frontMatter); err != nil {
+		return nil, content, fmt.Errorf("invalid front matter: %w", err)
+	}
+	if err := frontMatter.Validate(); err != nil {
+		return nil, content, err
+	}
+
+	for i := start; i <= end; i++ {
+		if strings.HasSuffix(lines[i], "\n") {
+			lines[i] = "\n"
+		} else {
+			lines[i] = ""
+		}
+	}
+	return &frontMatter, strings.Join(lines, ""), nil
+}
+
+// ReadFrontMatter parses the front matter of a template file
+// A template that does not exist has none; reporting it is left to the template processor
+func ReadFrontMatter(templatePath string) (*FrontMatter, error) {
+	content, err := os.ReadFile(templatePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	frontMatter, _, err := ParseFrontMatter(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", templatePath, err)
+	}
+	return frontMatter, nil
+}
+
+// frontMatterLine returns the text of a % comment line, without the space following the %
+func frontMatterLine(line string) (string, bool) {
+	line = strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(line, "%") {
+		return "", false
+	}
+	text := strings.TrimPrefix(line[1:], " ")
+	return strings.TrimRight(text, "\r\n"), true
+}
+
+// Validate checks the passes and delimiters the front matter declares
+func (fm *FrontMatter) Validate() error {
+	if fm.Passes < 0 || fm.Passes > 10 {
+		return fmt.Errorf("front matter passes must be between 1 and 10, got %d", fm.Passes)
+	}
+	return fm.Delimiters.Validate()
+}
+
+// Apply merges the front matter into a config: its engine, passes, use_latexmk and delimiters
+// replace the config's, its assets are added, and its optional variables default those
+// the config does not define. The engine, passes and use_latexmk of the profile applied to
+// the config, which was selected for this build, are kept; the delimiters the template is
+// written with are not
+func (fm *FrontMatter) Apply(cfg *Config) {
+	if fm == nil {
+		return
+	}
+	profile := cfg.Profiles[cfg.Profile]
+	if fm.Engine != "" && !profile.Sets("engine") {
+		cfg.Engine = fm.Engine
+	}
+	if fm.Passes > 0 && !profile.Sets("passes") {
+		cfg.Passes = fm.Passes
+	}
+	if fm.UseLatexmk != nil && !profile.Sets("use_latexmk") {
+		cfg.UseLatexmk = *fm.UseLatexmk
+	}
+	if fm.Delimiters.IsSet() {
+		cfg.Delimiters = fm.Delimiters
+	}
+	for _, asset := range fm.Assets {
+		if !containsString(cfg.Assets.Extra, asset) {
+			cfg.Assets.Extra = append(cfg.Assets.Extra, asset)
+		}
+	}
+	fm.ApplyDefaults(&cfg.Variables)
+}
+
+// ApplyDefaults defines the optional variables vars does not, including the missing keys
+// of maps vars defines only in part. The defaults are template text, so they are literal:
+// Config.Interpolate leaves ${...} in them as it is
+func (fm *FrontMatter) ApplyDefaults(vars *Variables) {
+	if fm == nil || fm.Variables.Optional.VariableSet == nil {
+		return
+	}
+	if vars.VariableSet == nil {
+		vars.VariableSet = NewVariableSet()
+	}
+	fm.Variables.Optional.Range(func(name string, value Variable) bool {
+		markLiteral(value)
+		if existing, ok := vars.Get(name); ok {
+			fillDefaults(existing, value)
+		} else {
+			vars.Set(name, value)
+		}
+		return true
+	})
+}
+
+// markLiteral marks the strings in v literal
+func markLiteral(v Variable) {
+	switch v := v.(type) {
+	case *StringVariable:
+		v.Literal = true
+	case *MapVariable:
+		for _, value := range v.Values {
+			markLiteral(value)
+		}
+	case *SliceVariable:
+		for _, item := range v.Values {
+			markLiteral(item)
+		}
+	}
+}
+
+// fillDefaults adds the keys of defaults that v lacks, when both are maps
+func fillDefaults(v, defaults Variable) {
+	target, ok := v.(*MapVariable)
+	if !ok {
+		return
+	}
+	source, ok := defaults.(*MapVariable)
+	if !ok {
+		return
+	}
+	for key, value := range source.Values {
+		if existing, ok := target.Values[key]; ok {
+			fillDefaults(existing, value)
+		} else {
+			target.Values[key] = value
+		}
+	}
+}
+
+// MissingVariables returns the required variables vars does not define, in declaration order
+func (fm *FrontMatter) MissingVariables(vars *Variables) []string {
+	if fm == nil {
+		return nil
+	}
+	var missing []string
+	for _, path := range fm.Variables.Required {
+		if vars == nil || vars.VariableSet == nil {
+			missing = append(missing, path)
+			continue
+		}
+		if _, ok := vars.GetByPath(path); !ok {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const frontMatterTemplate = `% ---
+% engine: xelatex
+% passes: 2
+% use_latexmk: true
+% variables:
+%   required: [title, client.name]
+%   optional:
+%     subtitle: "Draft"
+%     client:
+%       country: "BR"
+% assets: [fonts/*.otf]
+% ---
+\documentclass{article}
+`
+
+func TestParseFrontMatter(t *testing.T) {
+	frontMatter, content, err := ParseFrontMatter(frontMatterTemplate)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+	if frontMatter.Engine != "xelatex" || frontMatter.Passes != 2 {
+		t.Errorf("Expected engine xelatex and 2 passes, got %q and %d", frontMatter.Engine, frontMatter.Passes)
+	}
+	if frontMatter.UseLatexmk == nil || !*frontMatter.UseLatexmk {
+		t.Errorf("Expected use_latexmk to be set to true")
+	}
+	if !reflect.DeepEqual(frontMatter.Variables.Required, []string{"title", "client.name"}) {
+		t.Errorf("Unexpected required variables %v", frontMatter.Variables.Required)
+	}
+	if got, _ := frontMatter.Variables.Optional.GetString("client.country"); got != "BR" {
+		t.Errorf("Expected optional client.country BR, got %q", got)
+	}
+	if !reflect.DeepEqual(frontMatter.Assets, []string{"fonts/*.otf"}) {
+		t.Errorf("Unexpected assets %v", frontMatter.Assets)
+	}
+
+	// The block is blanked, keeping the line numbers of the template
+	if want := strings.Repeat("\n", 12) + "\\documentclass{article}\n"; content != want {
+		t.Errorf("Expected front matter lines to be blanked, got %q", content)
+	}
+}
+
+func TestParseFrontMatter_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not closed", "% ---\n% engine: xelatex\n\\documentclass{article}\n"},
+		{"invalid yaml", "% ---\n% engine: [xelatex\n% ---\n"},
+		{"passes", "% ---\n% passes: 12\n% ---\n"},
+		{"delimiters", "% ---\n% delimiters: {left: \"<<\"}\n% ---\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseFrontMatter(tt.content); err == nil {
+				t.Errorf("Expected an error for %q", tt.content)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatter_None(t *testing.T) {
+	for _, content := range []string{"\\documentclass{article}\n% ---\n", "% A letter\n% ---\n", ""} {
+		frontMatter, got, err := ParseFrontMatter(content)
+		if err != nil || frontMatter != nil || got != content {
+			t.Errorf("Expected no front matter in %q, got %v, %q, %v", content, frontMatter, got, err)
+		}
+	}
+}
+
+func TestFrontMatter_Apply(t *testing.T) {
+	frontMatter, _, err := ParseFrontMatter(frontMatterTemplate)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+	cfg, err := NewConfigFromYAML([]byte(`
+engine: pdflatex
+variables:
+  title: "Report"
+  client:
+    name: "ACME"
+assets:
+  extra: [logo.png]
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+
+	frontMatter.Apply(cfg)
+
+	if cfg.Engine != "xelatex" || cfg.Passes != 2 || !cfg.UseLatexmk {
+		t.Errorf("Expected the front matter's engine, passes and use_latexmk, got %q, %d, %v", cfg.Engine, cfg.Passes, cfg.UseLatexmk)
+	}
+	if !reflect.DeepEqual(cfg.Assets.Extra, []string{"logo.png", "fonts/*.otf"}) {
+		t.Errorf("Unexpected assets %v", cfg.Assets.Extra)
+	}
+	for path, want := range map[string]string{"title": "Report", "subtitle": "Draft", "client.name": "ACME", "client.country": "BR"} {
+		if got, _ := cfg.Variables.GetString(path); got != want {
+			t.Errorf("Expected %s to be %q, got %q", path, want, got)
+		}
+	}
+	if missing := frontMatter.MissingVariables(&cfg.Variables); len(missing) != 0 {
+		t.Errorf("Expected no missing variables, got %v", missing)
+	}
+
+	if missing := frontMatter.MissingVariables(NewVariables()); !reflect.DeepEqual(missing, []string{"title", "client.name"}) {
+		t.Errorf("Expected title and client.name to be missing, got %v", missing)
+	}
+}
+
+func TestFrontMatter_ApplyKeepsProfileSettings(t *testing.T) {
+	frontMatter, _, err := ParseFrontMatter(frontMatterTemplate)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+	cfg, err := NewConfigFromYAML([]byte(`
+engine: pdflatex
+profiles:
+  final: {engine: lualatex, passes: 3, use_latexmk: false}
+  draft: {passes: 1}
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	if err := cfg.ApplyProfile("final"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+
+	frontMatter.Apply(cfg)
+
+	if cfg.Engine != "lualatex" || cfg.Passes != 3 || cfg.UseLatexmk {
+		t.Errorf("Expected the profile's engine, passes and use_latexmk, got %q, %d, %v", cfg.Engine, cfg.Passes, cfg.UseLatexmk)
+	}
+
+	cfg, err = NewConfigFromYAML([]byte(`
+engine: pdflatex
+profiles:
+  draft: {passes: 1}
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	if err := cfg.ApplyProfile("draft"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+
+	frontMatter.Apply(cfg)
+
+	if cfg.Engine != "xelatex" || cfg.Passes != 1 || !cfg.UseLatexmk {
+		t.Errorf("Expected the front matter to fill in what the profile leaves unset, got %q, %d, %v", cfg.Engine, cfg.Passes, cfg.UseLatexmk)
+	}
+}
+
+func TestReadFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "letter.tex")
+	if err := os.WriteFile(templatePath, []byte(frontMatterTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	frontMatter, err := ReadFrontMatter(templatePath)
+	if err != nil || frontMatter == nil {
+		t.Fatalf("ReadFrontMatter() = %v, %v", frontMatter, err)
+	}
+
+	frontMatter, err = ReadFrontMatter(filepath.Join(dir, "missing.tex"))
+	if err != nil || frontMatter != nil {
+		t.Errorf("Expected no front matter for a missing template, got %v, %v", frontMatter, err)
+	}
+}
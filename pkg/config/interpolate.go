@@ -0,0 +1,248 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Interpolation is what the ${...} expressions of a config are resolved with, see Config.Interpolate
+type Interpolation struct {
+	Dir     string    // Directory git:describe runs in, the current one when empty
+	Now     time.Time // Time of now, the current time when zero
+	Environ []string  // Environment read by env:NAME, os.Environ() when nil
+}
+
+// Interpolate resolves the ${...} expressions in the config's string variables and output,
+// leaving literal ones, the content of --set-file files and front matter defaults, as written.
+// An expression is the path of another variable, such as ${client.name} or ${items[0].qty},
+// or a built-in:
+//
+//	${now}           the time, as RFC 3339
+//	${now:LAYOUT}    the time in a Go layout, such as ${now:2006-01-02}
+//	${env:NAME}      an environment variable, empty when unset
+//	${uuid}          a random UUID
+//	${git:describe}  git describe --tags --always --dirty, run in Interpolation.Dir
+//
+// A variable named now or uuid is referred to instead of the built-in. Referenced variables
+// are resolved first; a variable referring to itself, directly or not, is an error.
+// $${ is a literal ${
+func (c *Config) Interpolate(in Interpolation) error {
+	ip := newInterpolator(&c.Variables, in)
+	if c.Variables.VariableSet != nil {
+		for _, name := range sortedKeys(c.Variables.GetVariables()) {
+			value, _ := c.Variables.Get(name)
+			if err := ip.walk(name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	output, err := ip.expand(c.Output.String())
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	c.Output = Output(output)
+	return nil
+}
+
+// interpolator resolves the string variables of a set in place, each once
+type interpolator struct {
+	Interpolation
+	vars      *Variables
+	env       map[string]string
+	resolved  map[*StringVariable]bool
+	resolving []string                // Paths of the variables being resolved, to report cycles
+	active    map[*StringVariable]int // Index of a variable being resolved in resolving
+	described *string
+}
+
+func newInterpolator(vars *Variables, in Interpolation) *interpolator {
+	if in.Now.IsZero() {
+		in.Now = time.Now()
+	}
+	if in.Environ == nil {
+		in.Environ = os.Environ()
+	}
+	env := make(map[string]string, len(in.Environ))
+	for _, entry := range in.Environ {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			env[name] = value
+		}
+	}
+	return &interpolator{
+		Interpolation: in,
+		vars:          vars,
+		env:           env,
+		resolved:      make(map[*StringVariable]bool),
+		active:        make(map[*StringVariable]int),
+	}
+}
+
+// walk resolves the string variables in v, whose path is path
+func (ip *interpolator) walk(path string, v Variable) error {
+	switch v := v.(type) {
+	case *StringVariable:
+		return ip.resolve(path, v)
+	case *MapVariable:
+		for _, key := range sortedKeys(v.Values) {
+			if err := ip.walk(path+"."+key, v.Values[key]); err != nil {
+				return err
+			}
+		}
+	case *SliceVariable:
+		for i, item := range v.Values {
+			if err := ip.walk(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve expands the expressions of a string variable, unless it already was
+func (ip *interpolator) resolve(path string, v *StringVariable) error {
+	if ip.resolved[v] || v.Literal {
+		return nil
+	}
+	if i, ok := ip.active[v]; ok {
+		return &cycleError{Paths: append(append([]string(nil), ip.resolving[i:]...), path)}
+	}
+
+	ip.active[v] = len(ip.resolving)
+	ip.resolving = append(ip.resolving, path)
+	value, err := ip.expand(v.Value)
+	ip.resolving = ip.resolving[:len(ip.resolving)-1]
+	delete(ip.active, v)
+	if err != nil {
+		var cycle *cycleError
+		if len(ip.resolving) > 0 || errors.As(err, &cycle) {
+			return err // Reported with the path of the variable that was resolved first
+		}
+		return fmt.Errorf("variable %s: %w", path, err)
+	}
+
+	v.Value = value
+	ip.resolved[v] = true
+	return nil
+}
+
+// cycleError reports variables whose expressions refer to each other
+type cycleError struct {
+	Paths []string // The first path is repeated last
+}
+
+func (e *cycleError) Error() string {
+	return "variables refer to each other: " + strings.Join(e.Paths, " -> ")
+}
+
+// expand returns s with its expressions replaced by their values and $${ by ${
+func (ip *interpolator) expand(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(s[start+2:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ${ in %q, write $${ for a literal ${", s)
+		}
+		expr := strings.TrimSpace(s[start+2 : start+2+end])
+		value, err := ip.evaluate(expr)
+		var cycle *cycleError
+		if errors.As(err, &cycle) {
+			return "", err
+		} else if err != nil {
+			return "", fmt.Errorf("${%s}: %w", expr, err)
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+3+end:]
+	}
+}
+
+// evaluate returns the value of a variable path or built-in
+func (ip *interpolator) evaluate(expr string) (string, error) {
+	if expr == "" {
+		return "", fmt.Errorf("empty expression, write $${ for a literal ${")
+	}
+
+	name, arg, hasArg := strings.Cut(expr, ":")
+	if !hasArg && ip.vars.VariableSet != nil {
+		if v, ok := ip.vars.GetByPath(expr); ok {
+			return ip.value(expr, v)
+		}
+	}
+
+	switch {
+	case name == "now":
+		layout := time.RFC3339
+		if hasArg {
+			layout = arg
+		}
+		return ip.Now.Format(layout), nil
+	case name == "uuid" && !hasArg:
+		return uuid.NewString(), nil
+	case name == "env" && hasArg:
+		return ip.env[arg], nil
+	case name == "git" && arg == "describe":
+		return ip.describe()
+	case hasArg:
+		return "", fmt.Errorf("unknown built-in, use now, now:LAYOUT, env:NAME, uuid or git:describe")
+	}
+	return "", fmt.Errorf("no variable %s", expr)
+}
+
+// value returns a referenced variable as text, resolving its own expressions first
+func (ip *interpolator) value(path string, v Variable) (string, error) {
+	switch v := v.(type) {
+	case *StringVariable:
+		if err := ip.resolve(path, v); err != nil {
+			return "", err
+		}
+		return v.Value, nil
+	case *NumberVariable, *BoolVariable:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("%s is a map or a list, refer to one of its values", path)
+}
+
+// describe runs git describe once per interpolation
+func (ip *interpolator) describe() (string, error) {
+	if ip.described != nil {
+		return *ip.described, nil
+	}
+	cmd := exec.Command("git", "describe", "--tags", "--always", "--dirty")
+	cmd.Dir = ip.Dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git describe: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	described := strings.TrimSpace(string(out))
+	ip.described = &described
+	return described, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Interpolate(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(`
+output: "out/invoice-${invoice.number}-${now:2006-01-02}.pdf"
+variables:
+  subject: "Invoice ${invoice.number} for ${client.name}"
+  greeting: "Dear ${client.contact}"
+  invoice:
+    number: 42
+    paid: false
+    status: "paid: ${invoice.paid}"
+  client:
+    name: ACME
+    contact: "${client.name} accounts"
+  items:
+    - name: "Pen for ${client.name}"
+  builder: "${env:USER} at ${now}"
+  literal: "costs $${price} and $$5"
+  id: "${uuid}"
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+
+	now := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	if err := cfg.Interpolate(Interpolation{Now: now, Environ: []string{"USER=ada"}}); err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+
+	if cfg.Output != "out/invoice-42-2025-03-14.pdf" {
+		t.Errorf("Output = %q", cfg.Output)
+	}
+	vars := cfg.Variables.Flatten()
+	id := vars["id"]
+	delete(vars, "id")
+	expected := map[string]string{
+		"subject":        "Invoice 42 for ACME",
+		"greeting":       "Dear ACME accounts",
+		"invoice.number": "42",
+		"invoice.paid":   "false",
+		"invoice.status": "paid: false",
+		"client.name":    "ACME",
+		"client.contact": "ACME accounts",
+		"items[0].name":  "Pen for ACME",
+		"builder":        "ada at 2025-03-14T09:30:00Z",
+		"literal":        "costs ${price} and $$5",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("Flatten() = %v, want %v", vars, expected)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("Expected a random UUID, got %q", id)
+	}
+}
+
+func TestConfig_InterpolateLiteralValues(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(`
+variables:
+  sample: carbon
+  caption: "Dating ${sample}: ${isotope}"
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	dir := t.TempDir()
+	sources := VariableSources{
+		SetFiles: []Override{{Path: "isotope", Value: writeFile(t, dir, "isotope.tex", "${}^{14}$C")}},
+	}
+	if err := sources.Apply(&cfg.Variables); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	frontMatter, _, err := ParseFrontMatter("% ---\n% variables:\n%   optional:\n%     note: \"${x}$ is unset\"\n% ---\n")
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+	frontMatter.ApplyDefaults(&cfg.Variables)
+
+	if err := cfg.Interpolate(Interpolation{Environ: []string{}}); err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+	expected := map[string]string{
+		"sample":  "carbon",
+		"caption": "Dating carbon: ${}^{14}$C",
+		"isotope": "${}^{14}$C",
+		"note":    "${x}$ is unset",
+	}
+	if got := cfg.Variables.Flatten(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Flatten() = %v, want %v", got, expected)
+	}
+}
+
+func TestConfig_InterpolateReferencesResolveOnce(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(`
+variables:
+  a: "${b}"
+  b: "$${literal}"
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	if err := cfg.Interpolate(Interpolation{}); err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+	if got := cfg.Variables.Flatten(); !reflect.DeepEqual(got, map[string]string{"a": "${literal}", "b": "${literal}"}) {
+		t.Errorf("Expected escaped expressions to stay literal when referred to, got %v", got)
+	}
+}
+
+func TestConfig_InterpolateErrors(t *testing.T) {
+	tests := map[string]struct {
+		yaml string
+		want string
+	}{
+		"cycle":           {"variables: {a: '${b}', b: '${c.d}', c: {d: '${a}'}}", "variables refer to each other: a -> b -> c.d -> a"},
+		"self reference":  {"variables: {a: 'x ${a}'}", "a -> a"},
+		"unknown":         {"variables: {a: '${missing}'}", "variable a: ${missing}: no variable missing"},
+		"map reference":   {"variables: {a: '${b}', b: {c: d}}", "b is a map or a list"},
+		"unknown builtin": {"variables: {a: '${git:log}'}", "unknown built-in"},
+		"unterminated":    {"variables: {a: 'x ${b'}", "unterminated"},
+		"empty":           {"variables: {a: 'x ${}'}", "empty expression"},
+		"output":          {"output: '${missing}.pdf'", "output: ${missing}"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := NewConfigFromYAML([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("NewConfigFromYAML() error = %v", err)
+			}
+			err = cfg.Interpolate(Interpolation{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Interpolate() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
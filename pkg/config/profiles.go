@@ -0,0 +1,125 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a named set of overrides of a config, written like the config itself:
+//
+//	profiles:
+//	  draft:
+//	    passes: 1
+//	    variables:
+//	      watermark: DRAFT
+type Profile struct {
+	node *yaml.Node
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, keeping the profile's YAML so it can be merged
+// over the config's like a config over the one it extends
+func (p *Profile) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: a profile must be a map", value.Line)
+	}
+	for _, key := range []string{"extends", "profiles"} {
+		if mappingIndex(value, key) >= 0 {
+			return fmt.Errorf("line %d: a profile cannot set %s", value.Line, key)
+		}
+	}
+	p.node = value
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (p Profile) MarshalYAML() (interface{}, error) {
+	return p.node, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (p Profile) MarshalJSON() ([]byte, error) {
+	var value interface{}
+	if p.node != nil {
+		if err := p.node.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(value)
+}
+
+// Sets reports whether the profile sets a top-level field, such as engine or passes
+func (p Profile) Sets(key string) bool {
+	return p.node != nil && mappingIndex(p.node, key) >= 0
+}
+
+// Config returns the fields and variables the profile sets, all others left zero
+func (p Profile) Config() (*Config, error) {
+	var config Config
+	if p.node == nil {
+		config.Variables = *NewVariables()
+		return &config, nil
+	}
+	node := cloneNode(p.node)
+	if err := resolveAppendTags(node); err != nil {
+		return nil, err
+	}
+	if err := node.Decode(&config); err != nil {
+		return nil, err
+	}
+	if config.Variables.VariableSet == nil {
+		config.Variables = *NewVariables()
+	}
+	return &config, nil
+}
+
+// ProfileNames returns the names of the config's profiles, sorted
+func (c *Config) ProfileNames() []string {
+	return sortedKeys(c.Profiles)
+}
+
+// ApplyProfile merges the named profile over the config read from YAML, as a config is merged
+// over the one it extends: maps key by key, lists replaced unless tagged !append
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: the config defines no profiles", name)
+		}
+		return fmt.Errorf("unknown profile %q: use one of %s", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if c.node == nil {
+		return fmt.Errorf("profile %s: profiles apply to configs read from YAML", name)
+	}
+
+	merged := mergeNodes(cloneNode(c.node), cloneNode(profile.node))
+	if err := resolveAppendTags(merged); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	var profiled Config
+	if err := merged.Decode(&profiled); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	profiled.setDefaults()
+	profiled.Profile = name
+	*c = profiled
+	return nil
+}
+
+// cloneNode returns a deep copy of a YAML node, so merging never changes the node it copies
+func cloneNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	clone := *node
+	clone.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		clone.Content[i] = cloneNode(child)
+	}
+	return &clone
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const profilesYAML = `
+template: report.tex
+engine: pdflatex
+passes: 2
+partials: [partials]
+conversion:
+  enabled: false
+  formats: [png]
+variables:
+  title: Annual report
+  watermark: ""
+  company:
+    name: AutoPDF
+    city: Lisbon
+profiles:
+  draft:
+    passes: 1
+    partials: !append [drafts]
+    variables:
+      watermark: DRAFT
+  final:
+    engine: xelatex
+    passes: 3
+    use_latexmk: true
+    conversion:
+      enabled: true
+    variables:
+      company:
+        city: Porto
+`
+
+func TestConfig_ApplyProfile(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(profilesYAML))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	if names := cfg.ProfileNames(); !reflect.DeepEqual(names, []string{"draft", "final"}) {
+		t.Errorf("ProfileNames() = %v", names)
+	}
+
+	if err := cfg.ApplyProfile("final"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if cfg.Profile != "final" {
+		t.Errorf("Expected the applied profile to be recorded, got %q", cfg.Profile)
+	}
+	if cfg.Engine != "xelatex" || cfg.Passes != 3 || !cfg.UseLatexmk {
+		t.Errorf("Expected the profile's fields, got engine %q, passes %d, use_latexmk %v", cfg.Engine, cfg.Passes, cfg.UseLatexmk)
+	}
+	if !cfg.Conversion.Enabled || !reflect.DeepEqual(cfg.Conversion.Formats, []string{"png"}) {
+		t.Errorf("Expected conversion to be merged key by key, got %+v", cfg.Conversion)
+	}
+	if cfg.Template != "report.tex" {
+		t.Errorf("Expected the fields the profile does not set to be kept, got %q", cfg.Template)
+	}
+	expected := map[string]string{
+		"title":        "Annual report",
+		"watermark":    "",
+		"company.name": "AutoPDF",
+		"company.city": "Porto",
+	}
+	if got := cfg.Variables.Flatten(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Flatten() = %v, want %v", got, expected)
+	}
+	if len(cfg.Profiles) != 2 {
+		t.Errorf("Expected the profiles to be kept, got %v", cfg.ProfileNames())
+	}
+
+	cfg, err = NewConfigFromYAML([]byte(profilesYAML))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	if err := cfg.ApplyProfile("draft"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if cfg.Passes != 1 || cfg.Engine != "pdflatex" {
+		t.Errorf("Expected passes 1 with the config's engine, got passes %d, engine %q", cfg.Passes, cfg.Engine)
+	}
+	if !reflect.DeepEqual(cfg.Partials, []string{"partials", "drafts"}) {
+		t.Errorf("Expected !append to extend the config's partials, got %v", cfg.Partials)
+	}
+	if watermark, _ := cfg.Variables.GetString("watermark"); watermark != "DRAFT" {
+		t.Errorf("Expected the profile's watermark, got %q", watermark)
+	}
+}
+
+func TestConfig_ApplyProfileErrors(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(profilesYAML))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	err = cfg.ApplyProfile("print")
+	if err == nil || !strings.Contains(err.Error(), "use one of draft, final") {
+		t.Errorf("Expected an unknown profile error listing the profiles, got %v", err)
+	}
+
+	built := &Config{Profiles: cfg.Profiles}
+	if err := built.ApplyProfile("draft"); err == nil {
+		t.Error("Expected a config not read from YAML to fail")
+	}
+
+	tests := map[string]string{
+		"not a map":      "profiles:\n  draft: [a]\n",
+		"extends":        "profiles:\n  draft:\n    extends: base.yaml\n",
+		"nested profile": "profiles:\n  draft:\n    profiles: {}\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewConfigFromYAML([]byte(content)); err == nil {
+				t.Error("Expected NewConfigFromYAML() to fail")
+			}
+		})
+	}
+}
+
+func TestProfile_Config(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(profilesYAML))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+
+	final, err := cfg.Profiles["final"].Config()
+	if err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	if final.Engine != "xelatex" || final.Passes != 3 || final.Template != "" || final.Conversion.Formats != nil {
+		t.Errorf("Expected only the profile's fields to be set, got %+v", final)
+	}
+	if got := final.Variables.Flatten(); !reflect.DeepEqual(got, map[string]string{"company.city": "Porto"}) {
+		t.Errorf("Flatten() = %v", got)
+	}
+
+	data, err := json.Marshal(cfg.Profiles["draft"])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"watermark":"DRAFT"`) {
+		t.Errorf("Expected the profile as JSON, got %s", data)
+	}
+}
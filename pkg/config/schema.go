@@ -0,0 +1,349 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaFileSuffix names the schema file next to a template: letter.tex has letter.schema.json
+const SchemaFileSuffix = ".schema.json"
+
+// Schema describes the variables a template takes, in a subset of JSON Schema:
+//
+//	{
+//	  "type": "object",
+//	  "required": ["client", "items"],
+//	  "properties": {
+//	    "client": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string", "minLength": 1}}},
+//	    "currency": {"type": "string", "enum": ["EUR", "USD"], "default": "EUR"},
+//	    "items": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"price": {"type": "number", "minimum": 0}}}}
+//	  }
+//	}
+//
+// It is read from the template's schema file, or embedded in the config under schema
+type Schema struct {
+	Type        string             `yaml:"type,omitempty" json:"type,omitempty"` // string, number, integer, boolean, array, object or null
+	Description string             `yaml:"description,omitempty" json:"description,omitempty"`
+	Properties  map[string]*Schema `yaml:"properties,omitempty" json:"properties,omitempty"`
+	Required    []string           `yaml:"required,omitempty" json:"required,omitempty"`
+	// Without it, or when true, an object may have properties the schema does not list
+	AdditionalProperties *bool         `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
+	Enum                 []interface{} `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Pattern              string        `yaml:"pattern,omitempty" json:"pattern,omitempty"` // Regular expression a string must match
+	MinLength            *int          `yaml:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength            *int          `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Minimum              *float64      `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	Maximum              *float64      `yaml:"maximum,omitempty" json:"maximum,omitempty"`
+	Items                *Schema       `yaml:"items,omitempty" json:"items,omitempty"` // The schema of every array item
+	MinItems             *int          `yaml:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems             *int          `yaml:"maxItems,omitempty" json:"maxItems,omitempty"`
+	Default              interface{}   `yaml:"default,omitempty" json:"default,omitempty"` // The value of a property the variables do not define
+}
+
+// FieldError is a variable a schema rejects, by its path, such as items[2].price
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// String returns the error as "items[2].price: must be >= 0"
+func (e FieldError) String() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// SchemaError lists the field errors of variables a schema rejects
+type SchemaError struct {
+	Fields []FieldError
+}
+
+func (e *SchemaError) Error() string {
+	return "variables do not match the schema: " + strings.Join(e.Messages(), "; ")
+}
+
+// Messages returns the field errors as strings
+func (e *SchemaError) Messages() []string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.String()
+	}
+	return messages
+}
+
+// SchemaPath returns the path of the schema file of a template
+func SchemaPath(templatePath string) string {
+	return strings.TrimSuffix(templatePath, filepath.Ext(templatePath)) + SchemaFileSuffix
+}
+
+// ReadSchema reads the schema file of a template; a template without one has no schema
+func ReadSchema(templatePath string) (*Schema, error) {
+	path := SchemaPath(templatePath)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var schema Schema
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("%s: invalid schema: %w", path, err)
+	}
+	if err := schema.Check(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &schema, nil
+}
+
+// Check reports schema keywords whose values cannot be used, such as an invalid pattern
+func (s *Schema) Check() error {
+	return s.check("")
+}
+
+func (s *Schema) check(path string) error {
+	if s == nil {
+		return nil
+	}
+	switch s.Type {
+	case "", "string", "number", "integer", "boolean", "array", "object", "null":
+	default:
+		return fmt.Errorf("schema %s: unknown type %q", schemaLocation(path), s.Type)
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("schema %s: invalid pattern: %w", schemaLocation(path), err)
+		}
+	}
+	for _, name := range sortedKeys(s.Properties) {
+		if err := s.Properties[name].check(joinField(path, name)); err != nil {
+			return err
+		}
+	}
+	return s.Items.check(path + "[]")
+}
+
+func schemaLocation(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return path
+}
+
+// Validate returns the field errors of data, the template data of the variables,
+// in the order of the schema's required and sorted properties
+func (s *Schema) Validate(data map[string]interface{}) []FieldError {
+	if s == nil {
+		return nil
+	}
+	return s.validate("", data, nil)
+}
+
+func (s *Schema) validate(field string, value interface{}, errs []FieldError) []FieldError {
+	if s == nil {
+		return errs
+	}
+	fail := func(format string, args ...interface{}) []FieldError {
+		return append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		article := "a"
+		if strings.ContainsAny(s.Type[:1], "aeiou") {
+			article = "an"
+		}
+		return fail("must be %s %s", article, s.Type)
+	}
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		return fail("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			errs = fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			errs = fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			if matched, err := regexp.MatchString(s.Pattern, v); err == nil && !matched {
+				errs = fail("must match %s", s.Pattern)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			errs = fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			errs = fail("must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range v {
+			errs = s.Items.validate(fmt.Sprintf("%s[%d]", field, i), item, errs)
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, FieldError{Field: joinField(field, name), Message: "is required"})
+			}
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			if property, ok := v[name]; ok {
+				errs = s.Properties[name].validate(joinField(field, name), property, errs)
+			}
+		}
+		if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+			for _, name := range sortedKeys(v) {
+				if _, ok := s.Properties[name]; !ok {
+					errs = append(errs, FieldError{Field: joinField(field, name), Message: "is not allowed"})
+				}
+			}
+		}
+	default:
+		if number, ok := toFloat(value); ok {
+			if s.Minimum != nil && number < *s.Minimum {
+				errs = fail("must be >= %v", *s.Minimum)
+			}
+			if s.Maximum != nil && number > *s.Maximum {
+				errs = fail("must be <= %v", *s.Maximum)
+			}
+		}
+	}
+	return errs
+}
+
+// ApplyDefaults defines the properties with a default that vars does not, including those
+// of the objects vars defines, and of the objects in its arrays
+func (s *Schema) ApplyDefaults(vars *Variables) {
+	if s == nil || len(s.Properties) == 0 {
+		return
+	}
+	if vars.VariableSet == nil {
+		vars.VariableSet = NewVariableSet()
+	}
+	for name, property := range s.Properties {
+		if existing, ok := vars.Get(name); ok {
+			property.fillDefaults(existing)
+		} else if property.Default != nil {
+			vars.Set(name, convertToVariable(property.Default))
+		}
+	}
+}
+
+// fillDefaults applies the defaults of an object or array schema to the value it describes
+func (s *Schema) fillDefaults(v Variable) {
+	switch val := v.(type) {
+	case *MapVariable:
+		for name, property := range s.Properties {
+			if existing, ok := val.Values[name]; ok {
+				property.fillDefaults(existing)
+			} else if property.Default != nil {
+				val.Values[name] = convertToVariable(property.Default)
+			}
+		}
+	case *SliceVariable:
+		if s.Items != nil {
+			for _, item := range val.Values {
+				s.Items.fillDefaults(item)
+			}
+		}
+	}
+}
+
+// hasType reports whether a template data value is of a schema type
+func hasType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		number, ok := toFloat(value)
+		return ok && number == math.Trunc(number)
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// inEnum compares numbers by value, so 1 matches 1.0, and other values deeply
+func inEnum(value interface{}, enum []interface{}) bool {
+	number, isNumber := toFloat(value)
+	for _, allowed := range enum {
+		if other, ok := toFloat(allowed); ok && isNumber {
+			if number == other {
+				return true
+			}
+		} else if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		if s, ok := value.(string); ok {
+			values[i] = fmt.Sprintf("%q", s)
+		} else {
+			values[i] = fmt.Sprintf("%v", value)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const invoiceSchema = `{
+  "type": "object",
+  "required": ["client", "items"],
+  "properties": {
+    "client": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string", "minLength": 1},
+        "vat": {"type": "string", "pattern": "^[A-Z]{2}[0-9]+$"},
+        "country": {"type": "string", "default": "BR"}
+      }
+    },
+    "currency": {"type": "string", "enum": ["EUR", "USD"], "default": "EUR"},
+    "items": {
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "required": ["name", "price"],
+        "properties": {
+          "name": {"type": "string"},
+          "price": {"type": "number", "minimum": 0},
+          "qty": {"type": "integer", "minimum": 1, "default": 1}
+        }
+      }
+    }
+  }
+}`
+
+func loadInvoiceSchema(t *testing.T) *Schema {
+	t.Helper()
+	var schema Schema
+	if err := json.Unmarshal([]byte(invoiceSchema), &schema); err != nil {
+		t.Fatalf("Failed to unmarshal schema: %v", err)
+	}
+	if err := schema.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	return &schema
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := loadInvoiceSchema(t)
+
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		expected []FieldError
+	}{
+		{
+			name: "valid",
+			data: map[string]interface{}{
+				"client":   map[string]interface{}{"name": "ACME", "vat": "PT123"},
+				"currency": "USD",
+				"items":    []interface{}{map[string]interface{}{"name": "Pen", "price": 1.5, "qty": 2}},
+			},
+		},
+		{
+			name: "missing required",
+			data: map[string]interface{}{"client": map[string]interface{}{}},
+			expected: []FieldError{
+				{Field: "items", Message: "is required"},
+				{Field: "client.name", Message: "is required"},
+			},
+		},
+		{
+			name: "item constraints",
+			data: map[string]interface{}{
+				"client": map[string]interface{}{"name": "ACME", "vat": "123"},
+				"items": []interface{}{
+					map[string]interface{}{"name": "Pen", "price": 1},
+					map[string]interface{}{"name": "Ink", "price": "free"},
+					map[string]interface{}{"name": "Pad", "price": -2, "qty": 1.5},
+				},
+			},
+			expected: []FieldError{
+				{Field: "client.vat", Message: "must match ^[A-Z]{2}[0-9]+$"},
+				{Field: "items[1].price", Message: "must be a number"},
+				{Field: "items[2].price", Message: "must be >= 0"},
+				{Field: "items[2].qty", Message: "must be an integer"},
+			},
+		},
+		{
+			name: "enum and lengths",
+			data: map[string]interface{}{
+				"client":   map[string]interface{}{"name": ""},
+				"currency": "BRL",
+				"items":    []interface{}{},
+			},
+			expected: []FieldError{
+				{Field: "client.name", Message: "must be at least 1 characters"},
+				{Field: "currency", Message: `must be one of "EUR", "USD"`},
+				{Field: "items", Message: "must have at least 1 items"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.Validate(tt.data)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Validate() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateAdditionalProperties(t *testing.T) {
+	closed := false
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{"title": {Type: "string"}},
+		AdditionalProperties: &closed,
+	}
+	got := schema.Validate(map[string]interface{}{"title": "Report", "subtitle": "Draft"})
+	expected := []FieldError{{Field: "subtitle", Message: "is not allowed"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Validate() = %v, want %v", got, expected)
+	}
+}
+
+func TestSchema_ValidateNilSchema(t *testing.T) {
+	var schema *Schema
+	if got := schema.Validate(map[string]interface{}{"title": 1}); got != nil {
+		t.Errorf("Expected a nil schema to accept any variables, got %v", got)
+	}
+}
+
+func TestSchema_ApplyDefaults(t *testing.T) {
+	schema := loadInvoiceSchema(t)
+	var vars Variables
+	if err := yaml.Unmarshal([]byte("client:\n  name: ACME\nitems:\n  - name: Pen\n    price: 1\n  - name: Ink\n    price: 2\n    qty: 3\n"), &vars); err != nil {
+		t.Fatalf("Failed to unmarshal variables: %v", err)
+	}
+
+	schema.ApplyDefaults(&vars)
+
+	data := vars.TemplateData()
+	if data["currency"] != "EUR" {
+		t.Errorf("Expected currency to default to EUR, got %v", data["currency"])
+	}
+	if country := data["client"].(map[string]interface{})["country"]; country != "BR" {
+		t.Errorf("Expected client.country to default to BR, got %v", country)
+	}
+	items := data["items"].([]interface{})
+	for i, want := range []int{1, 3} {
+		if qty := items[i].(map[string]interface{})["qty"]; qty != want {
+			t.Errorf("Expected items[%d].qty to be %d, got %v", i, want, qty)
+		}
+	}
+	if fields := schema.Validate(vars.TemplateData()); len(fields) > 0 {
+		t.Errorf("Expected the defaults to satisfy the schema, got %v", fields)
+	}
+}
+
+func TestSchema_Check(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *Schema
+	}{
+		{"unknown type", &Schema{Properties: map[string]*Schema{"total": {Type: "money"}}}},
+		{"invalid pattern", &Schema{Items: &Schema{Pattern: "("}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schema.Check(); err == nil {
+				t.Error("Expected Check() to fail")
+			}
+		})
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "invoice.tex")
+
+	schema, err := ReadSchema(templatePath)
+	if err != nil || schema != nil {
+		t.Fatalf("Expected no schema without a schema file, got %v, %v", schema, err)
+	}
+
+	if got := SchemaPath(templatePath); got != filepath.Join(dir, "invoice.schema.json") {
+		t.Errorf("SchemaPath() = %q", got)
+	}
+	if err := os.WriteFile(SchemaPath(templatePath), []byte(invoiceSchema), 0644); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+	schema, err = ReadSchema(templatePath)
+	if err != nil {
+		t.Fatalf("ReadSchema() error = %v", err)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"client", "items"}) {
+		t.Errorf("Expected the schema's required properties, got %v", schema.Required)
+	}
+
+	if err := os.WriteFile(SchemaPath(templatePath), []byte(`{"type": "object", "properties": {"vat": {"pattern": "["}}}`), 0644); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+	if _, err := ReadSchema(templatePath); err == nil {
+		t.Error("Expected an invalid pattern to fail")
+	}
+}
+
+func TestNewConfigFromYAML_Schema(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(`
+template: invoice.tex
+variables:
+  total: -1
+schema:
+  type: object
+  properties:
+    total: {type: number, minimum: 0}
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+	fields := cfg.Schema.Validate(cfg.Variables.TemplateData())
+	expected := []FieldError{{Field: "total", Message: "must be >= 0"}}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Validate() = %v, want %v", fields, expected)
+	}
+	if got := (&SchemaError{Fields: fields}).Error(); got != "variables do not match the schema: total: must be >= 0" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVariablePrefix starts the names of environment variables that set template variables
+const EnvVariablePrefix = "AUTOPDF_VAR_"
+
+// DotEnvFile is the file of environment variables read next to the config file
+const DotEnvFile = ".env"
+
+// VariableSources are the sources of variables besides the config file. They are applied
+// over the config's variables in this order, each overriding the ones before it:
+//
+//  1. Files, YAML, JSON or TOML, in the order given; maps are merged key by key
+//  2. The variables of EnvFile, a .env file, with the prefix
+//  3. The environment variables with the prefix
+//  4. SetFiles, which set a variable to the content of a file
+//  5. Sets, which set a variable to a value
+//
+// Environment variables are named by their path, with __ between its parts:
+// AUTOPDF_VAR_CLIENT__NAME sets client.name and AUTOPDF_VAR_ITEMS__0__QTY sets items[0].qty.
+// Each part matches an existing key regardless of case, so AUTOPDF_VAR_CLIENT__VATID sets
+// client.vatId when the variables have it, and names a lower case key otherwise.
+// Values from the environment and Sets are read as YAML scalars: 3 is a number, true a boolean
+// and "3" a string. SetFiles are always strings, taken as written by Config.Interpolate
+type VariableSources struct {
+	Files     []string
+	EnvFile   string
+	EnvPrefix string   // EnvVariablePrefix when empty
+	Environ   []string // KEY=value pairs, as os.Environ returns them
+	SetFiles  []Override
+	Sets      []Override
+}
+
+// Override sets the variable at Path, such as client.name or items[0].qty, to Value
+type Override struct {
+	Path  string
+	Value string
+}
+
+// ParseOverride reads an override written as path=value
+func ParseOverride(arg string) (Override, error) {
+	path, value, ok := strings.Cut(arg, "=")
+	path = strings.TrimSpace(path)
+	if !ok || path == "" {
+		return Override{}, fmt.Errorf("invalid override %q: expected path=value", arg)
+	}
+	return Override{Path: path, Value: value}, nil
+}
+
+// Apply sets the variables of the sources over vars
+func (s VariableSources) Apply(vars *Variables) error {
+	if vars.VariableSet == nil {
+		vars.VariableSet = NewVariableSet()
+	}
+
+	for _, file := range s.Files {
+		fileVars, err := ReadVariablesFile(file)
+		if err != nil {
+			return err
+		}
+		vars.Merge(fileVars.VariableSet)
+	}
+
+	env := make(map[string]string)
+	if s.EnvFile != "" {
+		data, err := os.ReadFile(s.EnvFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", s.EnvFile, err)
+		}
+		dotEnv, err := ParseDotEnv(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.EnvFile, err)
+		}
+		for name, value := range dotEnv {
+			env[name] = value
+		}
+	}
+	for _, pair := range s.Environ {
+		if name, value, ok := strings.Cut(pair, "="); ok {
+			env[name] = value
+		}
+	}
+	prefix := s.EnvPrefix
+	if prefix == "" {
+		prefix = EnvVariablePrefix
+	}
+	for _, name := range sortedKeys(env) {
+		path, ok := envVariablePath(vars.VariableSet, name, prefix)
+		if !ok {
+			continue
+		}
+		if err := vars.SetByPath(path, scalarVariable(env[name])); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for _, override := range s.SetFiles {
+		content, err := os.ReadFile(override.Value)
+		if err != nil {
+			return fmt.Errorf("failed to read %s for %s: %w", override.Value, override.Path, err)
+		}
+		if err := vars.SetByPath(override.Path, &StringVariable{Value: string(content), Literal: true}); err != nil {
+			return fmt.Errorf("%s: %w", override.Path, err)
+		}
+	}
+	for _, override := range s.Sets {
+		if err := vars.SetByPath(override.Path, scalarVariable(override.Value)); err != nil {
+			return fmt.Errorf("%s: %w", override.Path, err)
+		}
+	}
+	return nil
+}
+
+// scalarVariable reads value as a YAML scalar: integers, floats and booleans keep their type,
+// and everything else, quoted values, dates and text that is not a scalar included, is a string
+func scalarVariable(value string) Variable {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil || len(doc.Content) != 1 {
+		return &StringVariable{Value: value}
+	}
+	node := doc.Content[0]
+	if node.Kind != yaml.ScalarNode {
+		return &StringVariable{Value: value}
+	}
+	switch node.ShortTag() {
+	case "!!int", "!!float", "!!bool":
+		var decoded interface{}
+		if err := node.Decode(&decoded); err == nil {
+			return convertToVariable(decoded)
+		}
+	case "!!str":
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			return &StringVariable{Value: node.Value}
+		}
+	}
+	return &StringVariable{Value: value}
+}
+
+// envVariablePath returns the variable path an environment variable with the prefix sets.
+// Each part names the key of vars it matches regardless of case, or is lower case
+func envVariablePath(vars *VariableSet, name, prefix string) (string, bool) {
+	if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+		return "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(name, prefix), "__")
+	keys, lookup := vars.Keys(), vars.Get
+	for i, part := range parts {
+		parts[i] = matchKey(keys, part)
+		next, _ := lookup(parts[i])
+		keys, lookup = childKeys(next)
+	}
+	return strings.Join(parts, "."), true
+}
+
+// childKeys returns the keys of a map or the indexes of a slice, and how to look them up
+func childKeys(v Variable) ([]string, func(string) (Variable, bool)) {
+	switch v := v.(type) {
+	case *MapVariable:
+		return sortedKeys(v.Values), func(key string) (Variable, bool) {
+			child, ok := v.Values[key]
+			return child, ok
+		}
+	case *SliceVariable:
+		indexes := make([]string, len(v.Values))
+		for i := range indexes {
+			indexes[i] = strconv.Itoa(i)
+		}
+		return indexes, func(key string) (Variable, bool) {
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v.Values) {
+				return nil, false
+			}
+			return v.Values[i], true
+		}
+	}
+	return nil, func(string) (Variable, bool) { return nil, false }
+}
+
+// matchKey returns the key equal to part regardless of case, preferring the lower case
+// spelling and then the first in order, or part in lower case when no key matches
+func matchKey(keys []string, part string) string {
+	lower := strings.ToLower(part)
+	sort.Strings(keys)
+	match := ""
+	for _, key := range keys {
+		if key == lower {
+			return key
+		}
+		if match == "" && strings.EqualFold(key, part) {
+			match = key
+		}
+	}
+	if match == "" {
+		return lower
+	}
+	return match
+}
+
+// ReadVariablesFile reads the variables of a YAML, JSON or TOML file, by its extension
+func ReadVariablesFile(path string) (*Variables, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables file: %w", err)
+	}
+
+	vars := NewVariables()
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml", ".json":
+		// JSON is YAML, so one decoder reads both
+		if err := yaml.Unmarshal(data, vars); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		var values map[string]interface{}
+		if _, err := toml.Decode(string(data), &values); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for name, value := range values {
+			vars.Set(name, tomlVariable(value))
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported variables file type %q: use .yaml, .yml, .json or .toml", path, ext)
+	}
+	return vars, nil
+}
+
+// tomlVariable converts a value decoded from TOML, where integers are int64, arrays of
+// tables []map[string]interface{} and dates and times time.Time, printed as they are written
+func tomlVariable(value interface{}) Variable {
+	switch v := value.(type) {
+	case int64:
+		return &NumberVariable{Value: float64(v)}
+	case time.Time:
+		return &StringVariable{Value: v.Format(tomlTimeLayout(v.Location().String()))}
+	case []map[string]interface{}:
+		slice := NewSliceVariable()
+		for _, item := range v {
+			slice.Values = append(slice.Values, tomlVariable(item))
+		}
+		return slice
+	case []interface{}:
+		slice := NewSliceVariable()
+		for _, item := range v {
+			slice.Values = append(slice.Values, tomlVariable(item))
+		}
+		return slice
+	case map[string]interface{}:
+		m := NewMapVariable()
+		for key, item := range v {
+			m.Values[key] = tomlVariable(item)
+		}
+		return m
+	}
+	return convertToVariable(value)
+}
+
+// tomlTimeLayout returns the layout of a TOML date or time by the zone the decoder gives it:
+// local dates, times and date-times have no offset
+func tomlTimeLayout(zone string) string {
+	switch zone {
+	case "date-local":
+		return time.DateOnly
+	case "time-local":
+		return "15:04:05.999999999"
+	case "datetime-local":
+		return "2006-01-02T15:04:05.999999999"
+	}
+	return time.RFC3339Nano
+}
+
+// ParseDotEnv reads the KEY=value lines of a .env file. Blank lines and lines starting
+// with # are skipped, export before a key is ignored, and a value may be quoted:
+// "..." understands \n, \t, \" and \\, '...' is taken as written
+func ParseDotEnv(data []byte) (map[string]string, error) {
+	env := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", i+1)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, `"`):
+			end := closingQuote(value)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", i+1)
+			}
+			unquoted, err := strconv.Unquote(value[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			value = unquoted
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", i+1)
+			}
+			value = value[1 : end+1]
+		default:
+			// A comment after an unquoted value starts with whitespace
+			if comment := strings.Index(value, " #"); comment >= 0 {
+				value = strings.TrimSpace(value[:comment])
+			}
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// closingQuote returns the index of the quote that closes the "..." value starts with
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestVariableSources_Apply(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := NewConfigFromYAML([]byte(`
+variables:
+  title: Report
+  version: "0.0.0"
+  client:
+    name: ACME
+    city: Lisbon
+    vatId: PT0
+  items:
+    - name: Pen
+      qty: 1
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+
+	sources := VariableSources{
+		Files: []string{
+			writeFile(t, dir, "client.json", `{"client": {"city": "Porto", "vat": "PT1"}, "date": "from json"}`),
+			writeFile(t, dir, "release.toml", "date = \"from toml\"\nstage = \"from toml\"\n"),
+		},
+		EnvFile: writeFile(t, dir, ".env", "AUTOPDF_VAR_STAGE=from dotenv\nAUTOPDF_VAR_AUTHOR=from dotenv\nOTHER=ignored\n"),
+		Environ: []string{"AUTOPDF_VAR_AUTHOR=from env", "AUTOPDF_VAR_CLIENT__NAME=ACME Corp", "AUTOPDF_VAR_ITEMS__0__QTY=3", "AUTOPDF_VAR_CLIENT__VATID=PT2", "HOME=/root"},
+		SetFiles: []Override{
+			{Path: "notes", Value: writeFile(t, dir, "notes.txt", "Line one\nLine two")},
+		},
+		Sets: []Override{
+			{Path: "version", Value: "1.4.2"},
+			{Path: "items[1].name", Value: "Ink"},
+		},
+	}
+	if err := sources.Apply(&cfg.Variables); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"title":         "Report",
+		"version":       "1.4.2",
+		"client.name":   "ACME Corp",
+		"client.city":   "Porto",
+		"client.vat":    "PT1",
+		"client.vatId":  "PT2",
+		"date":          "from toml",
+		"stage":         "from dotenv",
+		"author":        "from env",
+		"items[0].name": "Pen",
+		"items[0].qty":  "3",
+		"items[1].name": "Ink",
+		"notes":         "Line one\nLine two",
+	}
+	if got := cfg.Variables.Flatten(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Flatten() = %v, want %v", got, expected)
+	}
+	if _, ok := cfg.Variables.Get("other"); ok {
+		t.Error("Expected environment variables without the prefix to be ignored")
+	}
+}
+
+func TestVariableSources_ApplyTypedValues(t *testing.T) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(`{
+  "type": "object",
+  "properties": {
+    "copies": {"type": "integer", "minimum": 1},
+    "rate": {"type": "number"},
+    "draft": {"type": "boolean"},
+    "code": {"type": "string"},
+    "date": {"type": "string"},
+    "notes": {"type": "string"}
+  }
+}`), &schema); err != nil {
+		t.Fatalf("Failed to unmarshal schema: %v", err)
+	}
+
+	vars := NewVariables()
+	sources := VariableSources{
+		Environ: []string{"AUTOPDF_VAR_RATE=0.25", "AUTOPDF_VAR_DRAFT=true"},
+		SetFiles: []Override{
+			{Path: "notes", Value: writeFile(t, t.TempDir(), "notes.txt", "42")},
+		},
+		Sets: []Override{
+			{Path: "copies", Value: "3"},
+			{Path: "code", Value: `"007"`},
+			{Path: "date", Value: "2025-01-02"},
+		},
+	}
+	if err := sources.Apply(vars); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	data := vars.TemplateData()
+	if errs := schema.Validate(data); len(errs) > 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+	expected := map[string]interface{}{
+		"copies": 3,
+		"rate":   0.25,
+		"draft":  true,
+		"code":   "007",
+		"date":   "2025-01-02",
+		"notes":  "42",
+	}
+	for name, want := range expected {
+		if got := data[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %#v, want %#v", name, got, want)
+		}
+	}
+}
+
+func TestVariableSources_ApplyErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		sources VariableSources
+	}{
+		{"missing file", VariableSources{Files: []string{filepath.Join(dir, "missing.yaml")}}},
+		{"unsupported file", VariableSources{Files: []string{writeFile(t, dir, "vars.ini", "a=1")}}},
+		{"missing set file", VariableSources{SetFiles: []Override{{Path: "notes", Value: filepath.Join(dir, "missing.txt")}}}},
+		{"index out of range", VariableSources{Sets: []Override{{Path: "items[5].name", Value: "Ink"}}}},
+		{"path through a string", VariableSources{Sets: []Override{{Path: "title.text", Value: "Report"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := NewVariables()
+			if err := vars.SetByPath("title", &StringVariable{Value: "Report"}); err != nil {
+				t.Fatalf("SetByPath() error = %v", err)
+			}
+			if err := vars.SetByPath("items", &SliceVariable{Values: []Variable{NewMapVariable()}}); err != nil {
+				t.Fatalf("SetByPath() error = %v", err)
+			}
+			if err := tt.sources.Apply(vars); err == nil {
+				t.Error("Expected Apply() to fail")
+			}
+		})
+	}
+}
+
+func TestReadVariablesFile_TOML(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "vars.toml", `
+# Invoice
+title = "Invoice \"2025\"\tQ1"
+path = 'C:\invoices'
+total = 1_250
+rate = 0.23
+paid = false
+issued = 2025-01-02
+sent = 2025-01-02T09:30:00
+tags = ["urgent", 'draft']
+client.name = "ACME"
+
+[company]
+address = { city = "Lisbon", zip = "1000" }
+
+[[items]]
+name = "Pen"
+qty = 2
+
+[[items]]
+name = "Ink"
+`)
+	vars, err := ReadVariablesFile(path)
+	if err != nil {
+		t.Fatalf("ReadVariablesFile() error = %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"title":   "Invoice \"2025\"\tQ1",
+		"path":    `C:\invoices`,
+		"total":   1250,
+		"rate":    0.23,
+		"paid":    false,
+		"issued":  "2025-01-02",
+		"sent":    "2025-01-02T09:30:00",
+		"tags":    []interface{}{"urgent", "draft"},
+		"client":  map[string]interface{}{"name": "ACME"},
+		"company": map[string]interface{}{"address": map[string]interface{}{"city": "Lisbon", "zip": "1000"}},
+		"items": []interface{}{
+			map[string]interface{}{"name": "Pen", "qty": 2},
+			map[string]interface{}{"name": "Ink"},
+		},
+	}
+	if got := vars.TemplateData(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("TemplateData() = %v, want %v", got, expected)
+	}
+
+	if _, err := ReadVariablesFile(writeFile(t, t.TempDir(), "bad.toml", "a = 1\na = 2")); err == nil {
+		t.Error("Expected a duplicate key to fail")
+	}
+}
+
+func TestParseOverride(t *testing.T) {
+	override, err := ParseOverride("build.date=2025-01-02=x")
+	if err != nil {
+		t.Fatalf("ParseOverride() error = %v", err)
+	}
+	if override != (Override{Path: "build.date", Value: "2025-01-02=x"}) {
+		t.Errorf("ParseOverride() = %+v", override)
+	}
+	for _, arg := range []string{"version", "=1.0"} {
+		if _, err := ParseOverride(arg); err == nil {
+			t.Errorf("Expected ParseOverride(%q) to fail", arg)
+		}
+	}
+}
+
+func TestParseDotEnv(t *testing.T) {
+	env, err := ParseDotEnv([]byte(`
+# Release
+export VERSION=1.4.2
+TITLE = Annual report  # shown on the cover
+QUOTED="Line one\nsaid \"hi\" # not a comment"
+LITERAL='C:\path\n'
+EMPTY=
+`))
+	if err != nil {
+		t.Fatalf("ParseDotEnv() error = %v", err)
+	}
+	expected := map[string]string{
+		"VERSION": "1.4.2",
+		"TITLE":   "Annual report",
+		"QUOTED":  "Line one\nsaid \"hi\" # not a comment",
+		"LITERAL": `C:\path\n`,
+		"EMPTY":   "",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("ParseDotEnv() = %v, want %v", env, expected)
+	}
+
+	for _, data := range []string{"NOVALUE", `A="open`, "A='open"} {
+		if _, err := ParseDotEnv([]byte(data)); err == nil {
+			t.Errorf("Expected ParseDotEnv(%q) to fail", data)
+		}
+	}
+}
+
+func TestVariableSet_SetByPathIndexes(t *testing.T) {
+	vars := NewVariables()
+	if err := vars.SetByPath("authors", &SliceVariable{Values: []Variable{&StringVariable{Value: "Ada"}}}); err != nil {
+		t.Fatalf("SetByPath() error = %v", err)
+	}
+	for path, value := range map[string]string{"authors[0]": "Grace", "authors[1]": "Alan"} {
+		if err := vars.SetByPath(path, &StringVariable{Value: value}); err != nil {
+			t.Fatalf("SetByPath(%q) error = %v", path, err)
+		}
+	}
+	for path, want := range map[string]string{"authors[0]": "Grace", "authors.1": "Alan"} {
+		if got, ok := vars.GetString(path); !ok || got != want {
+			t.Errorf("GetString(%q) = %q, %v, want %q", path, got, ok, want)
+		}
+	}
+}
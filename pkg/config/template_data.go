@@ -0,0 +1,52 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import "math"
+
+// TemplateValue converts a variable to the value templates operate on, keeping its type:
+// strings, bools, numbers, []interface{} for slices and map[string]interface{} for maps
+// Whole numbers become int, so they print without a decimal point and compare with integer literals
+func TemplateValue(v Variable) interface{} {
+	switch val := v.(type) {
+	case *StringVariable:
+		return val.Value
+	case *BoolVariable:
+		return val.Value
+	case *NumberVariable:
+		if val.Value == math.Trunc(val.Value) && math.Abs(val.Value) < 1<<53 {
+			return int(val.Value)
+		}
+		return val.Value
+	case *MapVariable:
+		result := make(map[string]interface{}, len(val.Values))
+		for k, nested := range val.Values {
+			result[k] = TemplateValue(nested)
+		}
+		return result
+	case *SliceVariable:
+		result := make([]interface{}, len(val.Values))
+		for i, item := range val.Values {
+			result[i] = TemplateValue(item)
+		}
+		return result
+	case nil:
+		return nil
+	default:
+		return val.String()
+	}
+}
+
+// TemplateData returns the variables as the data a template is executed with,
+// one entry per top-level variable
+func (vs *VariableSet) TemplateData() map[string]interface{} {
+	data := make(map[string]interface{})
+	if vs == nil {
+		return data
+	}
+	for name, value := range vs.variables {
+		data[name] = TemplateValue(value)
+	}
+	return data
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVariableSet_TemplateData(t *testing.T) {
+	cfg, err := NewConfigFromYAML([]byte(`
+variables:
+  title: "Report"
+  draft: false
+  pages: 12
+  rate: 80.5
+  client:
+    name: "ACME"
+    tags: ["a", "b"]
+`))
+	if err != nil {
+		t.Fatalf("NewConfigFromYAML() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"title": "Report",
+		"draft": false,
+		"pages": 12,
+		"rate":  80.5,
+		"client": map[string]interface{}{
+			"name": "ACME",
+			"tags": []interface{}{"a", "b"},
+		},
+	}
+	if got := cfg.Variables.TemplateData(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateData() = %#v, want %#v", got, want)
+	}
+
+	var nilSet *VariableSet
+	if got := nilSet.TemplateData(); got == nil || len(got) != 0 {
+		t.Errorf("TemplateData() on nil set = %#v, want empty map", got)
+	}
+}
+
+func TestTemplateValue_LargeNumbers(t *testing.T) {
+	if got := TemplateValue(&NumberVariable{Value: 1e6}); got != 1000000 {
+		t.Errorf("TemplateValue(1e6) = %#v, want int 1000000", got)
+	}
+	if got := TemplateValue(&NumberVariable{Value: 1e20}); got != 1e20 {
+		t.Errorf("TemplateValue(1e20) = %#v, want float64", got)
+	}
+}
@@ -47,7 +47,8 @@ const (
 
 // StringVariable represents a simple string variable
 type StringVariable struct {
-	Value string
+	Value   string
+	Literal bool `yaml:"-"` // Taken as written by Config.Interpolate, as the content of files and templates is
 }
 
 func (v StringVariable) String() string {
@@ -65,6 +66,7 @@ func (v *StringVariable) Set(path string, value Variable) error {
 	if path == "" {
 		if strVar, ok := value.(*StringVariable); ok {
 			v.Value = strVar.Value
+			v.Literal = strVar.Literal
 			return nil
 		}
 		return fmt.Errorf("cannot set string variable to non-string value")
@@ -119,27 +121,6 @@ func (v MapVariable) Get(path string) (Variable, bool) {
 
 	key := parts[0]
 
-	// Check if the key contains array access (e.g., "tags[0]")
-	if strings.Contains(key, "[") && strings.Contains(key, "]") {
-		// Extract the base key and array index
-		baseKey := strings.Split(key, "[")[0]
-		indexStr := strings.TrimSuffix(strings.Split(key, "[")[1], "]")
-
-		// Get the base value
-		if baseVal, exists := v.Values[baseKey]; exists {
-			// Try to access the array index
-			if sliceVar, ok := baseVal.(*SliceVariable); ok {
-				if index, err := strconv.Atoi(indexStr); err == nil && index >= 0 && index < len(sliceVar.Values) {
-					if len(parts) == 1 {
-						return sliceVar.Values[index], true
-					}
-					return sliceVar.Values[index].Get(strings.Join(parts[1:], "."))
-				}
-			}
-		}
-		return nil, false
-	}
-
 	// Regular key access
 	if val, exists := v.Values[key]; exists {
 		if len(parts) == 1 {
@@ -265,11 +246,18 @@ func (v *SliceVariable) Set(path string, value Variable) error {
 		return fmt.Errorf("empty path")
 	}
 
-	// Check if first part is an index
+	// Check if first part is an index; the index after the last item appends one
 	if index, err := strconv.Atoi(parts[0]); err == nil {
-		if index < 0 || index >= len(v.Values) {
+		if index < 0 || index > len(v.Values) {
 			return fmt.Errorf("index %d out of range", index)
 		}
+		if index == len(v.Values) {
+			if len(parts) == 1 {
+				v.Values = append(v.Values, value)
+				return nil
+			}
+			v.Values = append(v.Values, NewMapVariable())
+		}
 		if len(parts) == 1 {
 			v.Values[index] = value
 			return nil
@@ -405,42 +393,31 @@ func (v *BoolVariable) UnmarshalJSON(data []byte) error {
 
 // Helper functions
 
-// parsePath parses a path like "foo.bar[0].baz" into ["foo", "bar[0]", "baz"]
+// parsePath parses a path like "foo.bar[0].baz" into ["foo", "bar", "0", "baz"]
+// An index in brackets is a segment of its own, as in "foo.bar.0.baz"
 func parsePath(path string) []string {
 	if path == "" {
 		return nil
 	}
 
-	// Handle array indices in brackets
 	var parts []string
 	var current strings.Builder
-	inBrackets := false
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
 
 	for _, char := range path {
 		switch char {
-		case '.':
-			if !inBrackets {
-				if current.Len() > 0 {
-					parts = append(parts, current.String())
-					current.Reset()
-				}
-			} else {
-				current.WriteRune(char)
-			}
-		case '[':
-			inBrackets = true
-			current.WriteRune(char)
-		case ']':
-			inBrackets = false
-			current.WriteRune(char)
+		case '.', '[', ']':
+			flush()
 		default:
 			current.WriteRune(char)
 		}
 	}
-
-	if current.Len() > 0 {
-		parts = append(parts, current.String())
-	}
+	flush()
 
 	return parts
 }
@@ -584,6 +561,34 @@ func (vs *VariableSet) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Merge sets the variables of other over these: maps are merged key by key,
+// any other value replaces the one it overrides
+func (vs *VariableSet) Merge(other *VariableSet) {
+	if other == nil {
+		return
+	}
+	for name, value := range other.variables {
+		vs.variables[name] = mergeVariable(vs.variables[name], value)
+	}
+}
+
+// mergeVariable returns override merged over base
+func mergeVariable(base, override Variable) Variable {
+	baseMap, ok := base.(*MapVariable)
+	overrideMap, isMap := override.(*MapVariable)
+	if !ok || !isMap {
+		return override
+	}
+	merged := NewMapVariable()
+	for key, value := range baseMap.Values {
+		merged.Values[key] = value
+	}
+	for key, value := range overrideMap.Values {
+		merged.Values[key] = mergeVariable(merged.Values[key], value)
+	}
+	return merged
+}
+
 // RangeVariables provides iteration over variables
 func (vs *VariableSet) Range(fn func(name string, value Variable) bool) {
 	for name, value := range vs.variables {
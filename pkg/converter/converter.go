@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/BuddhiLW/AutoPDF/pkg/config"
+	"github.com/BuddhiLW/AutoPDF/pkg/latex"
 )
 
 // Converter converts Go values to AutoPDF Variables
@@ -194,6 +195,9 @@ func (sc *StructConverter) convertValue(val reflect.Value, tag FieldTag) (config
 	// Handle different kinds
 	switch val.Kind() {
 	case reflect.String:
+		if tag.Markdown {
+			return convertMarkdown(val.String(), tag.MarkdownOptions())
+		}
 		return &config.StringVariable{Value: val.String()}, nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return &config.NumberVariable{Value: float64(val.Int())}, nil
@@ -220,6 +224,20 @@ func (sc *StructConverter) convertValue(val reflect.Value, tag FieldTag) (config
 	}
 }
 
+// convertMarkdown converts the Markdown of a field tagged markdown to escaped LaTeX,
+// which templates print with raw: delim[[raw .description]]
+func convertMarkdown(source, spec string) (config.Variable, error) {
+	options, err := latex.ParseMarkdownOptions(spec)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := latex.Markdown(source, options)
+	if err != nil {
+		return nil, err
+	}
+	return &config.StringVariable{Value: converted}, nil
+}
+
 // convertStructField converts a struct field
 func (sc *StructConverter) convertStructField(val reflect.Value, tag FieldTag) (config.Variable, error) {
 	// Check if it implements AutoPDFFormattable
@@ -133,6 +133,15 @@ func TestParseTag(t *testing.T) {
 				Options:   []string{"flatten", "omitempty"},
 			},
 		},
+		{
+			name: "markdown with options",
+			tag:  "body,markdown=subsection restricted",
+			expected: FieldTag{
+				Name:     "body",
+				Markdown: true,
+				Options:  []string{"markdown=subsection restricted"},
+			},
+		},
 		{
 			name: "empty tag",
 			tag:  "",
@@ -176,6 +185,41 @@ func TestStructConverter_ConvertStruct_Basic(t *testing.T) {
 	assert.Equal(t, "john@example.com", emailVar.String())
 }
 
+func TestStructConverter_ConvertStruct_Markdown(t *testing.T) {
+	converter := NewStructConverter()
+
+	product := struct {
+		Name        string `autopdf:"name"`
+		Description string `autopdf:"description,markdown"`
+		Review      string `autopdf:"review,markdown=subsection restricted"`
+	}{
+		Name:        "**Pen** & ink",
+		Description: "# Pen\nWrites *well*",
+		Review:      "# Great\n[more](javascript:alert)",
+	}
+
+	variables, err := converter.ConvertStruct(product)
+	require.NoError(t, err)
+
+	nameVar, exists := variables.Get("name")
+	require.True(t, exists)
+	assert.Equal(t, "**Pen** & ink", nameVar.String())
+
+	descriptionVar, exists := variables.Get("description")
+	require.True(t, exists)
+	assert.Equal(t, "\\section{Pen}\n\nWrites \\emph{well}", descriptionVar.String())
+
+	reviewVar, exists := variables.Get("review")
+	require.True(t, exists)
+	assert.Equal(t, "Great\n\nmore", reviewVar.String())
+
+	bad := struct {
+		Body string `autopdf:"body,markdown=h1"`
+	}{Body: "text"}
+	_, err = converter.ConvertStruct(bad)
+	assert.Error(t, err)
+}
+
 func TestStructConverter_ConvertStruct_Nested(t *testing.T) {
 	converter := NewStructConverter()
 
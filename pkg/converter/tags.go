@@ -14,6 +14,7 @@ type FieldTag struct {
 	OmitEmpty bool     // Omit if empty value
 	Flatten   bool     // Flatten nested structures
 	Inline    bool     // Inline nested struct fields
+	Markdown  bool     // Convert Markdown text to LaTeX, see MarkdownOptions
 	Options   []string // Additional options
 }
 
@@ -24,6 +25,8 @@ type FieldTag struct {
 //	autopdf:"field_name,omitempty"
 //	autopdf:"field_name,flatten"
 //	autopdf:"field_name,inline"
+//	autopdf:"field_name,markdown"
+//	autopdf:"field_name,markdown=subsection restricted"
 //	autopdf:"-" (skip field)
 func ParseTag(tag string) FieldTag {
 	if tag == "" {
@@ -59,6 +62,12 @@ func ParseTag(tag string) FieldTag {
 			fieldTag.Flatten = true
 		case "inline":
 			fieldTag.Inline = true
+		case "markdown":
+			fieldTag.Markdown = true
+		default:
+			if strings.HasPrefix(option, "markdown=") {
+				fieldTag.Markdown = true
+			}
 		}
 	}
 
@@ -91,6 +100,17 @@ func (ft FieldTag) HasOption(option string) bool {
 	return false
 }
 
+// MarkdownOptions returns the options of markdown=: a heading command for # and
+// "restricted", separated by spaces
+func (ft FieldTag) MarkdownOptions() string {
+	for _, opt := range ft.Options {
+		if spec, ok := strings.CutPrefix(opt, "markdown="); ok {
+			return spec
+		}
+	}
+	return ""
+}
+
 // IsEmpty returns true if the tag is empty (no name and no options)
 func (ft FieldTag) IsEmpty() bool {
 	return ft.Name == "" && len(ft.Options) == 0 && !ft.Omit
@@ -3,6 +3,11 @@
 
 package errors
 
+import (
+	"errors"
+	"strings"
+)
+
 // DomainErrorFactory builds common AutoPDF errors with context
 type DomainErrorFactory struct {
 	formatter StringFormatter
@@ -17,8 +22,10 @@ func NewDomainErrorFactory(formatter StringFormatter) *DomainErrorFactory {
 
 // Document processing errors
 
+// TemplateProcessingFailed builds the template error; when cause is a VariableMissing error,
+// the variable's location is blamed and its details attached
 func (f *DomainErrorFactory) TemplateProcessingFailed(templatePath string, cause error) error {
-	return NewInternalError(
+	builder := NewInternalError(
 		"TEMPLATE_PROCESSING_FAILED",
 		"Failed to process template with variables",
 	).WithBlame(f.formatter.Format("templatePath: %s", templatePath)).
@@ -29,14 +36,27 @@ func (f *DomainErrorFactory) TemplateProcessingFailed(templatePath string, cause
 			"Verify all required variables are provided",
 			"Check template syntax for errors",
 			"Ensure template file permissions are correct",
-		).Build()
+		)
+
+	var missing *DomainError
+	if errors.As(cause, &missing) && missing.Code == CodeVariableMissing {
+		builder = builder.WithBlame(missing.Blame).WithDetails(missing.Details)
+	}
+	return builder.Build()
 }
 
-func (f *DomainErrorFactory) LaTeXCompilationFailed(outputPath string, cause error) error {
-	return NewInternalError(
+// LaTeXCompilationFailed builds the compilation error; when the engine log was parsed,
+// the first diagnostic is blamed and all of them are attached as details
+func (f *DomainErrorFactory) LaTeXCompilationFailed(outputPath string, cause error, diagnostics ...string) error {
+	blame := f.formatter.Format("outputPath: %s", outputPath)
+	if len(diagnostics) > 0 {
+		blame = diagnostics[0]
+	}
+
+	builder := NewInternalError(
 		"LATEX_COMPILATION_FAILED",
 		"Failed to compile LaTeX content to PDF",
-	).WithBlame(f.formatter.Format("outputPath: %s", outputPath)).
+	).WithBlame(blame).
 		WithDetail("output_path", outputPath).
 		WithCause(cause).
 		WithSuggestions(
@@ -44,7 +64,11 @@ func (f *DomainErrorFactory) LaTeXCompilationFailed(outputPath string, cause err
 			"Verify all required LaTeX packages are installed",
 			"Check if the output directory is writable",
 			"Review LaTeX compilation logs for specific errors",
-		).Build()
+		)
+	if len(diagnostics) > 0 {
+		builder = builder.WithDetail("diagnostics", diagnostics)
+	}
+	return builder.Build()
 }
 
 func (f *DomainErrorFactory) PDFConversionFailed(pdfPath string, cause error) error {
@@ -77,13 +101,27 @@ func (f *DomainErrorFactory) CleanupFailed(pdfPath string, cause error) error {
 		).Build()
 }
 
+func (f *DomainErrorFactory) WorkspaceSetupFailed(templatePath string, cause error) error {
+	return NewInternalError(
+		"WORKSPACE_SETUP_FAILED",
+		"Failed to prepare the build workspace",
+	).WithBlame(f.formatter.Format("templatePath: %s", templatePath)).
+		WithDetail("template_path", templatePath).
+		WithCause(cause).
+		WithSuggestions(
+			"Check that the workspace root exists and is writable",
+			"Check that the template directory is readable",
+			"Check available disk space",
+		).Build()
+}
+
 // Configuration and validation errors
 
 func (f *DomainErrorFactory) EngineInvalid(engine string) error {
 	return NewInvalidInputError("ENGINE_INVALID", "Invalid LaTeX engine").
 		WithDetail("engine", engine).
 		WithSuggestions(
-			"Use one of: pdflatex, xelatex, lualatex",
+			"Use one of: pdflatex, xelatex, lualatex, tectonic, typst",
 		).Build()
 }
 
@@ -92,20 +130,73 @@ func (f *DomainErrorFactory) OutputPathEmpty() error {
 		WithSuggestions("Provide a valid output path").Build()
 }
 
+// OutputPathInvalid builds the error of an output path that cannot be rendered or created
+func (f *DomainErrorFactory) OutputPathInvalid(outputPath string, cause error) error {
+	return NewInvalidInputError("OUTPUT_PATH_INVALID", "Failed to resolve the output path").
+		WithBlame(f.formatter.Format("outputPath: %s", outputPath)).
+		WithDetail("output_path", outputPath).
+		WithCause(cause).
+		WithSuggestions(
+			"Check that the variables the output path refers to are defined",
+			"Check the output path's template syntax",
+			"Check that the output directory can be created",
+			"Use output_collision: fail, overwrite or suffix",
+		).Build()
+}
+
+// OutputExists builds the error of an output that already exists under the fail collision policy
+func (f *DomainErrorFactory) OutputExists(outputPath string) error {
+	return NewValidationError("OUTPUT_EXISTS", "Output file already exists").
+		WithBlame(f.formatter.Format("outputPath: %s", outputPath)).
+		WithDetail("output_path", outputPath).
+		WithSuggestions(
+			"Remove or move the existing file",
+			"Use output_collision: overwrite to replace it, or suffix to write next to it",
+		).Build()
+}
+
 func (f *DomainErrorFactory) TemplatePathEmpty() error {
 	return NewInvalidInputError("TEMPLATE_PATH_EMPTY", "Template path must not be empty").
 		WithSuggestions("Provide a valid template path").Build()
 }
 
-func (f *DomainErrorFactory) VariableMissing(variable, template string) error {
-	return NewValidationError("VARIABLE_MISSING", "Required template variable is missing").
-		WithDetails(map[string]interface{}{"variable": variable, "template": template}).
+// CodeVariableMissing is the code of VariableMissing errors
+const CodeVariableMissing = "VARIABLE_MISSING"
+
+// VariableMissing builds the error of a template referring to a variable that is not defined,
+// at line and column of the template file, whose text is snippet
+func (f *DomainErrorFactory) VariableMissing(variable, template string, line, column int, snippet string) error {
+	return NewValidationError(CodeVariableMissing, "Required template variable is missing").
+		WithBlame(f.formatter.Format("%s:%d:%d: %q in: %s", template, line, column, variable, strings.TrimSpace(snippet))).
+		WithDetails(map[string]interface{}{
+			"variable": variable,
+			"template": template,
+			"line":     line,
+			"column":   column,
+			"snippet":  snippet,
+		}).
 		WithSuggestions(
 			"Add the missing variable to the request",
 			"Check the template's required variables",
 		).Build()
 }
 
+// VariablesInvalid builds the error of variables the template's schema rejects,
+// with one "field: message" entry per field, such as "items[2].price: must be >= 0"
+func (f *DomainErrorFactory) VariablesInvalid(template string, fields []string) error {
+	builder := NewValidationError("VARIABLES_INVALID", "Variables do not match the template's schema").
+		WithDetail("template", template).
+		WithDetail("fields", fields).
+		WithSuggestions(
+			"Correct the listed variables",
+			"Check the template's schema for the expected types and values",
+		)
+	if len(fields) > 0 {
+		builder = builder.WithBlame(f.formatter.Format("%s: %s", template, fields[0]))
+	}
+	return builder.Build()
+}
+
 func (f *DomainErrorFactory) PassesInvalid(passes int) error {
 	return NewInvalidInputError("PASSES_INVALID", "Compilation passes must be between 1 and 10").
 		WithDetail("passes", passes).
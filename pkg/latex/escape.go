@@ -0,0 +1,42 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+// Package latex turns text into LaTeX: escaping, and converting Markdown
+// It is shared by the template engine and the struct converter
+package latex
+
+import "strings"
+
+var textReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`%`, `\%`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+)
+
+// Escape escapes the characters LaTeX treats as commands
+func Escape(s string) string {
+	return textReplacer.Replace(s)
+}
+
+// urlReplacer escapes what \url and \href interpret: \% and \# are understood by hyperref,
+// braces and backslashes cannot appear in a URL argument and are percent-encoded
+var urlReplacer = strings.NewReplacer(
+	`\`, `%5C`,
+	`{`, `%7B`,
+	`}`, `%7D`,
+	` `, `%20`,
+	`%`, `\%`,
+	`#`, `\#`,
+)
+
+// EscapeURL escapes a URL for the argument of \url or \href
+func EscapeURL(s string) string {
+	return urlReplacer.Replace(s)
+}
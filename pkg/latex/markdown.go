@@ -0,0 +1,339 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// headingCommands are the LaTeX sectioning commands, from the highest level down
+var headingCommands = []string{"part", "chapter", "section", "subsection", "subsubsection", "paragraph", "subparagraph"}
+
+// maxListDepth is how deeply LaTeX nests itemize and enumerate; deeper lists continue the deepest
+const maxListDepth = 4
+
+// romanCounters name the enumerate counters by nesting: enumi, enumii, ...
+var romanCounters = []string{"i", "ii", "iii", "iv"}
+
+// safeLinkSchemes are the link targets restricted Markdown keeps
+var safeLinkSchemes = []string{"http://", "https://", "mailto:"}
+
+// MarkdownOptions configure the conversion of Markdown to LaTeX
+type MarkdownOptions struct {
+	// Heading is the sectioning command of level-1 headings, such as section or subsection;
+	// deeper headings use the following commands
+	Heading string
+	// Restricted keeps paragraphs, lists, emphasis, inline code and http, https and mailto links,
+	// for text from untrusted sources: headings, code blocks, quotes and tables become paragraphs
+	Restricted bool
+}
+
+// DefaultMarkdownOptions render # as \section
+func DefaultMarkdownOptions() MarkdownOptions {
+	return MarkdownOptions{Heading: "section"}
+}
+
+// ParseMarkdownOptions reads options written as words separated by spaces or commas:
+// a heading command and "restricted", such as "subsection restricted"
+func ParseMarkdownOptions(spec string) (MarkdownOptions, error) {
+	options := DefaultMarkdownOptions()
+	for _, word := range strings.FieldsFunc(spec, func(r rune) bool { return r == ' ' || r == ',' }) {
+		switch {
+		case word == "restricted":
+			options.Restricted = true
+		case headingLevel(word) >= 0:
+			options.Heading = word
+		default:
+			return options, fmt.Errorf("unknown markdown option %q: use restricted or one of %s", word, strings.Join(headingCommands, ", "))
+		}
+	}
+	return options, nil
+}
+
+func headingLevel(command string) int {
+	for i, c := range headingCommands {
+		if c == command {
+			return i
+		}
+	}
+	return -1
+}
+
+// Markdown converts CommonMark, with tables, to LaTeX whose text is escaped:
+// emphasis, lists, links, headings, code, block quotes and tables become their LaTeX
+// counterparts, and links need hyperref. Images print their description and raw HTML
+// its text, so Markdown never reads files or injects commands
+func Markdown(source string, options MarkdownOptions) (string, error) {
+	if options.Heading == "" {
+		options.Heading = DefaultMarkdownOptions().Heading
+	}
+	if headingLevel(options.Heading) < 0 {
+		return "", fmt.Errorf("unknown heading command %q", options.Heading)
+	}
+
+	var extensions []goldmark.Extender
+	if !options.Restricted {
+		extensions = append(extensions, extension.Table)
+	}
+	src := []byte(source)
+	doc := goldmark.New(goldmark.WithExtensions(extensions...)).Parser().Parse(text.NewReader(src), parser.WithContext(parser.NewContext()))
+
+	r := &markdownRenderer{source: src, options: options}
+	r.blocks(doc)
+	return strings.TrimSpace(r.buf.String()), nil
+}
+
+// markdownRenderer writes the LaTeX of a Markdown tree
+type markdownRenderer struct {
+	buf     strings.Builder
+	source  []byte
+	options MarkdownOptions
+	depth   int // nesting of lists
+	enums   int // nesting of enumerate, which numbers its counters enumi to enumiv
+}
+
+// blocks writes the children of a block node, separated by blank lines
+func (r *markdownRenderer) blocks(n ast.Node) {
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		r.block(child)
+	}
+}
+
+func (r *markdownRenderer) block(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Paragraph:
+		r.inlines(n)
+		r.buf.WriteString("\n\n")
+	case *ast.TextBlock:
+		// The text of a tight list item
+		r.inlines(n)
+		r.buf.WriteString("\n")
+	case *ast.Heading:
+		if r.options.Restricted {
+			r.inlines(n)
+			r.buf.WriteString("\n\n")
+			return
+		}
+		level := headingLevel(r.options.Heading) + n.Level - 1
+		if level >= len(headingCommands) {
+			level = len(headingCommands) - 1
+		}
+		r.buf.WriteString(`\` + headingCommands[level] + "{")
+		r.inlines(n)
+		r.buf.WriteString("}\n\n")
+	case *ast.List:
+		r.list(n)
+	case *ast.Blockquote:
+		if r.options.Restricted {
+			r.blocks(n)
+			return
+		}
+		r.buf.WriteString("\\begin{quote}\n")
+		r.blocks(n)
+		r.buf.WriteString("\\end{quote}\n\n")
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		r.codeBlock(lines(n, r.source))
+	case *ast.ThematicBreak:
+		if !r.options.Restricted {
+			r.buf.WriteString("\\noindent\\rule{\\linewidth}{0.4pt}\n\n")
+		}
+	case *ast.HTMLBlock:
+		r.buf.WriteString(Escape(strings.TrimSpace(lines(n, r.source))))
+		r.buf.WriteString("\n\n")
+	case *east.Table:
+		r.table(n)
+	default:
+		r.blocks(n)
+	}
+}
+
+// list writes a list as itemize or enumerate; lists nested deeper than LaTeX allows
+// continue the list they are in
+func (r *markdownRenderer) list(n *ast.List) {
+	if r.depth >= maxListDepth {
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			r.buf.WriteString("\\item ")
+			r.blocks(item)
+		}
+		return
+	}
+
+	env := "itemize"
+	if n.IsOrdered() {
+		env = "enumerate"
+	}
+	r.depth++
+	r.buf.WriteString("\\begin{" + env + "}\n")
+	if n.IsOrdered() {
+		r.enums++
+		defer func() { r.enums-- }()
+		if n.Start > 1 {
+			fmt.Fprintf(&r.buf, "\\setcounter{enum%s}{%d}\n", romanCounters[r.enums-1], n.Start-1)
+		}
+	}
+	for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+		r.buf.WriteString("\\item ")
+		r.blocks(item)
+	}
+	r.buf.WriteString("\\end{" + env + "}\n\n")
+	r.depth--
+}
+
+// codeBlock writes code verbatim, or as escaped typewriter text when the code would end
+// the verbatim environment or the Markdown is restricted
+func (r *markdownRenderer) codeBlock(code string) {
+	code = strings.TrimRight(code, "\n")
+	if !r.options.Restricted && !strings.Contains(code, `\end{verbatim}`) {
+		r.buf.WriteString("\\begin{verbatim}\n" + code + "\n\\end{verbatim}\n\n")
+		return
+	}
+	codeLines := strings.Split(code, "\n")
+	for i, line := range codeLines {
+		codeLines[i] = `\texttt{` + Escape(line) + `}`
+	}
+	r.buf.WriteString("\\noindent " + strings.Join(codeLines, "\\\\\n") + "\n\n")
+}
+
+// table writes a tabular with the columns' alignments and a rule below the header
+func (r *markdownRenderer) table(n *east.Table) {
+	spec := make([]string, len(n.Alignments))
+	for i, alignment := range n.Alignments {
+		switch alignment {
+		case east.AlignCenter:
+			spec[i] = "c"
+		case east.AlignRight:
+			spec[i] = "r"
+		default:
+			spec[i] = "l"
+		}
+	}
+	r.buf.WriteString("\\begin{tabular}{" + strings.Join(spec, "") + "}\n\\hline\n")
+	for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			if cell != row.FirstChild() {
+				r.buf.WriteString(" & ")
+			}
+			if _, header := row.(*east.TableHeader); header {
+				r.buf.WriteString(`\textbf{`)
+				r.inlines(cell)
+				r.buf.WriteString("}")
+			} else {
+				r.inlines(cell)
+			}
+		}
+		r.buf.WriteString(" \\\\\n")
+		if _, header := row.(*east.TableHeader); header {
+			r.buf.WriteString("\\hline\n")
+		}
+	}
+	r.buf.WriteString("\\hline\n\\end{tabular}\n\n")
+}
+
+// inlines writes the inline children of a node
+func (r *markdownRenderer) inlines(n ast.Node) {
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		r.inline(child)
+	}
+}
+
+func (r *markdownRenderer) inline(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Text:
+		r.buf.WriteString(Escape(string(n.Segment.Value(r.source))))
+		switch {
+		case n.HardLineBreak():
+			r.buf.WriteString("\\\\\n")
+		case n.SoftLineBreak():
+			r.buf.WriteString("\n")
+		}
+	case *ast.String:
+		r.buf.WriteString(Escape(string(n.Value)))
+	case *ast.CodeSpan:
+		r.buf.WriteString(`\texttt{` + Escape(r.plainText(n)) + `}`)
+	case *ast.Emphasis:
+		command := `\emph{`
+		if n.Level >= 2 {
+			command = `\textbf{`
+		}
+		r.buf.WriteString(command)
+		r.inlines(n)
+		r.buf.WriteString("}")
+	case *ast.Link:
+		r.link(string(n.Destination), func() { r.inlines(n) })
+	case *ast.AutoLink:
+		url := string(n.URL(r.source))
+		if n.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(url, "mailto:") {
+			url = "mailto:" + url
+		}
+		label := Escape(string(n.Label(r.source)))
+		r.link(url, func() { r.buf.WriteString(label) })
+	case *ast.Image:
+		// Including files named by the text would let it read any file LaTeX can
+		r.inlines(n)
+	case *ast.RawHTML:
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			r.buf.WriteString(Escape(string(segment.Value(r.source))))
+		}
+	default:
+		r.inlines(n)
+	}
+}
+
+// link writes \href to url around the label, or the label alone when restricted
+// Markdown links to something other than http, https or mailto
+func (r *markdownRenderer) link(url string, label func()) {
+	if r.options.Restricted && !hasSafeScheme(url) {
+		label()
+		return
+	}
+	r.buf.WriteString(`\href{` + EscapeURL(url) + `}{`)
+	label()
+	r.buf.WriteString("}")
+}
+
+func hasSafeScheme(url string) bool {
+	lower := strings.ToLower(url)
+	for _, scheme := range safeLinkSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// plainText returns the text of a node's inline children, without formatting
+func (r *markdownRenderer) plainText(n ast.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch c := child.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(r.source))
+		case *ast.String:
+			b.Write(c.Value)
+		default:
+			b.WriteString(r.plainText(c))
+		}
+	}
+	return b.String()
+}
+
+// lines returns the source lines of a block node
+func lines(n ast.Node, source []byte) string {
+	var b strings.Builder
+	segments := n.Lines()
+	for i := 0; i < segments.Len(); i++ {
+		segment := segments.At(i)
+		b.Write(segment.Value(source))
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 AutoPDF BuddhiLW
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"emphasis", "Some *emphasis*, **bold** and `code_span`", `Some \emph{emphasis}, \textbf{bold} and \texttt{code\_span}`},
+		{"escaping", "50% of $10 & more_ {x} #1 \\o/", `50\% of \$10 \& more\_ \{x\} \#1 \textbackslash{}o/`},
+		{"paragraphs", "First\nline\n\nSecond", "First\nline\n\nSecond"},
+		{"hard break", "First  \nSecond", "First\\\\\nSecond"},
+		{"headings", "# Title\n## Part", "\\section{Title}\n\n\\subsection{Part}"},
+		{"list", "- one\n- *two*", "\\begin{itemize}\n\\item one\n\\item \\emph{two}\n\\end{itemize}"},
+		{"ordered list", "3. three\n4. four", "\\begin{enumerate}\n\\setcounter{enumi}{2}\n\\item three\n\\item four\n\\end{enumerate}"},
+		{"link", "[the site](https://example.com/a_b?x=1%20#top)", `\href{https://example.com/a_b?x=1\%20\#top}{the site}`},
+		{"autolink", "<https://example.com> <ada@example.com>", `\href{https://example.com}{https://example.com} \href{mailto:ada@example.com}{ada@example.com}`},
+		{"code block", "```\nif a < b { x_1 }\n```", "\\begin{verbatim}\nif a < b { x_1 }\n\\end{verbatim}"},
+		{"code block ending verbatim", "    \\end{verbatim}", `\noindent \texttt{\textbackslash{}end\{verbatim\}}`},
+		{"quote", "> quoted", "\\begin{quote}\nquoted\n\n\\end{quote}"},
+		{"image", "![a logo](/etc/passwd)", `a logo`},
+		{"html", "<b>bold</b>", `<b>bold</b>`},
+		{"table", "| Item | Price |\n|:-----|------:|\n| Pen & ink | 1.50 |",
+			"\\begin{tabular}{lr}\n\\hline\n\\textbf{Item} & \\textbf{Price} \\\\\n\\hline\nPen \\& ink & 1.50 \\\\\n\\hline\n\\end{tabular}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Markdown(tt.markdown, DefaultMarkdownOptions())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestMarkdown_HeadingLevel(t *testing.T) {
+	out, err := Markdown("# A\n### B\n##### C", MarkdownOptions{Heading: "subsection"})
+	require.NoError(t, err)
+	assert.Equal(t, "\\subsection{A}\n\n\\paragraph{B}\n\n\\subparagraph{C}", out)
+
+	_, err = Markdown("# A", MarkdownOptions{Heading: "title"})
+	assert.Error(t, err)
+}
+
+func TestMarkdown_DeepLists(t *testing.T) {
+	out, err := Markdown("- 1\n  - 2\n    - 3\n      - 4\n        - 5", DefaultMarkdownOptions())
+	require.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(out, `\begin{itemize}`))
+	assert.Equal(t, 5, strings.Count(out, `\item`))
+}
+
+func TestMarkdown_Restricted(t *testing.T) {
+	options := MarkdownOptions{Heading: "section", Restricted: true}
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"kept", "*a* [site](https://example.com) [mail](mailto:a@example.com)", `\emph{a} \href{https://example.com}{site} \href{mailto:a@example.com}{mail}`},
+		{"unsafe links", "[open](run:calc.exe) [file](file:///etc/passwd)", `open file`},
+		{"headings", "# Title", `Title`},
+		{"code block", "```\n\\input{x}\n```", `\noindent \texttt{\textbackslash{}input\{x\}}`},
+		{"table", "| a |\n|---|\n| b |", `| a |` + "\n" + `|---|` + "\n" + `| b |`},
+		{"quote", "> quoted", `quoted`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Markdown(tt.markdown, options)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestParseMarkdownOptions(t *testing.T) {
+	options, err := ParseMarkdownOptions("subsection, restricted")
+	require.NoError(t, err)
+	assert.Equal(t, MarkdownOptions{Heading: "subsection", Restricted: true}, options)
+
+	options, err = ParseMarkdownOptions("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMarkdownOptions(), options)
+
+	_, err = ParseMarkdownOptions("h2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,23 @@
+// AutoPDF Typst example: variables use double-brace delimiters and values are escaped for where they print
+#set page(paper: "a4", margin: 2cm)
+#set text(size: 11pt)
+
+#grid(
+  columns: (1fr, auto),
+  [*{{ .company }}*],
+  align(right)[Invoice *{{ .number }}* \ {{ .date }}],
+)
+
+= Invoice for {{ .client }}
+
+#table(
+  columns: (1fr, auto, auto),
+  align: (left, right, right),
+  table.header[*Description*][*Hours*][*Rate*],
+{{- range .items }}
+  [{{ .description }}], [{{ .hours }}], [{{ $.currency }}{{ .rate }}],
+{{- end }}
+)
+
+== Notes
+{{ .notes }}